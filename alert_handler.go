@@ -0,0 +1,245 @@
+package nslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const DEFAULT_ALERT_LEVEL = slog.LevelError
+const DEFAULT_ALERT_RATE_LIMIT = 10
+const DEFAULT_ALERT_RATE_INTERVAL = time.Minute
+const DEFAULT_ALERT_QUEUE_SIZE = 100
+const DEFAULT_ALERT_TIMEOUT = 10 * time.Second
+
+// A payload format of webhook for [nslog.AlertHandler].
+type AlertPreset int
+
+const (
+	AlertPresetSlack   AlertPreset = iota // {"text": "..."}
+	AlertPresetTeams                      // {"text": "..."}
+	AlertPresetDiscord                    // {"content": "..."}
+)
+
+// An option to customize [nslog.AlertHandler].
+type AlertHandlerOptions struct {
+	Level        slog.Leveler       // Set level to post alert. (default: slog.LevelError)
+	Preset       AlertPreset        // Set payload format of webhook. (default: AlertPresetSlack)
+	RateLimit    int                // Set maximum number of alerts posted in RateInterval. Exceeded alerts are suppressed. (default: 10)
+	RateInterval time.Duration      // Set interval for RateLimit. (default: 1 minute)
+	QueueSize    int                // Set size of queue for async delivery. Alerts are dropped if queue is full. (default: 100)
+	Client       *http.Client       // Set HTTP client to post alert. (default: client with 10 seconds timeout)
+	Format       *LogHandlerOptions // Set options to format alert text. Color is always disabled.
+	OnError      func(error)        // Set function called when posting alert is failed. (default: nil)
+}
+
+// A handler to post high-severity records to a webhook URL asynchronously.
+type AlertHandler struct {
+	formatter *LogHandler
+	sender    *alertSender
+}
+
+type alertSender struct {
+	url        string
+	options    AlertHandlerOptions
+	lineEnding string // line ending of the formatter, which is trimmed from alert text
+	queue      chan []byte
+	done       chan struct{}
+	once       sync.Once
+	stopped    atomic.Bool
+
+	mutex       sync.Mutex
+	closed      bool // queue is closed by Drain, which is guarded by the mutex
+	windowStart time.Time
+	windowCount int
+	suppressed  int
+}
+
+// Create a new [nslog.AlertHandler] object, which starts goroutine to post alerts.
+// Call [AlertHandler.Close] to stop the goroutine.
+func NewAlertHandler(url string, options *AlertHandlerOptions) *AlertHandler {
+	// set default parameters
+	if options == nil {
+		options = &AlertHandlerOptions{}
+	}
+	if options.Level == nil {
+		options.Level = DEFAULT_ALERT_LEVEL
+	}
+	if options.RateLimit <= 0 {
+		options.RateLimit = DEFAULT_ALERT_RATE_LIMIT
+	}
+	if options.RateInterval <= 0 {
+		options.RateInterval = DEFAULT_ALERT_RATE_INTERVAL
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = DEFAULT_ALERT_QUEUE_SIZE
+	}
+	if options.Client == nil {
+		options.Client = &http.Client{Timeout: DEFAULT_ALERT_TIMEOUT}
+	}
+
+	sender := &alertSender{
+		url:     url,
+		options: *options,
+		queue:   make(chan []byte, options.QueueSize),
+		done:    make(chan struct{}),
+	}
+
+	// the formatter writes alert text to the sender, so hooks and options such as DropKeys are applied same as LogHandler
+	var formatOptions LogHandlerOptions
+	if options.Format != nil {
+		formatOptions = *options.Format
+	}
+	formatOptions.AddColor = false
+	formatOptions.ColorMode = ColorModeNever
+	formatter := NewLogHandler(nil, &formatOptions).WithOptions(func(options *LogHandlerOptions) {
+		// environment variables must not add color either, and each record is an alert
+		options.AddColor = false
+		options.ColorMode = ColorModeNever
+		options.BatchWrite = false
+	}).WithWriter(sender)
+	sender.lineEnding = formatter.options.LineEnding
+	go sender.run()
+
+	return &AlertHandler{
+		formatter: formatter,
+		sender:    sender,
+	}
+}
+
+func (handler *AlertHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= handler.sender.options.Level.Level()
+}
+
+func (handler *AlertHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &AlertHandler{
+		formatter: handler.formatter.WithAttrs(attrs).(*LogHandler),
+		sender:    handler.sender,
+	}
+}
+
+func (handler *AlertHandler) WithGroup(name string) slog.Handler {
+	return &AlertHandler{
+		formatter: handler.formatter.WithGroup(name).(*LogHandler),
+		sender:    handler.sender,
+	}
+}
+
+func (handler *AlertHandler) Handle(ctx context.Context, record slog.Record) error {
+	return handler.formatter.Handle(ctx, record)
+}
+
+// Stop goroutine after posting queued alerts.
+func (handler *AlertHandler) Close() error {
+	handler.StopIntake()
+	return handler.Drain(context.Background())
+}
+
+// Get number of alerts waiting in the queue.
+func (handler *AlertHandler) QueueDepth() int {
+	return len(handler.sender.queue)
+}
+
+// Stop accepting new records. Records handled after this are dropped.
+func (handler *AlertHandler) StopIntake() {
+	handler.sender.stopped.Store(true)
+}
+
+// Wait until queued alerts are posted and stop goroutine, or the context is done.
+func (handler *AlertHandler) Drain(ctx context.Context) error {
+	handler.sender.once.Do(func() {
+		handler.sender.mutex.Lock()
+		handler.sender.closed = true
+		close(handler.sender.queue)
+		handler.sender.mutex.Unlock()
+	})
+	select {
+	case <-handler.sender.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Enqueue the line written by the formatter as alert text. Alerts are suppressed without error.
+func (sender *alertSender) Write(p []byte) (int, error) {
+	sender.enqueue(bytes.Clone(bytes.TrimSuffix(p, []byte(sender.lineEnding))))
+	return len(p), nil
+}
+
+func (sender *alertSender) enqueue(text []byte) {
+	if sender.stopped.Load() {
+		return
+	}
+
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+	if sender.closed {
+		return
+	}
+
+	// rate limit
+	now := time.Now()
+	if now.Sub(sender.windowStart) >= sender.options.RateInterval {
+		sender.windowStart = now
+		sender.windowCount = 0
+	}
+	if sender.windowCount >= sender.options.RateLimit {
+		sender.suppressed++
+		return
+	}
+
+	if sender.suppressed > 0 {
+		text = append(text, []byte(" ("+strconv.Itoa(sender.suppressed)+" alerts suppressed)")...)
+	}
+
+	// count the alert only if it is queued, and keep the number of suppressed alerts for the next alert otherwise
+	select {
+	case sender.queue <- text:
+		sender.windowCount++
+		sender.suppressed = 0
+	default:
+		sender.suppressed++
+	}
+}
+
+func (sender *alertSender) run() {
+	defer close(sender.done)
+	for text := range sender.queue {
+		err := sender.post(text)
+		if err != nil && sender.options.OnError != nil {
+			sender.options.OnError(err)
+		}
+	}
+}
+
+func (sender *alertSender) post(text []byte) error {
+	var payload any
+	switch sender.options.Preset {
+	case AlertPresetDiscord:
+		payload = map[string]string{"content": string(text)}
+	default:
+		payload = map[string]string{"text": string(text)}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	response, err := sender.options.Client.Post(sender.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("nslog: alert webhook returned status %s", response.Status)
+	}
+	return nil
+}
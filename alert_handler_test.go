@@ -0,0 +1,181 @@
+package nslog
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type webhookRecorder struct {
+	mutex    sync.Mutex
+	payloads []map[string]string
+}
+
+func (recorder *webhookRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	payload := map[string]string{}
+	_ = json.NewDecoder(r.Body).Decode(&payload)
+	recorder.mutex.Lock()
+	recorder.payloads = append(recorder.payloads, payload)
+	recorder.mutex.Unlock()
+}
+
+func TestAlertHandlerSlack(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	handler := NewAlertHandler(server.URL, nil)
+	log := slog.New(handler).WithGroup("Main")
+	log.Warn("warn message")
+	log.Error("error message", "key1", "val1")
+	handler.Close()
+
+	assert.Len(t, recorder.payloads, 1)
+	assert.Regexp(t, DEFAULT_TIME_REGEXP+" ERROR Main: error message Main\\.key1=val1 \\(.+\\)$", recorder.payloads[0]["text"])
+}
+
+func TestAlertHandlerDiscord(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	handler := NewAlertHandler(server.URL, &AlertHandlerOptions{Level: slog.LevelWarn, Preset: AlertPresetDiscord})
+	log := slog.New(handler)
+	log.Warn("warn message")
+	handler.Close()
+
+	assert.Len(t, recorder.payloads, 1)
+	assert.Contains(t, recorder.payloads[0]["content"], "WARN. warn message")
+}
+
+func TestAlertHandlerRateLimit(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	handler := NewAlertHandler(server.URL, &AlertHandlerOptions{RateLimit: 2, RateInterval: 50 * time.Millisecond})
+	log := slog.New(handler)
+	log.Error("message1")
+	log.Error("message2")
+	log.Error("message3")
+	log.Error("message4")
+	time.Sleep(60 * time.Millisecond)
+	log.Error("message5")
+	handler.Close()
+
+	assert.Len(t, recorder.payloads, 3)
+	assert.Regexp(t, "message5 \\(.+\\) \\(2 alerts suppressed\\)$", recorder.payloads[2]["text"])
+}
+
+func TestAlertHandlerQueueFull(t *testing.T) {
+	recorder := &webhookRecorder{}
+	received := make(chan struct{}, 10)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		<-release
+		recorder.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	handler := NewAlertHandler(server.URL, &AlertHandlerOptions{RateLimit: 3, QueueSize: 1})
+	log := slog.New(handler)
+	log.Error("message1")
+	<-received
+	log.Error("message2")
+	log.Error("message3") // dropped since the queue is full
+	close(release)
+	assert.Eventually(t, func() bool { return handler.QueueDepth() == 0 }, time.Second, time.Millisecond)
+
+	// the dropped alert is not counted for the rate limit
+	log.Error("message4")
+	handler.Close()
+
+	assert.Len(t, recorder.payloads, 3)
+	assert.Regexp(t, "message4 \\(.+\\) \\(1 alerts suppressed\\)$", recorder.payloads[2]["text"])
+}
+
+func TestAlertHandlerHooks(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	handler := NewAlertHandler(server.URL, &AlertHandlerOptions{Format: &LogHandlerOptions{
+		DropKeys: []string{"password"},
+		Hooks: []Hook{{BeforeFormat: func(ctx context.Context, record *slog.Record) bool {
+			return record.Message != "ignored message"
+		}}},
+	}})
+	log := slog.New(handler)
+	log.Error("ignored message")
+	log.Error("error message", "user", "alice", "password", "secret")
+	handler.Close()
+
+	assert.Len(t, recorder.payloads, 1)
+	assert.Regexp(t, "ERROR error message user=alice \\(.+\\)$", recorder.payloads[0]["text"])
+}
+
+func TestAlertHandlerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var errs []error
+	handler := NewAlertHandler(server.URL, &AlertHandlerOptions{OnError: func(err error) { errs = append(errs, err) }})
+	slog.New(handler).Error("message")
+	handler.Close()
+
+	assert.Len(t, errs, 1)
+}
+
+func TestAlertHandlerQueueDepth(t *testing.T) {
+	handler := NewAlertHandler("http://127.0.0.1:0", nil)
+	assert.Equal(t, 0, handler.QueueDepth())
+	handler.Close()
+}
+
+func TestAlertHandlerNoColor(t *testing.T) {
+	t.Setenv("GO_NSLOG_ADD_COLOR", "true")
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	handler := NewAlertHandler(server.URL, &AlertHandlerOptions{Format: &LogHandlerOptions{AddColor: true, ColorTime: true}})
+	slog.New(handler).Error("error message")
+	handler.Close()
+
+	assert.Len(t, recorder.payloads, 1)
+	assert.NotContains(t, recorder.payloads[0]["text"], "\x1b")
+	assert.Contains(t, recorder.payloads[0]["text"], " ERROR error message")
+}
+
+func TestAlertHandlerClosed(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	handler := NewAlertHandler(server.URL, nil)
+	log := slog.New(handler)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Error("error message")
+		}()
+	}
+	handler.Close()
+	wg.Wait()
+	log.Error("error message after close")
+	for _, payload := range recorder.payloads {
+		assert.NotContains(t, payload["text"], "after close")
+	}
+}
@@ -0,0 +1,95 @@
+package nslog
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/width"
+)
+
+// Get width of the terminal for AlignSource, MessageOverflow, and AttrsOverflow options, which is 0 if they are not used or the width is unknown.
+// TerminalWidth option takes precedence, and then COLUMNS environment variable takes precedence over size of the terminal like shells.
+func newTerminalWidth(options *LogHandlerOptions, writer io.Writer) int {
+	if !options.AlignSource && options.MessageOverflow == OverflowNone && options.AttrsOverflow == OverflowNone {
+		return 0
+	}
+	if options.TerminalWidth > 0 {
+		return options.TerminalWidth
+	}
+	if !isTerminal(writer) {
+		return 0
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return consoleWidth(writer.(interface{ Fd() uintptr }).Fd())
+}
+
+// Get width of the string on the terminal, which excludes escape sequences of color (SGR) and hyperlink (OSC 8).
+func visibleWidth(s string) int {
+	columns := 0
+	for i := 0; i < len(s); {
+		if n := escapeLength(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		columns += runeWidth(r)
+	}
+	return columns
+}
+
+// Get width of the rune on the terminal, where wide characters such as CJK and emoji take 2 columns,
+// and combining marks, variation selectors, and zero width joiners take no column.
+func runeWidth(r rune) int {
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// Get length of the escape sequence at the start of the string, which is 0 if the string does not start with it.
+func escapeLength(s string) int {
+	if len(s) < 2 || s[0] != '\x1b' {
+		return 0
+	}
+	switch s[1] {
+	case '[':
+		// CSI sequence such as "\x1b[31m" terminated by a final byte
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return len(s)
+	case ']':
+		// OSC sequence such as "\x1b]8;;url\x1b\\" terminated by ST or BEL
+		end := strings.IndexAny(s[2:], "\x07\x1b")
+		if end < 0 {
+			return len(s)
+		}
+		if s[2+end] == '\x1b' {
+			return min(2+end+2, len(s))
+		}
+		return 2 + end + 1
+	}
+	// two bytes sequence such as "\x1bc"
+	return 2
+}
+
+// Pad the string with spaces on the right to the width on the terminal.
+func padRight(s string, width int) string {
+	if padding := width - visibleWidth(s); padding > 0 {
+		return s + strings.Repeat(" ", padding)
+	}
+	return s
+}
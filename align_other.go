@@ -0,0 +1,8 @@
+//go:build !unix && !windows
+
+package nslog
+
+// Width of the terminal is unknown on this platform, so source is not right-aligned.
+func consoleWidth(fd uintptr) int {
+	return 0
+}
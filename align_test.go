@@ -0,0 +1,72 @@
+package nslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPadLevel(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{LevelStyle: LevelStyleBracketed, PadLevel: true, OmitTime: true})
+	log.Info("log message")
+	assert.Equal(t, "[INFO]  log message\n", buf.String())
+}
+
+func TestPadLevelColor(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{LevelLabels: map[slog.Level]string{slog.LevelError: "FATAL!"}, PadLevel: true, AddColor: true, OmitTime: true})
+	log.Info("log message")
+	assert.Equal(t, "\x1b[92mINFO.\x1b[0m  log message\n", buf.String())
+}
+
+func TestMessageColumn(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{MessageColumn: 20, OmitTime: true})
+	log.Info("message1")
+	log.WithGroup("Main").Info("message2", "key", "val")
+	log.WithGroup("VeryLongGroupName").Info("message3")
+	assert.Equal(t, "INFO.               message1\nINFO. Main:         message2 Main.key=val\nINFO. VeryLongGroupName: message3\n", buf.String())
+}
+
+func TestMessageColumnEnv(t *testing.T) {
+	t.Setenv("GO_NSLOG_MESSAGE_COLUMN", "10")
+	t.Setenv("GO_NSLOG_PAD_LEVEL", "true")
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{LevelStyle: LevelStyleShort, LevelLabels: map[slog.Level]string{slog.LevelInfo: "info"}, OmitTime: true})
+	log.Info("log message")
+	assert.Equal(t, "info      log message\n", buf.String())
+}
+
+func TestAlignSource(t *testing.T) {
+	buf := new(bytes.Buffer)
+	handler := NewLogHandler(buf, &LogHandlerOptions{OmitTime: true, AddSourceLevel: slog.LevelInfo, SourceFormatter: func(frame runtime.Frame) string { return "(main.go:19)" }})
+	handler.width = 40
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+	_ = handler.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "log message", pcs[0]))
+	_ = handler.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, strings.Repeat("x", 40), pcs[0]))
+	assert.Equal(t, "INFO. log message           (main.go:19)\nINFO. "+strings.Repeat("x", 40)+" (main.go:19)\n", buf.String())
+}
+
+func TestAlignSourceNotTerminal(t *testing.T) {
+	handler := NewLogHandler(new(bytes.Buffer), &LogHandlerOptions{AlignSource: true})
+	assert.Equal(t, 0, handler.width)
+}
+
+func TestVisibleWidth(t *testing.T) {
+	assert.Equal(t, 5, visibleWidth("hello"))
+	assert.Equal(t, 5, visibleWidth("\x1b[31mhello\x1b[0m"))
+	assert.Equal(t, 5, visibleWidth("\x1b]8;;file:///main.go\x1b\\hello\x1b]8;;\x1b\\"))
+	assert.Equal(t, 5, visibleWidth("\x1bchello"))
+	assert.Equal(t, 6, visibleWidth("日本語"))
+	assert.Equal(t, 2, visibleWidth("🐞"))
+	assert.Equal(t, 1, visibleWidth("⚠\ufe0f"))
+	assert.Equal(t, 1, visibleWidth("e\u0301"))
+}
@@ -0,0 +1,140 @@
+package nslog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"sync"
+)
+
+// Separator between a line and its hash in audit log.
+const auditHashSeparator = " hash="
+
+// An option to customize [nslog.AuditWriter] and [nslog.VerifyAudit].
+type AuditOptions struct {
+	Key      []byte // Set key of HMAC-SHA256 for lines. SHA-256 is used if it is empty. (default: nil)
+	Previous []byte // Set hash of the previous line to continue the chain, such as returned by [nslog.VerifyAudit]. (default: nil)
+}
+
+// A writer to make tamper-evident audit log, where each line carries a hash chained from the previous line such as
+// " hash=<hex>". The hash is HMAC-SHA256 (or SHA-256 without key) of the hash of the previous line and the line,
+// so modifying, inserting, or deleting lines breaks the chain, which is detected by [nslog.VerifyAudit].
+type AuditWriter struct {
+	writer   io.Writer
+	mutex    sync.Mutex
+	hash     hash.Hash
+	previous []byte
+	partial  []byte // incomplete line waiting for newline
+}
+
+// Create a new [nslog.AuditWriter] object.
+func NewAuditWriter(writer io.Writer, options *AuditOptions) *AuditWriter {
+	// set default parameters
+	if options == nil {
+		options = &AuditOptions{}
+	}
+
+	return &AuditWriter{
+		writer:   writer,
+		hash:     newAuditHash(options.Key),
+		previous: bytes.Clone(options.Previous),
+	}
+}
+
+func newAuditHash(key []byte) hash.Hash {
+	if len(key) == 0 {
+		return sha256.New()
+	}
+	return hmac.New(sha256.New, key)
+}
+
+// Compute hash of the line chained from the previous hash.
+func auditHash(h hash.Hash, previous []byte, line []byte) []byte {
+	h.Reset()
+	h.Write(previous)
+	h.Write(line)
+	return h.Sum(nil)
+}
+
+func (writer *AuditWriter) Write(p []byte) (int, error) {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	// the chain is updated only after the lines are written, so the bytes can be written again after an error
+	data := append(bytes.Clone(writer.partial), p...)
+	previous := writer.previous
+	var chained []byte
+	for {
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			break
+		}
+		line := bytes.TrimSuffix(data[:end], []byte("\r"))
+		previous = auditHash(writer.hash, previous, line)
+		chained = append(chained, line...)
+		chained = append(chained, auditHashSeparator...)
+		chained = append(chained, hex.EncodeToString(previous)...)
+		chained = append(chained, data[len(line):end+1]...)
+		data = data[end+1:]
+	}
+
+	if len(chained) > 0 {
+		_, err := writer.writer.Write(chained)
+		if err != nil {
+			return 0, err
+		}
+	}
+	writer.previous = previous
+	writer.partial = bytes.Clone(data)
+	return len(p), nil
+}
+
+// Get hash of the last line written, which is used as Previous option to continue the chain.
+func (writer *AuditWriter) Previous() []byte {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	return bytes.Clone(writer.previous)
+}
+
+// An error returned by [nslog.VerifyAudit] when the chain of hashes is broken.
+var ErrAuditTampered = errors.New("hash chain is broken")
+
+// Verify the chain of hashes of audit log written by [nslog.AuditWriter], and return hash of the last line.
+// The error reports the first line which is broken.
+func VerifyAudit(reader io.Reader, options *AuditOptions) ([]byte, error) {
+	// set default parameters
+	if options == nil {
+		options = &AuditOptions{}
+	}
+
+	h := newAuditHash(options.Key)
+	previous := bytes.Clone(options.Previous)
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for number := 1; scanner.Scan(); number++ {
+		line := bytes.TrimSuffix(scanner.Bytes(), []byte("\r"))
+		index := bytes.LastIndex(line, []byte(auditHashSeparator))
+		if index < 0 {
+			return nil, fmt.Errorf("nslog: audit line %d has no hash", number)
+		}
+		expected, err := hex.DecodeString(string(line[index+len(auditHashSeparator):]))
+		if err != nil {
+			return nil, fmt.Errorf("nslog: audit line %d has invalid hash: %w", number, err)
+		}
+		previous = auditHash(h, previous, line[:index])
+		if !hmac.Equal(previous, expected) {
+			return nil, fmt.Errorf("nslog: audit line %d is tampered: %w", number, ErrAuditTampered)
+		}
+	}
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
@@ -0,0 +1,87 @@
+package nslog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	writer := NewAuditWriter(buf, &AuditOptions{Key: []byte("secret")})
+	log := NewLogger(writer, nil)
+	log.Info("log message1", "user", "alice")
+	log.Info("log message2", "user", "bob")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. log message1 user=alice hash=[0-9a-f]{64}\n"+
+		DEFAULT_TIME_REGEXP+" INFO\\. log message2 user=bob hash=[0-9a-f]{64}\n$", buf.String())
+
+	last, err := VerifyAudit(bytes.NewReader(buf.Bytes()), &AuditOptions{Key: []byte("secret")})
+	assert.NoError(t, err)
+	assert.Equal(t, writer.Previous(), last)
+
+	// wrong key
+	_, err = VerifyAudit(bytes.NewReader(buf.Bytes()), &AuditOptions{Key: []byte("wrong")})
+	assert.ErrorIs(t, err, ErrAuditTampered)
+
+	// modified line
+	tampered := strings.Replace(buf.String(), "user=bob", "user=eve", 1)
+	_, err = VerifyAudit(strings.NewReader(tampered), &AuditOptions{Key: []byte("secret")})
+	assert.EqualError(t, err, "nslog: audit line 2 is tampered: hash chain is broken")
+
+	// deleted line
+	deleted := buf.String()[strings.Index(buf.String(), "\n")+1:]
+	_, err = VerifyAudit(strings.NewReader(deleted), &AuditOptions{Key: []byte("secret")})
+	assert.ErrorIs(t, err, ErrAuditTampered)
+}
+
+type failOnceWriter struct {
+	bytes.Buffer
+	failed bool
+}
+
+func (writer *failOnceWriter) Write(p []byte) (int, error) {
+	if !writer.failed {
+		writer.failed = true
+		return 0, errors.New("write error")
+	}
+	return writer.Buffer.Write(p)
+}
+
+func TestAuditWriterWriteError(t *testing.T) {
+	buf := &failOnceWriter{}
+	writer := NewAuditWriter(buf, nil)
+	_, err := writer.Write([]byte("line1\nline2"))
+	assert.Error(t, err)
+	assert.Nil(t, writer.Previous())
+
+	// the chain continues from the last line written
+	_, err = writer.Write([]byte("line1\nline2"))
+	assert.NoError(t, err)
+	_, err = writer.Write([]byte("\nline3\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, strings.Count(buf.String(), "hash="))
+	last, err := VerifyAudit(bytes.NewReader(buf.Bytes()), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, writer.Previous(), last)
+}
+
+func TestAuditWriterContinue(t *testing.T) {
+	buf := new(bytes.Buffer)
+	writer := NewAuditWriter(buf, nil)
+	_, _ = writer.Write([]byte("line1\r\nli"))
+	_, _ = writer.Write([]byte("ne2\n"))
+
+	// continue the chain after restart
+	writer = NewAuditWriter(buf, &AuditOptions{Previous: writer.Previous()})
+	_, _ = writer.Write([]byte("line3\n"))
+	assert.Regexp(t, "^line1 hash=[0-9a-f]{64}\r\nline2 hash=[0-9a-f]{64}\nline3 hash=[0-9a-f]{64}\n$", buf.String())
+
+	_, err := VerifyAudit(bytes.NewReader(buf.Bytes()), nil)
+	assert.NoError(t, err)
+
+	_, err = VerifyAudit(strings.NewReader("line without hash\n"), nil)
+	assert.EqualError(t, err, "nslog: audit line 1 has no hash")
+}
@@ -0,0 +1,43 @@
+package nslog
+
+import (
+	"log/slog"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"time"
+)
+
+// Create a startup banner record with PID, executable path, Go version, build VCS revision,
+// and effective configuration of the handler.
+func newBannerRecord(options *LogHandlerOptions) slog.Record {
+	executable, err := os.Executable()
+	if err != nil {
+		executable = "unknown"
+	}
+
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "log started", 0)
+	record.AddAttrs(
+		slog.Int("pid", os.Getpid()),
+		slog.String("exe", executable),
+		slog.String("go", runtime.Version()),
+	)
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				record.AddAttrs(slog.String("revision", setting.Value))
+			case "vcs.modified":
+				record.AddAttrs(slog.String("modified", setting.Value))
+			}
+		}
+	}
+	record.AddAttrs(
+		slog.String("level", options.Level.Level().String()),
+		slog.String("source_level", options.AddSourceLevel.Level().String()),
+		slog.String("time_layout", strconv.Quote(options.TimeLayout)),
+		slog.Bool("color", options.AddColor),
+	)
+	return record
+}
@@ -0,0 +1,23 @@
+package nslog
+
+import (
+	"bytes"
+	"log/slog"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddBanner(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{Level: slog.LevelWarn, AddBanner: true})
+	log.Info("log message")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. log started pid=\\d+ exe=\\S+ go="+runtime.Version()+" .*level=WARN source_level=WARN time_layout=\"2006/01/02 15:04:05\" color=false\n$", buf.String())
+}
+
+func TestAddBannerDisabled(t *testing.T) {
+	buf := new(bytes.Buffer)
+	NewLogger(buf, nil)
+	assert.Empty(t, buf.String())
+}
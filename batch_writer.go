@@ -0,0 +1,71 @@
+package nslog
+
+import (
+	"io"
+	"sync"
+)
+
+// A writer to coalesce lines queued by concurrent goroutines into a single Write call, like group commit of databases.
+// A goroutine which finds no write in progress becomes a leader to write all queued lines,
+// and the others wait until their lines are written by the leader.
+type batchWriter struct {
+	writer      io.Writer
+	writerMutex *sync.Mutex // mutex of the writer shared with handlers writing without batch
+
+	mutex   sync.Mutex
+	cond    *sync.Cond
+	pending []byte
+	spare   []byte       // buffer written by the previous leader, which is reused for pending lines
+	result  *batchResult // result of the batch which pending lines belong to
+	writing bool
+}
+
+// A result of a batch, which is kept by its waiters even if later batches are written before they wake up.
+type batchResult struct {
+	written bool
+	err     error
+}
+
+func newBatchWriter(writer io.Writer, writerMutex *sync.Mutex) *batchWriter {
+	batch := &batchWriter{
+		writer:      writer,
+		writerMutex: writerMutex,
+		result:      &batchResult{},
+	}
+	batch.cond = sync.NewCond(&batch.mutex)
+	return batch
+}
+
+// Queue the line and wait until it is written. The error is the one of the batch including the line.
+func (batch *batchWriter) write(line []byte) error {
+	batch.mutex.Lock()
+	result := batch.result
+	batch.pending = append(batch.pending, line...)
+	for batch.writing {
+		batch.cond.Wait()
+		if result.written {
+			batch.mutex.Unlock()
+			return result.err
+		}
+	}
+
+	// become a leader to write the pending lines
+	lines := batch.pending
+	batch.pending = batch.spare[:0]
+	batch.result = &batchResult{}
+	batch.writing = true
+	batch.mutex.Unlock()
+
+	batch.writerMutex.Lock()
+	_, err := batch.writer.Write(lines)
+	batch.writerMutex.Unlock()
+
+	batch.mutex.Lock()
+	batch.spare = lines
+	batch.writing = false
+	result.written = true
+	result.err = err
+	batch.cond.Broadcast()
+	batch.mutex.Unlock()
+	return err
+}
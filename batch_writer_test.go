@@ -0,0 +1,105 @@
+package nslog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type slowWriter struct {
+	buf    bytes.Buffer
+	writes int
+	err    error
+}
+
+func (writer *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(time.Millisecond)
+	writer.writes++
+	writer.buf.Write(p)
+	return len(p), writer.err
+}
+
+func TestBatchWrite(t *testing.T) {
+	writer := &slowWriter{}
+	log := NewLogger(writer, &LogHandlerOptions{BatchWrite: true})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Info("log message")
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 100, strings.Count(writer.buf.String(), "INFO. log message\n"))
+	assert.Less(t, writer.writes, 100)
+}
+
+func TestBatchWriteError(t *testing.T) {
+	writer := &slowWriter{err: errors.New("write error")}
+	handler := NewLogHandler(writer, &LogHandlerOptions{BatchWrite: true})
+	assert.Nil(t, handler.WithOptions(func(options *LogHandlerOptions) { options.BatchWrite = false }).batch)
+	assert.Same(t, handler.batch, handler.WithGroup("Main").(*LogHandler).batch)
+
+	err := handler.batch.write([]byte("line\n"))
+	assert.EqualError(t, err, "write error")
+}
+
+// A writer which fails the second write, and records number of lines per write.
+type failSecondWriter struct {
+	writes []int
+}
+
+func (writer *failSecondWriter) Write(p []byte) (int, error) {
+	time.Sleep(time.Millisecond)
+	writer.writes = append(writer.writes, bytes.Count(p, []byte("\n")))
+	if len(writer.writes) == 2 {
+		return 0, errors.New("write error")
+	}
+	return len(p), nil
+}
+
+func TestBatchWriteErrorOfWaiters(t *testing.T) {
+	writer := &failSecondWriter{}
+	handler := NewLogHandler(writer, &LogHandlerOptions{BatchWrite: true})
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	failed := 0
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := handler.batch.write([]byte("line\n"))
+			if err != nil {
+				mutex.Lock()
+				failed++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// all lines of the failed batch get the error, even if a later batch is written before they wake up
+	assert.GreaterOrEqual(t, len(writer.writes), 2)
+	assert.Equal(t, writer.writes[1], failed)
+}
+
+func BenchmarkBatchWrite(b *testing.B) {
+	log := NewLogger(io.Discard, &LogHandlerOptions{BatchWrite: true})
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			log.Info("log message", "key1", "val1")
+		}
+	})
+}
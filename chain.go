@@ -0,0 +1,79 @@
+package nslog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// A function to wrap the next handler with a handler such as [nslog.FilterHandler], which is composed by [nslog.Chain].
+type Middleware func(next slog.Handler) slog.Handler
+
+// Compose the handler with middlewares, where records pass through the middlewares in order and then the handler,
+// such as nslog.Chain(handler, nslog.Level(slog.LevelDebug), nslog.Filter(&options)).
+// Middlewares which are nil are skipped.
+func Chain(handler slog.Handler, middlewares ...Middleware) slog.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] != nil {
+			handler = middlewares[i](handler)
+		}
+	}
+	return handler
+}
+
+// Create a middleware from the function called for each record with the next handler, which passes the record
+// to the next handler to continue, or returns without it to drop the record. Attributes and groups of WithAttrs and WithGroup
+// are propagated to the next handler, so the function always receives the next handler derived in the same way.
+func Wrap(handle func(ctx context.Context, record slog.Record, next slog.Handler) error) Middleware {
+	return func(next slog.Handler) slog.Handler {
+		return &wrapHandler{next: next, handle: handle}
+	}
+}
+
+// Create a middleware of [nslog.LevelHandler].
+func Level(level slog.Leveler) Middleware {
+	return func(next slog.Handler) slog.Handler {
+		return WithLevel(next, level)
+	}
+}
+
+// Create a middleware of [nslog.FilterHandler].
+func Filter(options *FilterHandlerOptions) Middleware {
+	return func(next slog.Handler) slog.Handler {
+		return NewFilterHandler(next, options)
+	}
+}
+
+// Create a middleware of [nslog.TriggerHandler].
+func Trigger(options *TriggerHandlerOptions) Middleware {
+	return func(next slog.Handler) slog.Handler {
+		return NewTriggerHandler(next, options)
+	}
+}
+
+// A handler created by [nslog.Wrap].
+type wrapHandler struct {
+	next   slog.Handler
+	handle func(ctx context.Context, record slog.Record, next slog.Handler) error
+}
+
+func (handler *wrapHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return handler.next.Enabled(ctx, level)
+}
+
+func (handler *wrapHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &wrapHandler{
+		next:   handler.next.WithAttrs(attrs),
+		handle: handler.handle,
+	}
+}
+
+func (handler *wrapHandler) WithGroup(name string) slog.Handler {
+	return &wrapHandler{
+		next:   handler.next.WithGroup(name),
+		handle: handler.handle,
+	}
+}
+
+func (handler *wrapHandler) Handle(ctx context.Context, record slog.Record) error {
+	return handler.handle(ctx, record, handler.next)
+}
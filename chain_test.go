@@ -0,0 +1,49 @@
+package nslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	buf := new(bytes.Buffer)
+	var order []string
+	middleware := func(name string) Middleware {
+		return Wrap(func(ctx context.Context, record slog.Record, next slog.Handler) error {
+			order = append(order, name)
+			return next.Handle(ctx, record)
+		})
+	}
+	handler := Chain(NewLogHandler(buf, &LogHandlerOptions{OmitTime: true}),
+		middleware("first"),
+		nil,
+		Level(slog.LevelDebug),
+		Filter(&FilterHandlerOptions{Message: regexp.MustCompile("secret"), Exclude: true}),
+		middleware("second"),
+	)
+	log := slog.New(handler).WithGroup("Main").With("key1", "val1")
+	log.Debug("log message", "key2", "val2")
+	log.Info("secret message")
+	assert.Equal(t, "DEBUG Main[key1=val1]: log message Main.key2=val2\n", buf.String())
+	assert.Equal(t, []string{"first", "second", "first"}, order)
+}
+
+func TestWrap(t *testing.T) {
+	buf := new(bytes.Buffer)
+	redact := Wrap(func(ctx context.Context, record slog.Record, next slog.Handler) error {
+		if record.Level < slog.LevelWarn {
+			return nil
+		}
+		record.AddAttrs(slog.String("checked", "yes"))
+		return next.Handle(ctx, record)
+	})
+	log := slog.New(Chain(NewLogHandler(buf, &LogHandlerOptions{OmitTime: true, AddSourceLevel: slog.LevelError}), redact)).With("key1", "val1")
+	log.Info("info message")
+	log.Warn("warn message")
+	assert.Equal(t, "WARN. [key1=val1]: warn message checked=yes\n", buf.String())
+}
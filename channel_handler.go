@@ -0,0 +1,96 @@
+package nslog
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// An entry delivered by [nslog.ChannelHandler].
+type ChannelEntry struct {
+	Record slog.Record // cloned record, which does not contain attributes and groups added to the handler
+	Line   string      // formatted line with attributes and groups of the handler, which ends with newline
+}
+
+// An option to customize [nslog.ChannelHandler].
+type ChannelHandlerOptions struct {
+	Format *LogHandlerOptions // Set options to format lines, such as Level. (default: nil, which uses default options)
+	Block  bool               // Wait until the channel receives the entry if it is true. Drop the entry if the channel is full if it is false. (default: false)
+}
+
+// A handler to deliver entries of formatted lines and cloned records on a channel, so embedding applications can stream logs
+// to a GUI pane, websocket, or test harness without parsing text.
+type ChannelHandler struct {
+	formatter *LogHandler
+	channel   chan<- ChannelEntry
+	block     bool
+	dropped   *atomic.Uint64
+}
+
+// Create a new [nslog.ChannelHandler] object. The channel should be buffered unless Block option is true.
+func NewChannelHandler(channel chan<- ChannelEntry, options *ChannelHandlerOptions) *ChannelHandler {
+	// set default parameters
+	if options == nil {
+		options = &ChannelHandlerOptions{}
+	}
+
+	var formatOptions LogHandlerOptions
+	if options.Format != nil {
+		formatOptions = *options.Format
+	}
+	return &ChannelHandler{
+		formatter: NewLogHandler(nil, &formatOptions),
+		channel:   channel,
+		block:     options.Block,
+		dropped:   &atomic.Uint64{},
+	}
+}
+
+func (handler *ChannelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return handler.formatter.Enabled(ctx, level)
+}
+
+func (handler *ChannelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ChannelHandler{
+		formatter: handler.formatter.WithAttrs(attrs).(*LogHandler),
+		channel:   handler.channel,
+		block:     handler.block,
+		dropped:   handler.dropped,
+	}
+}
+
+func (handler *ChannelHandler) WithGroup(name string) slog.Handler {
+	return &ChannelHandler{
+		formatter: handler.formatter.WithGroup(name).(*LogHandler),
+		channel:   handler.channel,
+		block:     handler.block,
+		dropped:   handler.dropped,
+	}
+}
+
+func (handler *ChannelHandler) Handle(ctx context.Context, record slog.Record) error {
+	entry := ChannelEntry{
+		Record: record.Clone(),
+		Line:   string(handler.formatter.format(ctx, record)),
+	}
+	if handler.block {
+		select {
+		case handler.channel <- entry:
+		case <-ctx.Done():
+			handler.dropped.Add(1)
+			return ctx.Err()
+		}
+		return nil
+	}
+	select {
+	case handler.channel <- entry:
+	default:
+		handler.dropped.Add(1)
+	}
+	return nil
+}
+
+// Get number of entries dropped because the channel is full, which is shared with derived handlers.
+func (handler *ChannelHandler) Dropped() uint64 {
+	return handler.dropped.Load()
+}
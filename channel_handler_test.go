@@ -0,0 +1,39 @@
+package nslog
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannelHandler(t *testing.T) {
+	channel := make(chan ChannelEntry, 1)
+	handler := NewChannelHandler(channel, nil)
+	log := slog.New(handler).WithGroup("Main").With("key1", "val1")
+	log.Debug("log message1")
+	log.Info("log message2", "key2", "val2")
+	log.Info("log message3")
+
+	entry := <-channel
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. Main\\[key1=val1\\]: log message2 Main\\.key2=val2\n$", entry.Line)
+	assert.Equal(t, "log message2", entry.Record.Message)
+	assert.Equal(t, 1, entry.Record.NumAttrs())
+	assert.Equal(t, uint64(1), handler.Dropped())
+}
+
+func TestChannelHandlerBlock(t *testing.T) {
+	channel := make(chan ChannelEntry)
+	handler := NewChannelHandler(channel, &ChannelHandlerOptions{Block: true, Format: &LogHandlerOptions{Level: slog.LevelDebug}})
+	go slog.New(handler).Debug("log message")
+	entry := <-channel
+	assert.Equal(t, "log message", entry.Record.Message)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := handler.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelInfo, "log message", 0))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, uint64(1), handler.Dropped())
+}
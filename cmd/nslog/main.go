@@ -0,0 +1,185 @@
+// The nslog command provides utilities for log files written by the nslog package.
+//
+// Usage:
+//
+//	nslog replay [-speed 1.0] [-layout ""] [-max-wait 0] [file]
+//	nslog verify [-key-file path] [file]
+//	nslog decrypt -key-file path [file]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/mikiepure/nslog"
+)
+
+type replayOptions struct {
+	speed   float64
+	layout  string
+	maxWait time.Duration
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "replay":
+		err = runReplay(os.Args[2:])
+	case "verify":
+		err = runVerify(os.Args[2:])
+	case "decrypt":
+		err = runDecrypt(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "nslog:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: nslog <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "commands:")
+	fmt.Fprintln(os.Stderr, "  replay  re-emit a log file respecting original timing")
+	fmt.Fprintln(os.Stderr, "  verify  verify hash chain of an audit log file")
+	fmt.Fprintln(os.Stderr, "  decrypt decrypt a log file written by EncryptingWriter")
+}
+
+func runReplay(args []string) error {
+	options := replayOptions{}
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	flags.Float64Var(&options.speed, "speed", 1.0, "speed multiplier of replay (e.g. 2.0 is twice as fast)")
+	flags.StringVar(&options.layout, "layout", "", "time layout of log lines (default: layout in the file header or \"2006/01/02 15:04:05\")")
+	flags.DurationVar(&options.maxWait, "max-wait", 0, "maximum wait between lines (0 means no limit)")
+	flags.Parse(args)
+	if options.speed <= 0 {
+		return fmt.Errorf("speed must be positive: %v", options.speed)
+	}
+
+	reader := io.Reader(os.Stdin)
+	if flags.NArg() > 0 && flags.Arg(0) != "-" {
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		reader = file
+	}
+	return replay(reader, os.Stdout, options, time.Sleep)
+}
+
+// Write lines of the reader to the writer with waiting for the interval of their records parsed by [nslog.Scanner],
+// so lines with prefixes such as the sequence of AddSequence option are replayed with their timing.
+// Lines which are not records (e.g. the file header) and continuation lines are written without waiting.
+func replay(reader io.Reader, writer io.Writer, options replayOptions, sleep func(time.Duration)) error {
+	var previous time.Time
+	scanner := nslog.NewScanner(reader, &nslog.ParserOptions{TimeLayout: options.layout})
+	for scanner.Scan() {
+		record, err := scanner.Record()
+		if err == nil {
+			if current, ok := replayTime(record); ok {
+				if !previous.IsZero() && current.After(previous) {
+					wait := time.Duration(float64(current.Sub(previous)) / options.speed)
+					if options.maxWait > 0 && wait > options.maxWait {
+						wait = options.maxWait
+					}
+					sleep(wait)
+				}
+				previous = current
+			}
+		}
+		_, err = fmt.Fprintln(writer, scanner.Text())
+		if err != nil {
+			return err
+		}
+		if record != nil {
+			for _, continuation := range record.Continuations {
+				_, err = fmt.Fprintln(writer, "\t"+continuation)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// Get the time of the record to replay. The elapsed time by AddElapsed option is used if it is output,
+// since it is more precise than the time and is output even if time is omitted.
+func replayTime(record *nslog.ParsedRecord) (time.Time, bool) {
+	if record.Elapsed > 0 {
+		return time.Time{}.Add(record.Elapsed), true
+	}
+	return record.Time, !record.Time.IsZero()
+}
+
+func runVerify(args []string) error {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	keyFile := flags.String("key-file", "", "file of HMAC key (SHA-256 is used if it is empty)")
+	flags.Parse(args)
+
+	options := &nslog.AuditOptions{}
+	if *keyFile != "" {
+		key, err := os.ReadFile(*keyFile)
+		if err != nil {
+			return err
+		}
+		options.Key = key
+	}
+
+	reader := io.Reader(os.Stdin)
+	if flags.NArg() > 0 && flags.Arg(0) != "-" {
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		reader = file
+	}
+	return verify(reader, os.Stdout, options)
+}
+
+// Verify hash chain of audit log, and write hash of the last line to the writer.
+func verify(reader io.Reader, writer io.Writer, options *nslog.AuditOptions) error {
+	last, err := nslog.VerifyAudit(reader, options)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(writer, "ok (last hash: %x)\n", last)
+	return err
+}
+
+func runDecrypt(args []string) error {
+	flags := flag.NewFlagSet("decrypt", flag.ExitOnError)
+	keyFile := flags.String("key-file", "", "file of AES key (16, 24, or 32 bytes)")
+	flags.Parse(args)
+	if *keyFile == "" {
+		return fmt.Errorf("key-file is required")
+	}
+	key, err := os.ReadFile(*keyFile)
+	if err != nil {
+		return err
+	}
+
+	reader := io.Reader(os.Stdin)
+	if flags.NArg() > 0 && flags.Arg(0) != "-" {
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		reader = file
+	}
+	return nslog.DecryptLog(os.Stdout, reader, key)
+}
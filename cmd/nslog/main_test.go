@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikiepure/nslog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplay(t *testing.T) {
+	input := strings.Join([]string{
+		"2024/10/31 11:22:33 INFO. message1",
+		"2024/10/31 11:22:35 INFO. message2",
+		"    continuation",
+		"2024/10/31 11:22:36 INFO. message3",
+		"2024/10/31 11:23:36 INFO. message4",
+	}, "\n") + "\n"
+
+	var waits []time.Duration
+	output := new(bytes.Buffer)
+	options := replayOptions{speed: 2.0, layout: nslog.DEFAULT_TIME_LAYOUT, maxWait: 10 * time.Second}
+	err := replay(strings.NewReader(input), output, options, func(d time.Duration) { waits = append(waits, d) })
+	assert.NoError(t, err)
+	assert.Equal(t, input, output.String())
+	assert.Equal(t, []time.Duration{time.Second, 500 * time.Millisecond, 10 * time.Second}, waits)
+}
+
+func TestReplayPrefixes(t *testing.T) {
+	input := strings.Join([]string{
+		"#1 2024/10/31 11:22:33 INFO. message1",
+		"#2 2024/10/31 11:22:35 ERROR message2 err=failed",
+		"\tcaused by: failed",
+		"#3 2024/10/31 11:22:36 INFO. message3",
+	}, "\n") + "\n"
+
+	var waits []time.Duration
+	output := new(bytes.Buffer)
+	err := replay(strings.NewReader(input), output, replayOptions{speed: 1.0}, func(d time.Duration) { waits = append(waits, d) })
+	assert.NoError(t, err)
+	assert.Equal(t, input, output.String())
+	assert.Equal(t, []time.Duration{2 * time.Second, time.Second}, waits)
+
+	// elapsed time is used if time is omitted
+	input = "+100ms INFO. message1\n+350ms INFO. message2\n"
+	waits = nil
+	output.Reset()
+	err = replay(strings.NewReader(input), output, replayOptions{speed: 1.0}, func(d time.Duration) { waits = append(waits, d) })
+	assert.NoError(t, err)
+	assert.Equal(t, input, output.String())
+	assert.Equal(t, []time.Duration{250 * time.Millisecond}, waits)
+}
+
+func TestVerify(t *testing.T) {
+	input := new(bytes.Buffer)
+	writer := nslog.NewAuditWriter(input, nil)
+	_, _ = writer.Write([]byte("2024/10/31 11:22:33 INFO. message1\n"))
+
+	output := new(bytes.Buffer)
+	err := verify(bytes.NewReader(input.Bytes()), output, nil)
+	assert.NoError(t, err)
+	assert.Regexp(t, "^ok \\(last hash: [0-9a-f]{64}\\)\n$", output.String())
+
+	err = verify(strings.NewReader(strings.Replace(input.String(), "message1", "message2", 1)), output, nil)
+	assert.ErrorIs(t, err, nslog.ErrAuditTampered)
+}
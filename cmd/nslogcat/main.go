@@ -0,0 +1,299 @@
+// The nslogcat command reads log lines of the nslog text format or the slog JSON format, and writes them again
+// with color, which can be filtered by level, time range, and attributes.
+//
+// Usage:
+//
+//	nslogcat [-level warn] [-since 1h] [-until "2006/01/02 15:04:05"] [-grep key=regexp]... [-color auto] [-layout "2006/01/02 15:04:05"] [-format-version 1] [file]
+//
+// Lines which are neither the nslog text format nor the slog JSON format are written as is unless a filter is set.
+// The time layout and the format version are read from the file header if they are not given by the flags.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mikiepure/nslog"
+)
+
+type catOptions struct {
+	level  *slog.Level // minimum level to write, which is nil to write all levels
+	since  time.Time
+	until  time.Time
+	greps  []grepFilter
+	color  bool
+	layout string // time layout of log lines, which is empty to use the file header or the default
+
+	formatVersion int // version of the layout of log lines, which is 0 to use the file header or the latest
+}
+
+// A filter by attribute such as "key=regexp", or by the whole line if key is empty.
+type grepFilter struct {
+	key    string
+	regexp *regexp.Regexp
+}
+
+// A flag which can be set multiple times.
+type stringsFlag []string
+
+func (values *stringsFlag) String() string {
+	return strings.Join(*values, ",")
+}
+
+func (values *stringsFlag) Set(s string) error {
+	*values = append(*values, s)
+	return nil
+}
+
+func main() {
+	err := run(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "nslogcat:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	var level, since, until string
+	var greps stringsFlag
+	colorMode := nslog.ColorModeAuto
+	options := catOptions{}
+	flags := flag.NewFlagSet("nslogcat", flag.ExitOnError)
+	flags.StringVar(&level, "level", "", "minimum level to write such as \"warn\" or \"info+2\" (default: all levels)")
+	flags.StringVar(&since, "since", "", "write records at or after the time, or the duration before now such as \"1h\"")
+	flags.StringVar(&until, "until", "", "write records before the time, or the duration before now such as \"10m\"")
+	flags.Var(&greps, "grep", "write records whose attribute matches \"key=regexp\", or whose line matches \"regexp\" (repeatable)")
+	flags.Var(&colorMode, "color", "mode to add color: auto, always, or never")
+	flags.StringVar(&options.layout, "layout", "", "time layout of log lines (default: layout in the file header, or \""+nslog.DEFAULT_TIME_LAYOUT+"\")")
+	flags.IntVar(&options.formatVersion, "format-version", 0, "version of the layout of log lines (default: version in the file header, or the latest)")
+	flags.Parse(args)
+
+	if level != "" {
+		minimum, err := nslog.ParseLevel(level)
+		if err != nil {
+			return err
+		}
+		options.level = &minimum
+	}
+	now := time.Now()
+	var err error
+	options.since, err = parseTime(since, options.layout, now)
+	if err != nil {
+		return err
+	}
+	options.until, err = parseTime(until, options.layout, now)
+	if err != nil {
+		return err
+	}
+	for _, grep := range greps {
+		filter, err := parseGrep(grep)
+		if err != nil {
+			return err
+		}
+		options.greps = append(options.greps, filter)
+	}
+	options.color = colorMode.Enabled(os.Stdout)
+
+	reader := io.Reader(os.Stdin)
+	if flags.NArg() > 0 && flags.Arg(0) != "-" {
+		file, err := os.Open(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		reader = file
+	}
+	return cat(reader, os.Stdout, options)
+}
+
+// Parse time of the flag, which is a duration before now, RFC 3339, or the layout of log lines.
+func parseTime(s string, layout string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if layout == "" {
+		layout = nslog.DEFAULT_TIME_LAYOUT
+	}
+	if duration, err := time.ParseDuration(s); err == nil {
+		return now.Add(-duration), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use duration, RFC 3339, or %q", s, layout)
+}
+
+// Parse filter of the grep flag such as "key=regexp" or "regexp".
+func parseGrep(s string) (grepFilter, error) {
+	var filter grepFilter
+	pattern := s
+	if key, value, ok := strings.Cut(s, "="); ok && key != "" && !strings.ContainsAny(key, " \t") {
+		filter.key, pattern = key, value
+	}
+	var err error
+	filter.regexp, err = regexp.Compile(pattern)
+	if err != nil {
+		return filter, fmt.Errorf("invalid grep %q: %w", s, err)
+	}
+	return filter, nil
+}
+
+// Write records of the reader to the writer if they match the filters.
+func cat(reader io.Reader, writer io.Writer, options catOptions) error {
+	filtered := options.level != nil || !options.since.IsZero() || !options.until.IsZero() || len(options.greps) > 0
+	scanner := nslog.NewScanner(reader, &nslog.ParserOptions{TimeLayout: options.layout, FormatVersion: options.formatVersion})
+	for scanner.Scan() {
+		line := scanner.Text()
+		record, err := scanner.Record()
+		if errors.Is(err, nslog.ErrUnsupportedFormatVersion) {
+			return fmt.Errorf("%w: %d (use -format-version to parse as another version)", err, scanner.FormatVersion())
+		}
+		if err != nil && strings.HasPrefix(strings.TrimSpace(line), "{") {
+			record, err = nslog.ParseJSON(line)
+		}
+		if err != nil {
+			// write as is if the line is unknown format
+			if filtered {
+				continue
+			}
+			_, err = fmt.Fprintln(writer, line)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if !match(record, line, options) {
+			continue
+		}
+		_, err = io.WriteString(writer, render(record, options))
+		if err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Check whether the record matches the filters of the options.
+func match(record *nslog.ParsedRecord, line string, options catOptions) bool {
+	if options.level != nil && record.Level < *options.level {
+		return false
+	}
+	if !options.since.IsZero() && (record.Time.IsZero() || record.Time.Before(options.since)) {
+		return false
+	}
+	if !options.until.IsZero() && (record.Time.IsZero() || !record.Time.Before(options.until)) {
+		return false
+	}
+	for _, filter := range options.greps {
+		if filter.key == "" {
+			if !filter.regexp.MatchString(line) {
+				return false
+			}
+			continue
+		}
+		matched := false
+		for _, attribute := range record.Attrs {
+			if attribute.Key == filter.key && filter.regexp.MatchString(attribute.Value.String()) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// Render the record in the nslog text format with continuation lines.
+func render(record *nslog.ParsedRecord, options catOptions) string {
+	paint := func(c *color.Color, s string) string {
+		if !options.color || c == nil {
+			return s
+		}
+		enabled := *c
+		enabled.EnableColor()
+		return enabled.Sprint(s)
+	}
+
+	var words []string
+	if record.Sequence != 0 {
+		words = append(words, "#"+strconv.FormatUint(record.Sequence, 10))
+	}
+	if !record.Time.IsZero() {
+		layout := options.layout
+		if layout == "" {
+			layout = nslog.DEFAULT_TIME_LAYOUT
+		}
+		words = append(words, paint(nslog.DEFAULT_TIME_COLOR, record.Time.Format(layout)))
+	}
+	label := record.LevelLabel
+	if label == "" {
+		label = levelLabel(record.Level)
+	}
+	words = append(words, paint(levelColor(record.Level), label))
+	if len(record.Groups) > 0 {
+		words = append(words, strings.Join(record.Groups, ".")+":")
+	}
+	if record.Message != "" {
+		words = append(words, record.Message)
+	}
+	for _, attribute := range record.Attrs {
+		words = append(words, paint(nslog.DEFAULT_ATTR_KEY_COLOR, attribute.Key)+"="+attribute.Value.String())
+	}
+	if record.TraceID != "" {
+		words = append(words, "["+record.TraceID+"/"+record.SpanID+"]")
+	}
+	if record.Source != "" {
+		words = append(words, paint(nslog.DEFAULT_SOURCE_COLOR, "("+record.Source+")"))
+	}
+
+	text := strings.Join(words, " ") + "\n"
+	for _, continuation := range record.Continuations {
+		text += "\t" + continuation + "\n"
+	}
+	return text
+}
+
+// Get label of the level in the default style such as "WARN.".
+func levelLabel(level slog.Level) string {
+	switch level {
+	case nslog.LevelFatal:
+		return "FATAL"
+	case slog.LevelError:
+		return "ERROR"
+	case slog.LevelWarn:
+		return "WARN."
+	case slog.LevelInfo:
+		return "INFO."
+	case slog.LevelDebug:
+		return "DEBUG"
+	}
+	return level.String()
+}
+
+// Get color of the nearest level at or below the level.
+func levelColor(level slog.Level) *color.Color {
+	switch {
+	case level >= slog.LevelError:
+		return nslog.DEFAULT_THEME.Error
+	case level >= slog.LevelWarn:
+		return nslog.DEFAULT_THEME.Warn
+	case level >= slog.LevelInfo:
+		return nslog.DEFAULT_THEME.Info
+	default:
+		return nslog.DEFAULT_THEME.Debug
+	}
+}
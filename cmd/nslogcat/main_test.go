@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikiepure/nslog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCat(t *testing.T) {
+	input := strings.Join([]string{
+		"2024/10/31 11:22:33 INFO. message1 key1=val1",
+		"not nslog format",
+		"2024/10/31 11:22:34 ERROR Main: message2 Main.key2=val2 (main.go:12)",
+		"\terr: caused by: inner",
+	}, "\n") + "\n"
+
+	output := new(bytes.Buffer)
+	err := cat(strings.NewReader(input), output, catOptions{layout: nslog.DEFAULT_TIME_LAYOUT})
+	assert.NoError(t, err)
+	assert.Equal(t, input, output.String())
+}
+
+func TestCatJSON(t *testing.T) {
+	input := new(bytes.Buffer)
+	log := slog.New(slog.NewJSONHandler(input, &slog.HandlerOptions{AddSource: true}))
+	log.WithGroup("Main").Warn("message1", "key1", "val1", "key2", 2)
+
+	output := new(bytes.Buffer)
+	err := cat(input, output, catOptions{layout: nslog.DEFAULT_TIME_LAYOUT})
+	assert.NoError(t, err)
+	assert.Regexp(t, "^[0-9]{4}/[0-9]{2}/[0-9]{2} [0-9]{2}:[0-9]{2}:[0-9]{2} WARN\\. message1 Main\\.key1=val1 Main\\.key2=2 \\(main_test\\.go:[0-9]+\\)\n$", output.String())
+}
+
+func TestCatFilter(t *testing.T) {
+	input := strings.Join([]string{
+		"2024/10/31 11:22:33 DEBUG message1 key=val1",
+		"2024/10/31 11:22:34 INFO. message2 key=val2",
+		"not nslog format",
+		"2024/10/31 11:22:35 WARN. message3 key=val3",
+		"2024/10/31 11:22:36 ERROR message4 key=val4",
+	}, "\n") + "\n"
+	level := slog.LevelInfo
+
+	output := new(bytes.Buffer)
+	err := cat(strings.NewReader(input), output, catOptions{
+		level:  &level,
+		since:  time.Date(2024, 10, 31, 11, 22, 34, 0, time.Local),
+		until:  time.Date(2024, 10, 31, 11, 22, 36, 0, time.Local),
+		layout: nslog.DEFAULT_TIME_LAYOUT,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "2024/10/31 11:22:34 INFO. message2 key=val2\n2024/10/31 11:22:35 WARN. message3 key=val3\n", output.String())
+
+	filter, err := parseGrep("key=val[14]")
+	assert.NoError(t, err)
+	output.Reset()
+	err = cat(strings.NewReader(input), output, catOptions{greps: []grepFilter{filter}, layout: nslog.DEFAULT_TIME_LAYOUT})
+	assert.NoError(t, err)
+	assert.Equal(t, "2024/10/31 11:22:33 DEBUG message1 key=val1\n2024/10/31 11:22:36 ERROR message4 key=val4\n", output.String())
+}
+
+func TestCatColor(t *testing.T) {
+	output := new(bytes.Buffer)
+	err := cat(strings.NewReader("2024/10/31 11:22:33 ERROR message1 key=val1\n"), output, catOptions{color: true, layout: nslog.DEFAULT_TIME_LAYOUT})
+	assert.NoError(t, err)
+	assert.Equal(t, "\x1b[2m2024/10/31 11:22:33\x1b[22m \x1b[91mERROR\x1b[0m message1 \x1b[36mkey\x1b[0m=val1\n", output.String())
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, 10, 31, 11, 22, 33, 0, time.Local)
+	since, err := parseTime("1h", nslog.DEFAULT_TIME_LAYOUT, now)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(-time.Hour), since)
+	since, err = parseTime("2024/10/31 10:00:00", nslog.DEFAULT_TIME_LAYOUT, now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 10, 31, 10, 0, 0, 0, time.Local), since)
+	_, err = parseTime("yesterday", nslog.DEFAULT_TIME_LAYOUT, now)
+	assert.Error(t, err)
+}
+
+func TestCatFormatVersion(t *testing.T) {
+	input := strings.Join([]string{
+		"# format version: 1",
+		"# time layout: 2006-01-02T15:04:05Z07:00 (UTC)",
+		"2024-10-31T11:22:33Z INFO. message1 key1=val1",
+	}, "\n") + "\n"
+	output := new(bytes.Buffer)
+	err := cat(strings.NewReader(input), output, catOptions{level: new(slog.Level)})
+	assert.NoError(t, err)
+	assert.Equal(t, "2024/10/31 11:22:33 INFO. message1 key1=val1\n", output.String())
+
+	output.Reset()
+	err = cat(strings.NewReader("# format version: 99\n2024/10/31 11:22:33 INFO. message1\n"), output, catOptions{})
+	assert.ErrorIs(t, err, nslog.ErrUnsupportedFormatVersion)
+	assert.Equal(t, "# format version: 99\n", output.String())
+
+	output.Reset()
+	err = cat(strings.NewReader("# format version: 99\n2024/10/31 11:22:33 INFO. message1\n"), output, catOptions{formatVersion: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "# format version: 99\n2024/10/31 11:22:33 INFO. message1\n", output.String())
+}
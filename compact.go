@@ -0,0 +1,91 @@
+package nslog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+)
+
+// Layout of time of FormatCompact such as "15:04:05", which is used if TimeLayout option is default.
+const COMPACT_TIME_LAYOUT = "15:04:05"
+
+// Width of message of FormatCompact, which is padded so attributes start at the same column.
+const COMPACT_MESSAGE_WIDTH = 40
+
+var compactLevels = map[slog.Level]string{
+	LevelFatal:      "FTL",
+	slog.LevelError: "ERR",
+	slog.LevelWarn:  "WRN",
+	slog.LevelInfo:  "INF",
+	slog.LevelDebug: "DBG",
+}
+
+var compactDimColor = color.New(color.Faint)
+
+// Format the record in the compact style like ConsoleWriter of zerolog such as "11:22:33 INF message    key=value" by FormatCompact option.
+// Time, attributes, and source are dim, and the 3-letter level is colored by the theme if AddColor option is true.
+func (handler *LogHandler) formatCompact(ctx context.Context, record slog.Record) []byte {
+	var dim, levelColor *color.Color
+	if handler.options.AddColor {
+		dim = enableColor(compactDimColor)
+		levelColor = enableColor(handler.options.theme().level(record.Level))
+	}
+
+	var log_strings []string
+	if !handler.options.OmitTime && !record.Time.IsZero() {
+		recordTime := record.Time
+		if handler.options.UseUTC {
+			recordTime = recordTime.UTC()
+		}
+		layout := handler.options.TimeLayout
+		if layout == DEFAULT_TIME_LAYOUT {
+			layout = COMPACT_TIME_LAYOUT
+		}
+		log_strings = append(log_strings, colorize(dim, recordTime.Format(layout)))
+	}
+	level, ok := compactLevels[record.Level]
+	if !ok {
+		level = record.Level.String()
+	}
+	log_strings = append(log_strings, colorize(levelColor, level))
+
+	// attributes of the handler and the record
+	var attributes []string
+	var continuations []string
+	for _, attribute := range handler.flatAttrs(ctx, record, false) {
+		attributes = append(attributes, colorize(dim, attribute.key+handler.options.KeyValueSeparator+attribute.value))
+		continuations = append(continuations, attribute.continuations...)
+	}
+	attributesText := strings.Join(attributes, " ")
+
+	// message padded to the column of attributes
+	message := record.Message
+	if attributesText != "" {
+		if width := utf8.RuneCountInString(message); width < COMPACT_MESSAGE_WIDTH {
+			message += strings.Repeat(" ", COMPACT_MESSAGE_WIDTH-width)
+		}
+		log_strings = append(log_strings, message, attributesText)
+	} else {
+		log_strings = append(log_strings, message)
+	}
+
+	if record.Level >= handler.options.AddSourceLevel.Level() && record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		if source := handler.sourceString(frame); source != "" {
+			log_strings = append(log_strings, colorize(dim, source))
+		}
+	}
+
+	log_line := strings.Join(log_strings, " ")
+	if handler.options.MaxLineLength > 0 {
+		log_line = truncateLine(log_line, attributesText, handler.options.MaxLineLength)
+	}
+	for _, continuation := range continuations {
+		log_line += handler.options.LineEnding + continuation
+	}
+	return []byte(log_line + handler.options.LineEnding)
+}
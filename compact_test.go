@@ -0,0 +1,33 @@
+package nslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatCompact(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{Format: FormatCompact, Level: slog.LevelDebug})
+	log.With("key1", "val1").WithGroup("Main").Info("log message", "key2", "val2")
+	log.Debug("debug message")
+	log.Warn("warn message")
+	lines := bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n"))
+	assert.Len(t, lines, 3)
+	assert.Regexp(t, "^[0-9]{2}:[0-9]{2}:[0-9]{2} INF log message {30}key1=val1 Main\\.key2=val2$", string(lines[0]))
+	assert.Regexp(t, "^[0-9]{2}:[0-9]{2}:[0-9]{2} DBG debug message$", string(lines[1]))
+	assert.Regexp(t, "^[0-9]{2}:[0-9]{2}:[0-9]{2} WRN warn message \\(compact_test\\.go:[0-9]+\\)$", string(lines[2]))
+}
+
+func TestFormatCompactColor(t *testing.T) {
+	buf := new(bytes.Buffer)
+	handler := NewLogHandler(buf, &LogHandlerOptions{Format: FormatCompact, AddColor: true, TimeLayout: time.Kitchen})
+	record := slog.NewRecord(time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), slog.LevelError, "error message", 0)
+	record.AddAttrs(slog.String("key", "val"))
+	_ = handler.Handle(context.Background(), record)
+	assert.Equal(t, "\x1b[2m3:04PM\x1b[22m \x1b[91mERR\x1b[0m error message                            \x1b[2mkey=val\x1b[22m\n", buf.String())
+}
@@ -0,0 +1,127 @@
+package nslog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const DEFAULT_COMPRESS_FLUSH_INTERVAL = time.Second
+
+// An option to customize [nslog.CompressWriter].
+type CompressWriterOptions struct {
+	Level         int           // Set compression level of gzip such as gzip.BestSpeed. gzip.NoCompression is not available. (default: gzip.DefaultCompression)
+	FlushInterval time.Duration // Set interval to flush compressed data, so readers can decompress lines written so far. (default: 1 second)
+}
+
+// A writer to compress log lines by gzip on the fly, which is useful for verbose debug captures written to disk
+// or shipped over the network. Compressed data is flushed periodically and by [CompressWriter.Sync].
+type CompressWriter struct {
+	writer io.Writer
+	mutex  sync.Mutex
+	gzip   *gzip.Writer
+	dirty  bool // whether data is written after the last flush
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Create a new [nslog.CompressWriter] object, which starts goroutine to flush compressed data periodically.
+// Call [CompressWriter.Close] to stop the goroutine and write the end of the gzip stream.
+func NewCompressWriter(writer io.Writer, options *CompressWriterOptions) (*CompressWriter, error) {
+	// set default parameters
+	if options == nil {
+		options = &CompressWriterOptions{}
+	}
+	if options.Level == 0 {
+		options.Level = gzip.DefaultCompression
+	}
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = DEFAULT_COMPRESS_FLUSH_INTERVAL
+	}
+
+	gzipWriter, err := gzip.NewWriterLevel(writer, options.Level)
+	if err != nil {
+		return nil, err
+	}
+	compress := &CompressWriter{
+		writer: writer,
+		gzip:   gzipWriter,
+		done:   make(chan struct{}),
+	}
+	go compress.run(options.FlushInterval)
+	return compress, nil
+}
+
+func (writer *CompressWriter) Write(p []byte) (int, error) {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	if writer.gzip == nil {
+		return 0, os.ErrClosed
+	}
+	writer.dirty = true
+	return writer.gzip.Write(p)
+}
+
+// Flush compressed data to the underlying writer.
+func (writer *CompressWriter) Flush() error {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	return writer.flushLocked()
+}
+
+// Flush compressed data, and commit it to stable storage if the underlying writer has Sync method.
+func (writer *CompressWriter) Sync() error {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	err := writer.flushLocked()
+	if err != nil {
+		return err
+	}
+	syncer, ok := writer.writer.(interface{ Sync() error })
+	if !ok {
+		return nil
+	}
+	return syncer.Sync()
+}
+
+func (writer *CompressWriter) flushLocked() error {
+	if writer.gzip == nil {
+		return os.ErrClosed
+	}
+	if !writer.dirty {
+		return nil
+	}
+	writer.dirty = false
+	return writer.gzip.Flush()
+}
+
+// Stop goroutine and write the end of the gzip stream. The underlying writer is not closed.
+func (writer *CompressWriter) Close() error {
+	writer.once.Do(func() {
+		close(writer.done)
+	})
+
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	if writer.gzip == nil {
+		return nil
+	}
+	err := writer.gzip.Close()
+	writer.gzip = nil
+	return err
+}
+
+func (writer *CompressWriter) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-writer.done:
+			return
+		case <-ticker.C:
+			_ = writer.Flush()
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package nslog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type lockedBuffer struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+}
+
+func (buffer *lockedBuffer) Write(p []byte) (int, error) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	return buffer.buf.Write(p)
+}
+
+func (buffer *lockedBuffer) Bytes() []byte {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	return bytes.Clone(buffer.buf.Bytes())
+}
+
+func decompress(t *testing.T, data []byte) string {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	assert.NoError(t, err)
+	decompressed, _ := io.ReadAll(reader) // io.ErrUnexpectedEOF is returned for stream without the end
+	return string(decompressed)
+}
+
+func TestCompressWriter(t *testing.T) {
+	buf := new(lockedBuffer)
+	writer, err := NewCompressWriter(buf, nil)
+	assert.NoError(t, err)
+	log := NewLogger(writer, nil)
+	log.Info("log message1")
+	assert.NoError(t, writer.Sync())
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. log message1\n$", decompress(t, buf.Bytes()))
+
+	log.Info("log message2")
+	assert.NoError(t, writer.Close())
+	assert.NoError(t, writer.Close())
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. log message1\n"+DEFAULT_TIME_REGEXP+" INFO\\. log message2\n$", decompress(t, buf.Bytes()))
+
+	_, err = writer.Write([]byte("log message3\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestCompressWriterFlushInterval(t *testing.T) {
+	buf := new(lockedBuffer)
+	writer, err := NewCompressWriter(buf, &CompressWriterOptions{Level: gzip.BestSpeed, FlushInterval: 10 * time.Millisecond})
+	assert.NoError(t, err)
+	defer writer.Close()
+	NewLogger(writer, nil).Info("log message")
+	assert.Eventually(t, func() bool {
+		return len(buf.Bytes()) > 0 && decompress(t, buf.Bytes()) != ""
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestCompressWriterInvalidLevel(t *testing.T) {
+	_, err := NewCompressWriter(io.Discard, &CompressWriterOptions{Level: 100})
+	assert.Error(t, err)
+}
@@ -0,0 +1,240 @@
+package nslog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+const DEFAULT_WATCH_INTERVAL = 5 * time.Second
+
+// A configuration of [nslog.LogHandlerOptions] and output, which is loaded from JSON file such as
+//
+//	{"level": "DEBUG", "group_levels": {"db": "WARN"}, "color_mode": "auto", "output": "/var/log/app.log"}
+type Config struct {
+	Level          string            `json:"level"`            // level parsed by [nslog.ParseLevel] such as "DEBUG"
+	GroupLevels    map[string]string `json:"group_levels"`     // levels per name of groups
+	ColorMode      string            `json:"color_mode"`       // "auto", "always", or "never"
+	TimeLayout     string            `json:"time_layout"`      // layout for [Time.Format]
+	UseUTC         bool              `json:"use_utc"`          // output time in UTC
+	AddPID         bool              `json:"add_pid"`          // add PID
+	AddGoroutineID bool              `json:"add_goroutine_id"` // add goroutine ID
+	AddSourceLevel string            `json:"add_source_level"` // level parsed by [nslog.ParseLevel] such as "WARN"
+	SourceFilePath bool              `json:"source_file_path"` // use filepath for source
+	Output         string            `json:"output"`           // "stderr", "stdout", path of file, or syslog URL (see [nslog.OpenOutput])
+}
+
+// An option to customize [Registry.WatchConfig].
+type WatchConfigOptions struct {
+	Interval time.Duration // Set interval to check modification of the file. (default: 5 seconds)
+	OnSignal bool          // Reload the file on SIGHUP if it is true, which is ignored on platforms without SIGHUP such as js. (default: false)
+	OnError  func(error)   // Set function called when reloading the file is failed. (default: nil)
+}
+
+// Load configuration from JSON file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	err = json.Unmarshal(data, config)
+	if err != nil {
+		return nil, fmt.Errorf("nslog: invalid config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Convert the configuration to options.
+func (config *Config) Options() (*LogHandlerOptions, error) {
+	options := &LogHandlerOptions{
+		TimeLayout:     config.TimeLayout,
+		UseUTC:         config.UseUTC,
+		AddPID:         config.AddPID,
+		AddGoroutineID: config.AddGoroutineID,
+		SourceFilePath: config.SourceFilePath,
+	}
+	if config.Level != "" {
+		level, err := ParseLevel(config.Level)
+		if err != nil {
+			return nil, err
+		}
+		options.Level = level
+	}
+	if config.AddSourceLevel != "" {
+		level, err := ParseLevel(config.AddSourceLevel)
+		if err != nil {
+			return nil, err
+		}
+		options.AddSourceLevel = level
+	}
+	if len(config.GroupLevels) > 0 {
+		options.GroupLevels = map[string]slog.Leveler{}
+		for name, value := range config.GroupLevels {
+			level, err := ParseLevel(value)
+			if err != nil {
+				return nil, err
+			}
+			options.GroupLevels[name] = level
+		}
+	}
+	if config.ColorMode != "" {
+		err := options.ColorMode.Set(config.ColorMode)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return options, nil
+}
+
+// Open writer of the output. It returns nil for empty output, and the writer should be closed by the caller.
+func (config *Config) writer() (io.Writer, error) {
+	if config.Output == "" {
+		return nil, nil
+	}
+	return OpenOutput(config.Output)
+}
+
+// A writer of the output opened by the configuration.
+// It is closed after writes in flight are drained, and later writes by handlers created before reloading
+// are forwarded to the writer of the next configuration, so records are not dropped while reloading.
+// Writes are serialized by the writer, since the forwarded writes are not guarded by the mutex of the next handler.
+type configWriter struct {
+	mutex  sync.Mutex
+	writer io.Writer
+	closed bool
+	next   io.Writer
+}
+
+func (writer *configWriter) Write(p []byte) (int, error) {
+	writer.mutex.Lock()
+	if writer.closed {
+		next := writer.next
+		writer.mutex.Unlock()
+		if next == nil {
+			return 0, os.ErrClosed
+		}
+		return next.Write(p)
+	}
+	defer writer.mutex.Unlock()
+	return writer.writer.Write(p)
+}
+
+// Close the writer after writes in flight, and forward later writes to the next writer.
+func (writer *configWriter) closeTo(next io.Writer) error {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	if writer.closed {
+		return nil
+	}
+	writer.closed = true
+	writer.next = next
+	if closer, ok := writer.writer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (writer *configWriter) Close() error {
+	return writer.closeTo(nil)
+}
+
+// Load configuration from JSON file and update options and output of all loggers of the registry.
+// The previous output opened by the configuration is closed after writes in flight are drained.
+func (registry *Registry) LoadConfig(path string) error {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	options, err := config.Options()
+	if err != nil {
+		return err
+	}
+	writer, err := config.writer()
+	if err != nil {
+		return err
+	}
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	registry.options = *options
+	if writer == nil {
+		registry.update()
+		return nil
+	}
+	previous := registry.configWriter
+	registry.writer = writer
+	registry.configWriter = nil
+	if writer != os.Stderr && writer != os.Stdout {
+		// os.Stderr and os.Stdout are not wrapped to detect the terminal
+		registry.configWriter = &configWriter{writer: writer}
+		registry.writer = registry.configWriter
+	}
+	registry.update()
+	if previous != nil {
+		return previous.closeTo(registry.writer)
+	}
+	return nil
+}
+
+// Load configuration from JSON file, and reload it when the file is modified (or SIGHUP is received if OnSignal is true)
+// until the context is done. Options are swapped atomically, and the previous output is closed after writes in flight are drained,
+// so records are not dropped while reloading.
+func (registry *Registry) WatchConfig(ctx context.Context, path string, options *WatchConfigOptions) error {
+	// set default parameters
+	if options == nil {
+		options = &WatchConfigOptions{}
+	}
+	if options.Interval <= 0 {
+		options.Interval = DEFAULT_WATCH_INTERVAL
+	}
+
+	err := registry.LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	modTime := configModTime(path)
+
+	signals := make(chan os.Signal, 1)
+	if options.OnSignal {
+		notifyReload(signals)
+	}
+	go func() {
+		defer signal.Stop(signals)
+		ticker := time.NewTicker(options.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+			case <-ticker.C:
+				current := configModTime(path)
+				if current.Equal(modTime) {
+					continue
+				}
+				modTime = current
+			}
+			err := registry.LoadConfig(path)
+			if err != nil && options.OnError != nil {
+				options.OnError(err)
+			}
+		}
+	}()
+	return nil
+}
+
+// Get modification time of the file, which is zero if it is not available.
+func configModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,14 @@
+//go:build unix || windows
+
+package nslog
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Notify the channel of SIGHUP to reload the configuration.
+func notifyReload(signals chan<- os.Signal) {
+	signal.Notify(signals, syscall.SIGHUP)
+}
@@ -0,0 +1,8 @@
+//go:build !unix && !windows
+
+package nslog
+
+import "os"
+
+// SIGHUP is not available on this platform, so the configuration is reloaded only when the file is modified.
+func notifyReload(signals chan<- os.Signal) {}
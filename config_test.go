@@ -0,0 +1,136 @@
+package nslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigOptions(t *testing.T) {
+	config := &Config{Level: "debug", GroupLevels: map[string]string{"db": "WARN"}, ColorMode: "never", AddSourceLevel: "ERROR", TimeLayout: TIME_LAYOUT_MILLIS}
+	options, err := config.Options()
+	assert.NoError(t, err)
+	assert.Equal(t, slog.LevelDebug, options.Level)
+	assert.Equal(t, slog.LevelWarn, options.GroupLevels["db"])
+	assert.Equal(t, ColorModeNever, options.ColorMode)
+	assert.Equal(t, slog.LevelError, options.AddSourceLevel)
+	assert.Equal(t, TIME_LAYOUT_MILLIS, options.TimeLayout)
+
+	_, err = (&Config{Level: "verbose"}).Options()
+	assert.Error(t, err)
+	_, err = (&Config{ColorMode: "sometimes"}).Options()
+	assert.Error(t, err)
+}
+
+func TestRegistryLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nslog.json")
+	output := filepath.Join(dir, "app.log")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"level": "DEBUG", "output": "`+filepath.ToSlash(output)+`"}`), 0o644))
+
+	buf := new(bytes.Buffer)
+	registry := NewRegistry(buf, nil)
+	logger := registry.Named("app")
+	logger.Debug("message1")
+	assert.NoError(t, registry.LoadConfig(path))
+	logger.Debug("message2")
+	assert.Equal(t, slog.LevelDebug, registry.Options().Level)
+	assert.NoError(t, registry.configWriter.Close())
+
+	data, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Empty(t, buf.String())
+	assert.Contains(t, string(data), "DEBUG app: message2\n")
+}
+
+func TestRegistryLoadConfigReload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nslog.json")
+	output1 := filepath.Join(dir, "app1.log")
+	output2 := filepath.Join(dir, "app2.log")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"output": "`+filepath.ToSlash(output1)+`"}`), 0o644))
+
+	registry := NewRegistry(new(bytes.Buffer), nil)
+	assert.NoError(t, registry.LoadConfig(path))
+	previous := registry.base.Load()
+	assert.NoError(t, os.WriteFile(path, []byte(`{"output": "`+filepath.ToSlash(output2)+`"}`), 0o644))
+	assert.NoError(t, registry.LoadConfig(path))
+
+	// the handler created before reloading writes to the next output instead of the closed one
+	assert.NoError(t, previous.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "message1", 0)))
+	registry.Named("app").Info("message2")
+	assert.NoError(t, registry.configWriter.Close())
+	assert.ErrorIs(t, previous.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "message3", 0)), os.ErrClosed)
+
+	data, err := os.ReadFile(output1)
+	assert.NoError(t, err)
+	assert.Empty(t, string(data))
+	data, err = os.ReadFile(output2)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "INFO. message1\n")
+	assert.Contains(t, string(data), "INFO. app: message2\n")
+}
+
+func TestRegistryLoadConfigConcurrently(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nslog.json")
+	outputs := []string{filepath.Join(dir, "app1.log"), filepath.Join(dir, "app2.log")}
+
+	registry := NewRegistry(new(bytes.Buffer), nil)
+	load := func(output string) {
+		assert.NoError(t, os.WriteFile(path, []byte(`{"output": "`+filepath.ToSlash(output)+`"}`), 0o644))
+		assert.NoError(t, registry.LoadConfig(path))
+	}
+	logger := registry.Named("app")
+	load(outputs[0])
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.Info("message")
+			}
+		}()
+	}
+	for i := 1; i <= 10; i++ {
+		load(outputs[i%len(outputs)])
+	}
+	wg.Wait()
+	assert.NoError(t, registry.configWriter.Close())
+
+	// records are not dropped by reloading
+	var text string
+	for _, output := range outputs {
+		data, err := os.ReadFile(output)
+		assert.NoError(t, err)
+		text += string(data)
+	}
+	assert.Equal(t, 400, strings.Count(text, "INFO. app: message\n"))
+}
+
+func TestRegistryWatchConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nslog.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"level": "WARN"}`), 0o644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	buf := new(bytes.Buffer)
+	registry := NewRegistry(buf, nil)
+	assert.NoError(t, registry.WatchConfig(ctx, path, &WatchConfigOptions{Interval: 10 * time.Millisecond}))
+	assert.Equal(t, slog.LevelWarn, registry.Options().Level)
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"level": "DEBUG"}`), 0o644))
+	assert.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+	assert.Eventually(t, func() bool {
+		return registry.Options().Level == slog.LevelDebug
+	}, time.Second, 10*time.Millisecond)
+}
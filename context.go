@@ -0,0 +1,48 @@
+package nslog
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+type loggerKey struct{}
+type attrsKey struct{}
+
+var fallbackLogger atomic.Pointer[slog.Logger]
+
+// Create a new context with the logger, which can be taken by [nslog.FromContext].
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Get the logger in the context.
+// If the context has no logger, the fallback logger set by [nslog.SetFallbackLogger] is returned,
+// or [slog.Default] is returned if the fallback logger is not set.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+	if logger := fallbackLogger.Load(); logger != nil {
+		return logger
+	}
+	return slog.Default()
+}
+
+// Create a new context with the attributes added, which are output by [nslog.LogHandler] for records logged with the context.
+// It is used to flow request-scoped metadata such as request ID into log lines.
+func AddContextAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	current := ContextAttrs(ctx)
+	return context.WithValue(ctx, attrsKey{}, append(current[:len(current):len(current)], attrs...))
+}
+
+// Get the attributes added by [nslog.AddContextAttrs].
+func ContextAttrs(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// Set the logger returned by [nslog.FromContext] if the context has no logger. Set nil to use [slog.Default].
+func SetFallbackLogger(logger *slog.Logger) {
+	fallbackLogger.Store(logger)
+}
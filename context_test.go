@@ -0,0 +1,39 @@
+package nslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, nil).With("id", 1)
+	ctx := NewContext(context.Background(), log)
+	FromContext(ctx).Info("message")
+	assert.Contains(t, buf.String(), "INFO. [id=1]: message")
+}
+
+func TestContextAttrs(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, nil)
+	ctx := AddContextAttrs(context.Background(), slog.String("request_id", "1234"))
+	ctx2 := AddContextAttrs(ctx, slog.Int("user_id", 1))
+	log.InfoContext(ctx2, "message", "key1", "val1")
+	log.InfoContext(ctx, "message")
+	assert.Contains(t, buf.String(), "INFO. message key1=val1 request_id=1234 user_id=1\n")
+	assert.Contains(t, buf.String(), "INFO. message request_id=1234\n")
+}
+
+func TestContextFallback(t *testing.T) {
+	assert.Equal(t, slog.Default(), FromContext(context.Background()))
+
+	buf := new(bytes.Buffer)
+	SetFallbackLogger(NewLogger(buf, nil))
+	defer SetFallbackLogger(nil)
+	FromContext(context.Background()).Info("message")
+	assert.Contains(t, buf.String(), "INFO. message")
+}
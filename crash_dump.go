@@ -0,0 +1,116 @@
+package nslog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const DEFAULT_CRASH_DUMP_PATH = "crash-{pid}-{20060102-150405}.log"
+
+// An option to customize crash dump written by [nslog.Fatal].
+type CrashDumpOptions struct {
+	Path    string       // Set path pattern of the crash file, where "{pid}" is replaced with PID and "{layout}" is replaced with the time formatted by the layout. (default: DEFAULT_CRASH_DUMP_PATH)
+	Ring    *RingHandler // Set ring handler whose recent records such as Debug logs are dumped. (default: nil, which dumps no records)
+	Perm    os.FileMode  // Set permission of the crash file. (default: 0644)
+	OnError func(error)  // Set function called when writing the crash file is failed. (default: nil, which writes the error to os.Stderr)
+}
+
+var crashDumpPathRegexp = regexp.MustCompile(`\{[^{}]*\}`)
+
+// Options of crash dump set by [nslog.SetCrashDump], which is nil if crash dump is disabled.
+var crashDump atomic.Pointer[CrashDumpOptions]
+
+// Enable crash dump, which is written by [nslog.Fatal] and [nslog.Fatalf] before the exit hooks are called, like panic logs of servers.
+// The crash file has the exit code, recent records of the ring handler, and stacks of all goroutines.
+// [nslog.Panic] does not write the crash file since the runtime prints the stacks if the panic is not recovered.
+// Crash dump is disabled if the options is nil.
+func SetCrashDump(options *CrashDumpOptions) {
+	if options == nil {
+		crashDump.Store(nil)
+		return
+	}
+
+	// set default parameters
+	dumpOptions := *options
+	if dumpOptions.Path == "" {
+		dumpOptions.Path = DEFAULT_CRASH_DUMP_PATH
+	}
+	if dumpOptions.Perm == 0 {
+		dumpOptions.Perm = DEFAULT_FILE_PERM
+	}
+	crashDump.Store(&dumpOptions)
+}
+
+// Get path of the crash file from the pattern such as "/var/log/app/crash-{pid}-{20060102-150405}.log".
+func CrashDumpPath(pattern string, t time.Time) string {
+	return crashDumpPathRegexp.ReplaceAllStringFunc(pattern, func(field string) string {
+		if field == "{pid}" {
+			return strconv.Itoa(os.Getpid())
+		}
+		return t.Format(field[1 : len(field)-1])
+	})
+}
+
+// Write the crash file if crash dump is enabled and the exit code is not 0.
+func writeCrashDump(code int) {
+	options := crashDump.Load()
+	if options == nil || code == 0 {
+		return
+	}
+	path, err := options.write(code, time.Now())
+	if err != nil {
+		if options.OnError != nil {
+			options.OnError(err)
+		} else {
+			fmt.Fprintf(os.Stderr, "nslog: failed to write crash dump: %v\n", err)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "nslog: crash dump is written to %s\n", path)
+}
+
+// Write the crash file, and return the path.
+func (options *CrashDumpOptions) write(code int, now time.Time) (string, error) {
+	path := CrashDumpPath(options.Path, now)
+	if dir := filepath.Dir(path); dir != "." {
+		err := os.MkdirAll(dir, 0o755)
+		if err != nil {
+			return path, err
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "nslog crash dump\n")
+	fmt.Fprintf(buf, "time: %s\n", now.Format(time.RFC3339Nano))
+	fmt.Fprintf(buf, "exit code: %d\n", code)
+	fmt.Fprintf(buf, "pid: %d\n", os.Getpid())
+	fmt.Fprintf(buf, "go: %s\n", runtime.Version())
+	if options.Ring != nil {
+		fmt.Fprintf(buf, "\n--- recent records (%d) ---\n", options.Ring.Len())
+		_ = options.Ring.Dump(buf)
+	}
+	fmt.Fprintf(buf, "\n--- goroutines ---\n")
+	buf.Write(goroutineStacks())
+
+	return path, os.WriteFile(path, buf.Bytes(), options.Perm)
+}
+
+// Get stacks of all goroutines, growing the buffer until the stacks fit.
+func goroutineStacks() []byte {
+	stack := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(stack, true)
+		if n < len(stack) {
+			return []byte(strings.TrimRight(string(stack[:n]), "\n") + "\n")
+		}
+		stack = make([]byte, 2*len(stack))
+	}
+}
@@ -0,0 +1,53 @@
+package nslog
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrashDump(t *testing.T) {
+	resetExitHooks(t)
+	setExitFunc(t, func(code int) {})
+	t.Cleanup(func() { SetCrashDump(nil) })
+
+	dir := t.TempDir()
+	ring := NewRingHandler(nil, 10, &LogHandlerOptions{OmitTime: true})
+	SetCrashDump(&CrashDumpOptions{Path: filepath.Join(dir, "dump", "crash-{pid}.log"), Ring: ring})
+
+	logger := slog.New(ring)
+	logger.Debug("debug message")
+	Fatal(logger, "fatal message")
+
+	data, err := os.ReadFile(filepath.Join(dir, "dump", fmt.Sprintf("crash-%d.log", os.Getpid())))
+	assert.NoError(t, err)
+	assert.Regexp(t, "^nslog crash dump\ntime: .+\nexit code: 1\npid: [0-9]+\ngo: go.+\n\n"+
+		"--- recent records \\(2\\) ---\nDEBUG debug message\nFATAL fatal message \\(crash_dump_test\\.go:[0-9]+\\)\n\n"+
+		"--- goroutines ---\ngoroutine [0-9]+ \\[running\\]:\n", string(data))
+	assert.Contains(t, string(data), "nslog.TestCrashDump")
+}
+
+func TestCrashDumpError(t *testing.T) {
+	resetExitHooks(t)
+	setExitFunc(t, func(code int) {})
+	t.Cleanup(func() { SetCrashDump(nil) })
+
+	file := filepath.Join(t.TempDir(), "file")
+	assert.NoError(t, os.WriteFile(file, nil, 0o644))
+	var dumpErr error
+	SetCrashDump(&CrashDumpOptions{Path: filepath.Join(file, "crash.log"), OnError: func(err error) { dumpErr = err }})
+	Fatalf(slog.New(NewLogHandler(new(bytes.Buffer), nil)), "fatal %d", 1)
+	assert.Error(t, dumpErr)
+}
+
+func TestCrashDumpPath(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	assert.Equal(t, fmt.Sprintf("/var/log/crash-%d-20240102-150405.log", os.Getpid()), CrashDumpPath("/var/log/"+DEFAULT_CRASH_DUMP_PATH, now))
+	assert.Equal(t, "crash.log", CrashDumpPath("crash.log", now))
+}
@@ -0,0 +1,180 @@
+package nslog
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DEFAULT_DIGEST_LEVEL = slog.LevelError
+const DEFAULT_DIGEST_INTERVAL = time.Minute
+const DEFAULT_DIGEST_TOP = 5
+const DEFAULT_DIGEST_MESSAGE = "error digest"
+
+// An option to customize [nslog.DigestHandler].
+type DigestHandlerOptions struct {
+	Level       slog.Leveler  // Set lowest level of records to accumulate. (default: slog.LevelError)
+	Interval    time.Duration // Set interval to emit the digest. (default: 1 minute)
+	Top         int           // Set number of top messages by count in the digest. (default: 5)
+	Message     string        // Set message of the digest record. (default: "error digest")
+	PassThrough bool          // Pass accumulated records to the next handler as well if it is true. Drop them if it is false. (default: false)
+}
+
+// A handler to accumulate records at or above the level and periodically emit a digest record to the next handler,
+// which has the total count and top messages by count in the last interval such as:
+//
+//	2024/10/31 11:22:33 ERROR error digest interval=1m0s total=42 distinct=2 top="\"connection refused\" (30), \"timeout\" (12)"
+//
+// It is useful for noisy retry loops where individual errors are too chatty. Records below the level are passed to the next handler.
+type DigestHandler struct {
+	next    slog.Handler
+	options DigestHandlerOptions
+	state   *digestState
+}
+
+type digestState struct {
+	next   slog.Handler // handler to emit the digest, which has no attributes and groups of derived handlers
+	mutex  sync.Mutex
+	counts map[string]int
+	order  []string   // messages in order of first occurrence, so messages with the same count are stable
+	level  slog.Level // highest level of accumulated records
+	start  time.Time  // start of the interval
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Create a new [nslog.DigestHandler] object, which starts goroutine to emit the digest periodically.
+// Call [DigestHandler.Close] to stop the goroutine and emit the last digest.
+func NewDigestHandler(next slog.Handler, options *DigestHandlerOptions) *DigestHandler {
+	// set default parameters
+	if options == nil {
+		options = &DigestHandlerOptions{}
+	}
+	if options.Level == nil {
+		options.Level = DEFAULT_DIGEST_LEVEL
+	}
+	if options.Interval <= 0 {
+		options.Interval = DEFAULT_DIGEST_INTERVAL
+	}
+	if options.Top <= 0 {
+		options.Top = DEFAULT_DIGEST_TOP
+	}
+	if options.Message == "" {
+		options.Message = DEFAULT_DIGEST_MESSAGE
+	}
+
+	handler := &DigestHandler{
+		next:    next,
+		options: *options,
+		state: &digestState{
+			next:   next,
+			counts: map[string]int{},
+			start:  time.Now(),
+			done:   make(chan struct{}),
+		},
+	}
+	go handler.run()
+	return handler
+}
+
+func (handler *DigestHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= handler.options.Level.Level() || handler.next.Enabled(ctx, level)
+}
+
+func (handler *DigestHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &DigestHandler{
+		next:    handler.next.WithAttrs(attrs),
+		options: handler.options,
+		state:   handler.state,
+	}
+}
+
+func (handler *DigestHandler) WithGroup(name string) slog.Handler {
+	return &DigestHandler{
+		next:    handler.next.WithGroup(name),
+		options: handler.options,
+		state:   handler.state,
+	}
+}
+
+func (handler *DigestHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level < handler.options.Level.Level() {
+		return handler.next.Handle(ctx, record)
+	}
+
+	state := handler.state
+	state.mutex.Lock()
+	if len(state.order) == 0 || record.Level > state.level {
+		state.level = record.Level
+	}
+	if _, ok := state.counts[record.Message]; !ok {
+		state.order = append(state.order, record.Message)
+	}
+	state.counts[record.Message]++
+	state.mutex.Unlock()
+
+	if handler.options.PassThrough && handler.next.Enabled(ctx, record.Level) {
+		return handler.next.Handle(ctx, record)
+	}
+	return nil
+}
+
+// Emit the digest of records accumulated since the last digest, which is not emitted if no record is accumulated.
+func (handler *DigestHandler) Flush() error {
+	state := handler.state
+	state.mutex.Lock()
+	counts, order, level, start := state.counts, state.order, state.level, state.start
+	state.counts = map[string]int{}
+	state.order = nil
+	state.start = time.Now()
+	state.mutex.Unlock()
+	if len(order) == 0 {
+		return nil
+	}
+
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	slices.SortStableFunc(order, func(a, b string) int {
+		return counts[b] - counts[a]
+	})
+	var top []string
+	for _, message := range order[:min(len(order), handler.options.Top)] {
+		top = append(top, strconv.Quote(message)+" ("+strconv.Itoa(counts[message])+")")
+	}
+
+	record := slog.NewRecord(time.Now(), level, handler.options.Message, 0)
+	record.AddAttrs(
+		slog.Duration("interval", time.Since(start).Round(time.Millisecond)),
+		slog.Int("total", total),
+		slog.Int("distinct", len(order)),
+		slog.String("top", strings.Join(top, ", ")),
+	)
+	return state.next.Handle(context.Background(), record)
+}
+
+// Stop goroutine and emit the last digest.
+func (handler *DigestHandler) Close() error {
+	handler.state.once.Do(func() {
+		close(handler.state.done)
+	})
+	return handler.Flush()
+}
+
+func (handler *DigestHandler) run() {
+	ticker := time.NewTicker(handler.options.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-handler.state.done:
+			return
+		case <-ticker.C:
+			_ = handler.Flush()
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package nslog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDigestHandler(t *testing.T) {
+	buf := new(bytes.Buffer)
+	handler := NewDigestHandler(NewLogHandler(buf, &LogHandlerOptions{OmitTime: true}), &DigestHandlerOptions{Top: 2})
+	defer handler.Close()
+	log := slog.New(handler)
+	log.Info("retrying")
+	for i := 0; i < 3; i++ {
+		log.Error("connection refused", "attempt", i)
+	}
+	log.With("key", "val").Error("timeout")
+	log.Error("timeout")
+	log.Error("disk full")
+	assert.Equal(t, "INFO. retrying\n", buf.String())
+
+	assert.NoError(t, handler.Flush())
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 2)
+	assert.Regexp(t, "^ERROR error digest interval=[0-9.]+m?s total=6 distinct=3 top=\"connection refused\" \\(3\\), \"timeout\" \\(2\\)$", lines[1])
+
+	// nothing is emitted without records
+	buf.Reset()
+	assert.NoError(t, handler.Flush())
+	assert.Empty(t, buf.String())
+}
+
+func TestDigestHandlerPassThrough(t *testing.T) {
+	buf := new(bytes.Buffer)
+	handler := NewDigestHandler(NewLogHandler(buf, &LogHandlerOptions{OmitTime: true, AddSourceLevel: slog.LevelError + 1}), &DigestHandlerOptions{Level: slog.LevelWarn, PassThrough: true, Message: "warning digest"})
+	log := slog.New(handler)
+	log.Warn("slow query")
+	assert.NoError(t, handler.Close())
+	assert.Regexp(t, "^WARN\\. slow query\nWARN\\. warning digest interval=.+ total=1 distinct=1 top=\"slow query\" \\(1\\)\n$", buf.String())
+}
+
+func TestDigestHandlerInterval(t *testing.T) {
+	buf := new(bytes.Buffer)
+	next := NewLogHandler(buf, nil)
+	handler := NewDigestHandler(next, &DigestHandlerOptions{Interval: 10 * time.Millisecond})
+	defer handler.Close()
+	slog.New(handler).Error("connection refused")
+	time.Sleep(50 * time.Millisecond)
+	next.mutex.Lock()
+	defer next.mutex.Unlock()
+	assert.Contains(t, buf.String(), "error digest")
+}
@@ -0,0 +1,372 @@
+package nslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const DEFAULT_ELASTICSEARCH_INDEX = "nslog-{2006.01.02}"
+const DEFAULT_ELASTICSEARCH_BATCH_SIZE = 500
+const DEFAULT_ELASTICSEARCH_FLUSH_INTERVAL = 5 * time.Second
+const DEFAULT_ELASTICSEARCH_QUEUE_SIZE = 10000
+const DEFAULT_ELASTICSEARCH_RETRIES = 3
+const DEFAULT_ELASTICSEARCH_RETRY_BACKOFF = time.Second
+const DEFAULT_ELASTICSEARCH_TIMEOUT = 30 * time.Second
+
+var elasticsearchIndexRegexp = regexp.MustCompile(`\{[^{}]*\}`)
+
+// An option to customize [nslog.ElasticsearchHandler].
+type ElasticsearchHandlerOptions struct {
+	Level         slog.Leveler  // Set minimum level to index records. (default: slog.LevelInfo)
+	Index         string        // Set template of index name, where time layout in braces is replaced by UTC time of the record. (default: "nslog-{2006.01.02}")
+	BatchSize     int           // Set maximum number of documents in a bulk request. (default: 500)
+	FlushInterval time.Duration // Set interval to send queued documents. (default: 5 seconds)
+	QueueSize     int           // Set size of queue of documents waiting to be sent. (default: 10000)
+	Block         bool          // Wait until the queue has room if it is true. Drop the document if the queue is full if it is false. (default: false)
+	Retries       int           // Set number of retries of a bulk request for network errors, 429, and 5xx, which is disabled if it is negative. (default: 3)
+	RetryBackoff  time.Duration // Set time to wait before the first retry, which is doubled per retry. (default: 1 second)
+	Username      string        // Set username of basic authentication. (default: "")
+	Password      string        // Set password of basic authentication. (default: "")
+	APIKey        string        // Set API key sent as "Authorization: ApiKey <key>". (default: "")
+	AddSource     bool          // Add source location of the record as "log.origin" field if it is true. (default: false)
+	Client        *http.Client  // Set HTTP client to send bulk requests. (default: client with 30 seconds timeout)
+	OnError       func(error)   // Set function called when indexing documents is failed. (default: nil)
+}
+
+// A handler to convert records to JSON documents and index them by the _bulk API of Elasticsearch asynchronously,
+// so small applications can ship logs without Logstash. Attributes are fields of the document where groups are nested objects.
+type ElasticsearchHandler struct {
+	fields jsonAttrs
+	sender *elasticsearchSender
+}
+
+type elasticsearchDocument struct {
+	index string
+	body  []byte
+}
+
+type elasticsearchSender struct {
+	url     string
+	options ElasticsearchHandlerOptions
+	queue   chan elasticsearchDocument
+	flush   chan chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	stopped atomic.Bool
+	dropped atomic.Uint64
+	mutex   sync.RWMutex // guard of closed, which is locked for reading to send and for writing to close the queue
+	closed  bool
+}
+
+type elasticsearchBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  any `json:"error"`
+	} `json:"items"`
+}
+
+// Create a new [nslog.ElasticsearchHandler] object, which starts goroutine to send documents.
+// The url is the base URL of Elasticsearch such as "http://localhost:9200".
+// Call [ElasticsearchHandler.Close] to send queued documents and stop the goroutine.
+func NewElasticsearchHandler(url string, options *ElasticsearchHandlerOptions) *ElasticsearchHandler {
+	// set default parameters
+	if options == nil {
+		options = &ElasticsearchHandlerOptions{}
+	}
+	if options.Level == nil {
+		options.Level = slog.LevelInfo
+	}
+	if options.Index == "" {
+		options.Index = DEFAULT_ELASTICSEARCH_INDEX
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = DEFAULT_ELASTICSEARCH_BATCH_SIZE
+	}
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = DEFAULT_ELASTICSEARCH_FLUSH_INTERVAL
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = DEFAULT_ELASTICSEARCH_QUEUE_SIZE
+	}
+	if options.Retries < 0 {
+		options.Retries = 0
+	} else if options.Retries == 0 {
+		options.Retries = DEFAULT_ELASTICSEARCH_RETRIES
+	}
+	if options.RetryBackoff <= 0 {
+		options.RetryBackoff = DEFAULT_ELASTICSEARCH_RETRY_BACKOFF
+	}
+	if options.Client == nil {
+		options.Client = &http.Client{Timeout: DEFAULT_ELASTICSEARCH_TIMEOUT}
+	}
+
+	sender := &elasticsearchSender{
+		url:     strings.TrimSuffix(url, "/") + "/_bulk",
+		options: *options,
+		queue:   make(chan elasticsearchDocument, options.QueueSize),
+		flush:   make(chan chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go sender.run()
+
+	return &ElasticsearchHandler{sender: sender}
+}
+
+func (handler *ElasticsearchHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= handler.sender.options.Level.Level()
+}
+
+func (handler *ElasticsearchHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ElasticsearchHandler{
+		fields: handler.fields.withAttrs(attrs),
+		sender: handler.sender,
+	}
+}
+
+func (handler *ElasticsearchHandler) WithGroup(name string) slog.Handler {
+	return &ElasticsearchHandler{
+		fields: handler.fields.withGroup(name),
+		sender: handler.sender,
+	}
+}
+
+func (handler *ElasticsearchHandler) Handle(ctx context.Context, record slog.Record) error {
+	document := handler.fields.object(record)
+	recordTime := record.Time
+	if recordTime.IsZero() {
+		recordTime = time.Now()
+	}
+	document["@timestamp"] = recordTime.Format(time.RFC3339Nano)
+	document["log.level"] = record.Level.String()
+	document["message"] = record.Message
+	if handler.sender.options.AddSource && record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		document["log.origin"] = map[string]any{
+			"file":     map[string]any{"name": frame.File, "line": frame.Line},
+			"function": frame.Function,
+		}
+	}
+
+	body, err := json.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("nslog: failed to marshal elasticsearch document: %w", err)
+	}
+	return handler.sender.enqueue(ctx, elasticsearchDocument{
+		index: ElasticsearchIndex(handler.sender.options.Index, recordTime),
+		body:  body,
+	})
+}
+
+// Send queued documents and wait until they are sent.
+func (handler *ElasticsearchHandler) Flush() {
+	flushed := make(chan struct{})
+	select {
+	case handler.sender.flush <- flushed:
+		<-flushed
+	case <-handler.sender.done:
+	}
+}
+
+// Stop goroutine after sending queued documents.
+func (handler *ElasticsearchHandler) Close() error {
+	handler.sender.stopped.Store(true)
+	handler.sender.once.Do(func() {
+		handler.sender.mutex.Lock()
+		handler.sender.closed = true
+		close(handler.sender.queue)
+		handler.sender.mutex.Unlock()
+	})
+	<-handler.sender.done
+	return nil
+}
+
+// Get number of documents waiting in the queue.
+func (handler *ElasticsearchHandler) QueueDepth() int {
+	return len(handler.sender.queue)
+}
+
+// Get number of documents dropped because the queue is full or indexing is failed, which is shared with derived handlers.
+func (handler *ElasticsearchHandler) Dropped() uint64 {
+	return handler.sender.dropped.Load()
+}
+
+// Get index name from the template, where time layout in braces is replaced by UTC time such as "nslog-{2006.01.02}" to "nslog-2024.10.31".
+func ElasticsearchIndex(template string, t time.Time) string {
+	t = t.UTC()
+	return elasticsearchIndexRegexp.ReplaceAllStringFunc(template, func(layout string) string {
+		return t.Format(layout[1 : len(layout)-1])
+	})
+}
+
+func (sender *elasticsearchSender) enqueue(ctx context.Context, document elasticsearchDocument) error {
+	if sender.stopped.Load() {
+		return nil
+	}
+
+	// the goroutine keeps receiving until the queue is closed, so blocked senders do not block Close forever
+	sender.mutex.RLock()
+	defer sender.mutex.RUnlock()
+	if sender.closed {
+		sender.dropped.Add(1)
+		return nil
+	}
+	if sender.options.Block {
+		select {
+		case sender.queue <- document:
+		case <-ctx.Done():
+			sender.dropped.Add(1)
+			return ctx.Err()
+		}
+		return nil
+	}
+	select {
+	case sender.queue <- document:
+	default:
+		sender.dropped.Add(1)
+	}
+	return nil
+}
+
+func (sender *elasticsearchSender) run() {
+	defer close(sender.done)
+	ticker := time.NewTicker(sender.options.FlushInterval)
+	defer ticker.Stop()
+
+	var batch []elasticsearchDocument
+	for {
+		select {
+		case document, ok := <-sender.queue:
+			if !ok {
+				sender.send(batch)
+				return
+			}
+			batch = append(batch, document)
+			if len(batch) >= sender.options.BatchSize {
+				sender.send(batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			sender.send(batch)
+			batch = nil
+		case flushed := <-sender.flush:
+			for len(sender.queue) > 0 {
+				document, ok := <-sender.queue
+				if !ok {
+					break
+				}
+				batch = append(batch, document)
+				if len(batch) >= sender.options.BatchSize {
+					sender.send(batch)
+					batch = nil
+				}
+			}
+			sender.send(batch)
+			batch = nil
+			close(flushed)
+		}
+	}
+}
+
+// Send the documents by a bulk request. Documents rejected by 429 are retried with the request.
+func (sender *elasticsearchSender) send(batch []elasticsearchDocument) {
+	backoff := sender.options.RetryBackoff
+	for retry := 0; len(batch) > 0; retry++ {
+		rejected, err := sender.post(batch)
+		if err == nil {
+			return
+		}
+		if retry >= sender.options.Retries || !errors.Is(err, errElasticsearchRetryable) {
+			sender.dropped.Add(uint64(len(rejected)))
+			if sender.options.OnError != nil {
+				sender.options.OnError(err)
+			}
+			return
+		}
+		batch = rejected
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+var errElasticsearchRetryable = errors.New("nslog: elasticsearch is temporarily unavailable")
+
+// Post the documents and get documents which are not indexed with the error.
+// The error wraps errElasticsearchRetryable if the request or some documents can be retried.
+func (sender *elasticsearchSender) post(batch []elasticsearchDocument) ([]elasticsearchDocument, error) {
+	var body bytes.Buffer
+	for _, document := range batch {
+		action, _ := json.Marshal(map[string]any{"index": map[string]string{"_index": document.index}})
+		body.Write(action)
+		body.WriteByte('\n')
+		body.Write(document.body)
+		body.WriteByte('\n')
+	}
+
+	request, err := http.NewRequest(http.MethodPost, sender.url, &body)
+	if err != nil {
+		return batch, err
+	}
+	request.Header.Set("Content-Type", "application/x-ndjson")
+	if sender.options.APIKey != "" {
+		request.Header.Set("Authorization", "ApiKey "+sender.options.APIKey)
+	} else if sender.options.Username != "" {
+		request.SetBasicAuth(sender.options.Username, sender.options.Password)
+	}
+
+	response, err := sender.options.Client.Do(request)
+	if err != nil {
+		return batch, fmt.Errorf("%w: %w", errElasticsearchRetryable, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500 {
+		return batch, fmt.Errorf("%w: status %s", errElasticsearchRetryable, response.Status)
+	}
+	if response.StatusCode >= 300 {
+		return batch, fmt.Errorf("nslog: elasticsearch returned status %s", response.Status)
+	}
+
+	result := elasticsearchBulkResponse{}
+	err = json.NewDecoder(response.Body).Decode(&result)
+	if err != nil || !result.Errors {
+		return nil, nil
+	}
+
+	// documents failed individually, where only rejected ones by 429 can be retried
+	var retryable, failed []elasticsearchDocument
+	var cause any
+	for i, item := range result.Items {
+		for _, status := range item {
+			if i >= len(batch) || status.Status < 300 {
+				continue
+			}
+			if status.Status == http.StatusTooManyRequests {
+				retryable = append(retryable, batch[i])
+			} else {
+				failed = append(failed, batch[i])
+				cause = status.Error
+			}
+		}
+	}
+	if len(failed) > 0 {
+		sender.dropped.Add(uint64(len(failed)))
+		if sender.options.OnError != nil {
+			sender.options.OnError(fmt.Errorf("nslog: elasticsearch failed to index %d documents: %v", len(failed), cause))
+		}
+	}
+	if len(retryable) > 0 {
+		return retryable, fmt.Errorf("%w: %d documents are rejected", errElasticsearchRetryable, len(retryable))
+	}
+	return nil, nil
+}
@@ -0,0 +1,154 @@
+package nslog
+
+import (
+	"bufio"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestElasticsearchHandler(t *testing.T) {
+	var mutex sync.Mutex
+	var actions, documents []map[string]any
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_bulk", r.URL.Path)
+		mutex.Lock()
+		defer mutex.Unlock()
+		auth = r.Header.Get("Authorization")
+		scanner := bufio.NewScanner(r.Body)
+		for i := 0; scanner.Scan(); i++ {
+			line := map[string]any{}
+			_ = json.Unmarshal(scanner.Bytes(), &line)
+			if i%2 == 0 {
+				actions = append(actions, line)
+			} else {
+				documents = append(documents, line)
+			}
+		}
+		_, _ = w.Write([]byte(`{"errors":false,"items":[]}`))
+	}))
+	defer server.Close()
+
+	handler := NewElasticsearchHandler(server.URL+"/", &ElasticsearchHandlerOptions{Index: "logs-{2006.01}", APIKey: "key"})
+	log := slog.New(handler).With("id", 1).WithGroup("Main")
+	log.Debug("debug message")
+	log.Info("info message", "key1", "val1")
+	handler.Flush()
+	log.Error("error message")
+	handler.Close()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, "ApiKey key", auth)
+	assert.Len(t, documents, 2)
+	assert.Equal(t, map[string]any{"index": map[string]any{"_index": "logs-" + time.Now().UTC().Format("2006.01")}}, actions[0])
+	assert.Equal(t, "info message", documents[0]["message"])
+	assert.Equal(t, "INFO", documents[0]["log.level"])
+	assert.Equal(t, float64(1), documents[0]["id"])
+	assert.Equal(t, map[string]any{"key1": "val1"}, documents[0]["Main"])
+	assert.Contains(t, documents[0], "@timestamp")
+	assert.Equal(t, "ERROR", documents[1]["log.level"])
+	assert.NotContains(t, documents[1], "Main")
+	assert.Equal(t, uint64(0), handler.Dropped())
+}
+
+func TestElasticsearchHandlerRetry(t *testing.T) {
+	var mutex sync.Mutex
+	requests := 0
+	var indexed []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var messages []string
+		scanner := bufio.NewScanner(r.Body)
+		for i := 0; scanner.Scan(); i++ {
+			if i%2 == 1 {
+				document := map[string]any{}
+				_ = json.Unmarshal(scanner.Bytes(), &document)
+				messages = append(messages, document["message"].(string))
+			}
+		}
+		if requests == 2 {
+			// the second document is rejected by 429 and the third one fails
+			indexed = append(indexed, messages[0])
+			_, _ = w.Write([]byte(`{"errors":true,"items":[{"index":{"status":201}},{"index":{"status":429}},{"index":{"status":400,"error":"mapping"}}]}`))
+			return
+		}
+		indexed = append(indexed, messages...)
+		_, _ = w.Write([]byte(`{"errors":false,"items":[]}`))
+	}))
+	defer server.Close()
+
+	var errs []error
+	handler := NewElasticsearchHandler(server.URL, &ElasticsearchHandlerOptions{RetryBackoff: time.Millisecond, OnError: func(err error) {
+		errs = append(errs, err)
+	}})
+	log := slog.New(handler)
+	log.Info("message 1")
+	log.Info("message 2")
+	log.Info("message 3")
+	handler.Close()
+
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, []string{"message 1", "message 2"}, indexed)
+	assert.Equal(t, uint64(1), handler.Dropped())
+	assert.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "failed to index 1 documents: mapping")
+}
+
+func TestElasticsearchHandlerQueueFull(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+
+	handler := NewElasticsearchHandler(server.URL, &ElasticsearchHandlerOptions{BatchSize: 1, QueueSize: 1})
+	log := slog.New(handler)
+	for i := 0; i < 10; i++ {
+		log.Info("log message")
+	}
+	close(release)
+	handler.Close()
+	assert.GreaterOrEqual(t, handler.Dropped(), uint64(8))
+}
+
+func TestElasticsearchHandlerClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errors":false,"items":[]}`))
+	}))
+	defer server.Close()
+
+	handler := NewElasticsearchHandler(server.URL, &ElasticsearchHandlerOptions{Block: true, QueueSize: 1})
+	log := slog.New(handler)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Info("log message")
+		}()
+	}
+	handler.Close()
+	wg.Wait()
+	assert.NotPanics(t, func() { log.Info("log message after close") })
+}
+
+func TestElasticsearchIndex(t *testing.T) {
+	date := time.Date(2024, 10, 31, 23, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	assert.Equal(t, "nslog-2024.10.31", ElasticsearchIndex(DEFAULT_ELASTICSEARCH_INDEX, date))
+	assert.Equal(t, "logs-2024-10", ElasticsearchIndex("logs-{2006-01}", date))
+	assert.Equal(t, "logs", ElasticsearchIndex("logs", date))
+}
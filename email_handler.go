@@ -0,0 +1,234 @@
+package nslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DEFAULT_EMAIL_LEVEL = slog.LevelError
+const DEFAULT_EMAIL_WINDOW = time.Minute
+const DEFAULT_EMAIL_MAX_RECORDS = 100
+const DEFAULT_EMAIL_RATE_LIMIT = 10
+const DEFAULT_EMAIL_RATE_INTERVAL = time.Hour
+const DEFAULT_EMAIL_SUBJECT = "nslog alert"
+
+// An option to customize [nslog.EmailHandler].
+type EmailHandlerOptions struct {
+	Level        slog.Leveler       // Set level to send records by email. (default: slog.LevelError)
+	From         string             // Set sender address of emails.
+	To           []string           // Set recipient addresses of emails.
+	Subject      string             // Set subject of emails, which is followed by number of records. (default: "nslog alert")
+	Auth         smtp.Auth          // Set authentication of the SMTP server. (default: nil)
+	Window       time.Duration      // Set time to collect records into an email after the first record. (default: 1 minute)
+	MaxRecords   int                // Set maximum number of records in an email. Exceeded records are counted only. (default: 100)
+	RateLimit    int                // Set maximum number of emails sent in RateInterval. Records of exceeded emails are suppressed. (default: 10)
+	RateInterval time.Duration      // Set interval for RateLimit. (default: 1 hour)
+	Format       *LogHandlerOptions // Set options to format records. Color is always disabled.
+	OnError      func(error)        // Set function called when sending email is failed. (default: nil)
+
+	// Set function to send an email, which is replaced for tests. (default: smtp.SendMail)
+	SendMail func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// A handler to send high-severity records by email via SMTP for deployments without chat or paging infrastructure.
+// Records in a window are sent as a digest in an email, and emails are rate-limited.
+type EmailHandler struct {
+	formatter *LogHandler
+	sender    *emailSender
+}
+
+type emailSender struct {
+	addr    string
+	options EmailHandlerOptions
+	mutex   sync.Mutex
+	lines   []string
+	omitted int         // number of records exceeding MaxRecords in the window
+	timer   *time.Timer // timer to send the email at the end of the window
+	sending sync.WaitGroup
+	closed  bool
+
+	windowStart time.Time
+	windowCount int
+	suppressed  int // number of records suppressed by RateLimit
+}
+
+// Create a new [nslog.EmailHandler] object to send emails via the SMTP server such as "smtp.example.com:25".
+// Call [EmailHandler.Close] to send the pending email.
+func NewEmailHandler(addr string, options *EmailHandlerOptions) (*EmailHandler, error) {
+	// set default parameters
+	if options == nil {
+		options = &EmailHandlerOptions{}
+	}
+	if options.Level == nil {
+		options.Level = DEFAULT_EMAIL_LEVEL
+	}
+	if options.Subject == "" {
+		options.Subject = DEFAULT_EMAIL_SUBJECT
+	}
+	if options.Window <= 0 {
+		options.Window = DEFAULT_EMAIL_WINDOW
+	}
+	if options.MaxRecords <= 0 {
+		options.MaxRecords = DEFAULT_EMAIL_MAX_RECORDS
+	}
+	if options.RateLimit <= 0 {
+		options.RateLimit = DEFAULT_EMAIL_RATE_LIMIT
+	}
+	if options.RateInterval <= 0 {
+		options.RateInterval = DEFAULT_EMAIL_RATE_INTERVAL
+	}
+	if options.SendMail == nil {
+		options.SendMail = smtp.SendMail
+	}
+
+	if options.From == "" {
+		return nil, errors.New("nslog: sender address of email is missing")
+	}
+	if len(options.To) == 0 {
+		return nil, errors.New("nslog: recipient address of email is missing")
+	}
+
+	var formatOptions LogHandlerOptions
+	if options.Format != nil {
+		formatOptions = *options.Format
+	}
+	formatter := NewLogHandler(nil, &formatOptions).WithOptions(func(options *LogHandlerOptions) {
+		options.AddColor = false
+		options.ColorMode = ColorModeDefault
+	})
+
+	return &EmailHandler{
+		formatter: formatter,
+		sender: &emailSender{
+			addr:    addr,
+			options: *options,
+		},
+	}, nil
+}
+
+func (handler *EmailHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= handler.sender.options.Level.Level()
+}
+
+func (handler *EmailHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &EmailHandler{
+		formatter: handler.formatter.WithAttrs(attrs).(*LogHandler),
+		sender:    handler.sender,
+	}
+}
+
+func (handler *EmailHandler) WithGroup(name string) slog.Handler {
+	return &EmailHandler{
+		formatter: handler.formatter.WithGroup(name).(*LogHandler),
+		sender:    handler.sender,
+	}
+}
+
+func (handler *EmailHandler) Handle(ctx context.Context, record slog.Record) error {
+	line := strings.TrimSuffix(string(handler.formatter.format(ctx, record)), handler.formatter.options.LineEnding)
+	handler.sender.add(line)
+	return nil
+}
+
+// Send the pending email immediately and wait until emails being sent are done.
+func (handler *EmailHandler) Flush() {
+	handler.sender.send()
+	handler.sender.sending.Wait()
+}
+
+// Send the pending email and stop accepting new records.
+func (handler *EmailHandler) Close() error {
+	handler.sender.mutex.Lock()
+	handler.sender.closed = true
+	handler.sender.mutex.Unlock()
+	handler.Flush()
+	return nil
+}
+
+func (sender *emailSender) add(line string) {
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+	if sender.closed {
+		return
+	}
+
+	if len(sender.lines) >= sender.options.MaxRecords {
+		sender.omitted++
+		return
+	}
+	sender.lines = append(sender.lines, line)
+	if sender.timer == nil {
+		sender.timer = time.AfterFunc(sender.options.Window, sender.send)
+	}
+}
+
+// Send the records in the window as an email if the rate limit allows.
+func (sender *emailSender) send() {
+	sender.mutex.Lock()
+	lines, omitted := sender.lines, sender.omitted
+	sender.lines, sender.omitted = nil, 0
+	if sender.timer != nil {
+		sender.timer.Stop()
+		sender.timer = nil
+	}
+	if len(lines) == 0 {
+		sender.mutex.Unlock()
+		return
+	}
+
+	// rate limit
+	now := time.Now()
+	if now.Sub(sender.windowStart) >= sender.options.RateInterval {
+		sender.windowStart = now
+		sender.windowCount = 0
+	}
+	if sender.windowCount >= sender.options.RateLimit {
+		sender.suppressed += len(lines) + omitted
+		sender.mutex.Unlock()
+		return
+	}
+	sender.windowCount++
+	suppressed := sender.suppressed
+	sender.suppressed = 0
+	sender.sending.Add(1)
+	sender.mutex.Unlock()
+
+	defer sender.sending.Done()
+	err := sender.options.SendMail(sender.addr, sender.options.Auth, sender.options.From, sender.options.To, sender.message(now, lines, omitted, suppressed))
+	if err != nil && sender.options.OnError != nil {
+		sender.options.OnError(fmt.Errorf("nslog: failed to send email: %w", err))
+	}
+}
+
+func (sender *emailSender) message(now time.Time, lines []string, omitted int, suppressed int) []byte {
+	count := len(lines) + omitted
+	subject := sender.options.Subject + " (" + strconv.Itoa(count) + " records)"
+
+	var message bytes.Buffer
+	message.WriteString("From: " + sender.options.From + "\r\n")
+	message.WriteString("To: " + strings.Join(sender.options.To, ", ") + "\r\n")
+	message.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	message.WriteString("Date: " + now.Format(time.RFC1123Z) + "\r\n")
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	message.WriteString("\r\n")
+	for _, line := range lines {
+		message.WriteString(strings.ReplaceAll(strings.ReplaceAll(line, "\r\n", "\n"), "\n", "\r\n") + "\r\n")
+	}
+	if omitted > 0 {
+		message.WriteString("(" + strconv.Itoa(omitted) + " records omitted)\r\n")
+	}
+	if suppressed > 0 {
+		message.WriteString("(" + strconv.Itoa(suppressed) + " records suppressed by rate limit)\r\n")
+	}
+	return message.Bytes()
+}
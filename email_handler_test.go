@@ -0,0 +1,91 @@
+package nslog
+
+import (
+	"log/slog"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type emailRecorder struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (recorder *emailRecorder) sendMail(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.messages = append(recorder.messages, string(msg))
+	return nil
+}
+
+func TestEmailHandler(t *testing.T) {
+	recorder := &emailRecorder{}
+	handler, err := NewEmailHandler("smtp.example.com:25", &EmailHandlerOptions{
+		From:       "app@example.com",
+		To:         []string{"ops@example.com", "dev@example.com"},
+		MaxRecords: 2,
+		Format:     &LogHandlerOptions{OmitTime: true, AddColor: true},
+		SendMail:   recorder.sendMail,
+	})
+	assert.NoError(t, err)
+	log := slog.New(handler).With("id", 1)
+	log.Warn("warn message")
+	log.Error("error message 1")
+	log.Error("error message 2")
+	log.Error("error message 3")
+	handler.Close()
+	log.Error("error message 4")
+	handler.Flush()
+
+	assert.Len(t, recorder.messages, 1)
+	header, body, _ := strings.Cut(recorder.messages[0], "\r\n\r\n")
+	assert.Contains(t, header, "From: app@example.com\r\n")
+	assert.Contains(t, header, "To: ops@example.com, dev@example.com\r\n")
+	assert.Contains(t, header, "Subject: nslog alert (3 records)\r\n")
+	assert.Equal(t, "ERROR [id=1]: error message 1 (email_handler_test.go:38)\r\nERROR [id=1]: error message 2 (email_handler_test.go:39)\r\n(1 records omitted)\r\n", body)
+}
+
+func TestEmailHandlerWindow(t *testing.T) {
+	recorder := &emailRecorder{}
+	handler, _ := NewEmailHandler("smtp.example.com:25", &EmailHandlerOptions{From: "app@example.com", To: []string{"ops@example.com"}, Window: 10 * time.Millisecond, SendMail: recorder.sendMail})
+	defer handler.Close()
+	log := slog.New(handler)
+	log.Error("error message 1")
+	log.Error("error message 2")
+
+	assert.Eventually(t, func() bool {
+		recorder.mutex.Lock()
+		defer recorder.mutex.Unlock()
+		return len(recorder.messages) == 1
+	}, time.Second, time.Millisecond)
+	assert.Contains(t, recorder.messages[0], "Subject: nslog alert (2 records)\r\n")
+}
+
+func TestEmailHandlerRateLimit(t *testing.T) {
+	recorder := &emailRecorder{}
+	handler, _ := NewEmailHandler("smtp.example.com:25", &EmailHandlerOptions{From: "app@example.com", To: []string{"ops@example.com"}, RateLimit: 1, RateInterval: 50 * time.Millisecond, SendMail: recorder.sendMail})
+	log := slog.New(handler)
+	log.Error("error message 1")
+	handler.Flush()
+	log.Error("error message 2")
+	handler.Flush()
+	time.Sleep(50 * time.Millisecond)
+	log.Error("error message 3")
+	handler.Close()
+
+	assert.Len(t, recorder.messages, 2)
+	assert.Contains(t, recorder.messages[1], "error message 3")
+	assert.Contains(t, recorder.messages[1], "(1 records suppressed by rate limit)\r\n")
+}
+
+func TestEmailHandlerInvalidAddress(t *testing.T) {
+	_, err := NewEmailHandler("smtp.example.com:25", &EmailHandlerOptions{To: []string{"ops@example.com"}})
+	assert.Error(t, err)
+	_, err = NewEmailHandler("smtp.example.com:25", &EmailHandlerOptions{From: "app@example.com"})
+	assert.Error(t, err)
+}
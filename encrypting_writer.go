@@ -0,0 +1,115 @@
+package nslog
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Maximum length of a frame of encrypted log, which guards against reading corrupted length.
+const maxEncryptedFrameLength = 16 * 1024 * 1024
+
+// A writer to encrypt log lines by AES-GCM, for devices in the field where logs may contain sensitive data
+// and disks are accessible. Each Write is encrypted to a frame of 4-byte big-endian length followed by
+// 12-byte random nonce and the ciphertext, which is decrypted by [nslog.DecryptLog].
+// Writes larger than the maximum length of a frame are split into several frames.
+type EncryptingWriter struct {
+	writer io.Writer
+	mutex  sync.Mutex
+	aead   cipher.AEAD
+}
+
+// Create a new [nslog.EncryptingWriter] object. The key must be 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
+func NewEncryptingWriter(writer io.Writer, key []byte) (*EncryptingWriter, error) {
+	aead, err := newLogAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptingWriter{
+		writer: writer,
+		aead:   aead,
+	}, nil
+}
+
+func newLogAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("nslog: invalid encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func (writer *EncryptingWriter) Write(p []byte) (int, error) {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	// frames of a write are not interleaved with other writes
+	maxPlaintext := maxEncryptedFrameLength - writer.aead.NonceSize() - writer.aead.Overhead()
+	written := 0
+	for {
+		plaintext := p[written:min(len(p), written+maxPlaintext)]
+		err := writer.writeFrame(plaintext)
+		if err != nil {
+			return written, err
+		}
+		written += len(plaintext)
+		if written >= len(p) {
+			return written, nil
+		}
+	}
+}
+
+// Encrypt the plaintext to a frame and write it. The mutex must be locked by the caller.
+func (writer *EncryptingWriter) writeFrame(plaintext []byte) error {
+	frame := make([]byte, 4+writer.aead.NonceSize(), 4+writer.aead.NonceSize()+len(plaintext)+writer.aead.Overhead())
+	nonce := frame[4:]
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return err
+	}
+	frame = writer.aead.Seal(frame, nonce, plaintext, nil)
+	binary.BigEndian.PutUint32(frame, uint32(len(frame)-4))
+	_, err = writer.writer.Write(frame)
+	return err
+}
+
+// Decrypt log encrypted by [nslog.EncryptingWriter] from the reader, and write log lines to the writer.
+func DecryptLog(writer io.Writer, reader io.Reader, key []byte) error {
+	aead, err := newLogAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, 4)
+	for number := 1; ; number++ {
+		_, err := io.ReadFull(reader, header)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("nslog: encrypted frame %d is truncated: %w", number, err)
+		}
+		length := binary.BigEndian.Uint32(header)
+		if length < uint32(aead.NonceSize()+aead.Overhead()) || length > maxEncryptedFrameLength {
+			return fmt.Errorf("nslog: encrypted frame %d has invalid length %d", number, length)
+		}
+		frame := make([]byte, length)
+		_, err = io.ReadFull(reader, frame)
+		if err != nil {
+			return fmt.Errorf("nslog: encrypted frame %d is truncated: %w", number, err)
+		}
+		plaintext, err := aead.Open(nil, frame[:aead.NonceSize()], frame[aead.NonceSize():], nil)
+		if err != nil {
+			return fmt.Errorf("nslog: encrypted frame %d cannot be decrypted: %w", number, err)
+		}
+		_, err = writer.Write(plaintext)
+		if err != nil {
+			return err
+		}
+	}
+}
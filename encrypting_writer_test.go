@@ -0,0 +1,51 @@
+package nslog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptingWriter(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	buf := new(bytes.Buffer)
+	writer, err := NewEncryptingWriter(buf, key)
+	assert.NoError(t, err)
+	log := NewLogger(writer, nil)
+	log.Info("log message1", "password", "secret")
+	log.Info("log message2")
+	assert.NotContains(t, buf.String(), "secret")
+
+	decrypted := new(bytes.Buffer)
+	assert.NoError(t, DecryptLog(decrypted, bytes.NewReader(buf.Bytes()), key))
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. log message1 password=secret\n"+DEFAULT_TIME_REGEXP+" INFO\\. log message2\n$", decrypted.String())
+
+	// wrong key
+	err = DecryptLog(new(bytes.Buffer), bytes.NewReader(buf.Bytes()), bytes.Repeat([]byte{0x43}, 32))
+	assert.ErrorContains(t, err, "nslog: encrypted frame 1 cannot be decrypted")
+
+	// truncated
+	err = DecryptLog(new(bytes.Buffer), bytes.NewReader(buf.Bytes()[:buf.Len()-1]), key)
+	assert.ErrorContains(t, err, "nslog: encrypted frame 2 is truncated")
+}
+
+func TestEncryptingWriterLargeWrite(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	buf := new(bytes.Buffer)
+	writer, err := NewEncryptingWriter(buf, key)
+	assert.NoError(t, err)
+	payload := bytes.Repeat([]byte("0123456789abcdef"), maxEncryptedFrameLength/16+1)
+	n, err := writer.Write(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, len(payload), n)
+
+	decrypted := new(bytes.Buffer)
+	assert.NoError(t, DecryptLog(decrypted, bytes.NewReader(buf.Bytes()), key))
+	assert.True(t, bytes.Equal(payload, decrypted.Bytes()))
+}
+
+func TestEncryptingWriterInvalidKey(t *testing.T) {
+	_, err := NewEncryptingWriter(new(bytes.Buffer), []byte("short"))
+	assert.ErrorContains(t, err, "nslog: invalid encryption key")
+}
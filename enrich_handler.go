@@ -0,0 +1,123 @@
+package nslog
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"runtime"
+	"runtime/metrics"
+	"time"
+)
+
+const DEFAULT_ENRICH_LEVEL = slog.LevelDebug
+
+// A function to compute an attribute added to each record by [nslog.EnrichHandler].
+// The attribute is not added if its key is empty, such as when the value is not available on the platform.
+type EnrichFunc func(ctx context.Context) slog.Attr
+
+// An option to customize [nslog.EnrichHandler].
+type EnrichHandlerOptions struct {
+	Level slog.Leveler // Set lowest level of records to enrich. Records below the level are passed as is. (default: slog.LevelDebug)
+	Attrs []slog.Attr  // Set fixed attributes added to records. (default: nil)
+	Funcs []EnrichFunc // Set functions to compute attributes added to records after Attrs, such as EnrichMemory. (default: nil)
+}
+
+// A handler to add fixed attributes and dynamically computed attributes such as memory usage, number of open files, and uptime
+// to records at or above the level before passing them to the next handler, for lightweight operational telemetry inside logs.
+// The attributes are added to the record, so they are qualified by groups of WithGroup like other attributes of the record.
+type EnrichHandler struct {
+	next    slog.Handler
+	options EnrichHandlerOptions
+}
+
+// Start time of the process used by [nslog.EnrichUptime], which is the time this package is initialized.
+var processStart = time.Now()
+
+// Create a new [nslog.EnrichHandler] object.
+func NewEnrichHandler(next slog.Handler, options *EnrichHandlerOptions) *EnrichHandler {
+	// set default parameters
+	if options == nil {
+		options = &EnrichHandlerOptions{}
+	}
+	if options.Level == nil {
+		options.Level = DEFAULT_ENRICH_LEVEL
+	}
+
+	return &EnrichHandler{
+		next:    next,
+		options: *options,
+	}
+}
+
+// Create a middleware of [nslog.EnrichHandler].
+func Enrich(options *EnrichHandlerOptions) Middleware {
+	return func(next slog.Handler) slog.Handler {
+		return NewEnrichHandler(next, options)
+	}
+}
+
+func (handler *EnrichHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return handler.next.Enabled(ctx, level)
+}
+
+func (handler *EnrichHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &EnrichHandler{
+		next:    handler.next.WithAttrs(attrs),
+		options: handler.options,
+	}
+}
+
+func (handler *EnrichHandler) WithGroup(name string) slog.Handler {
+	return &EnrichHandler{
+		next:    handler.next.WithGroup(name),
+		options: handler.options,
+	}
+}
+
+func (handler *EnrichHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level < handler.options.Level.Level() || (len(handler.options.Attrs) == 0 && len(handler.options.Funcs) == 0) {
+		return handler.next.Handle(ctx, record)
+	}
+
+	// clone not to modify attributes shared with the caller
+	record = record.Clone()
+	record.AddAttrs(handler.options.Attrs...)
+	for _, f := range handler.options.Funcs {
+		if attribute := f(ctx); attribute.Key != "" {
+			record.AddAttrs(attribute)
+		}
+	}
+	return handler.next.Handle(ctx, record)
+}
+
+// Compute bytes of heap memory occupied by live and unswept objects as "heap" attribute, which is read without stopping the world.
+func EnrichMemory(_ context.Context) slog.Attr {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return slog.Attr{}
+	}
+	return slog.Uint64("heap", sample[0].Value.Uint64())
+}
+
+// Compute number of goroutines as "goroutines" attribute.
+func EnrichGoroutines(_ context.Context) slog.Attr {
+	return slog.Int("goroutines", runtime.NumGoroutine())
+}
+
+// Compute number of open file descriptors as "fds" attribute, which is available on Linux and macOS.
+func EnrichOpenFiles(_ context.Context) slog.Attr {
+	for _, dir := range []string{"/proc/self/fd", "/dev/fd"} {
+		entries, err := os.ReadDir(dir)
+		if err == nil {
+			// exclude the descriptor to read the directory
+			return slog.Int("fds", len(entries)-1)
+		}
+	}
+	return slog.Attr{}
+}
+
+// Compute time since start of the process as "uptime" attribute such as "1h2m3s".
+func EnrichUptime(_ context.Context) slog.Attr {
+	return slog.Duration("uptime", time.Since(processStart).Round(time.Second))
+}
@@ -0,0 +1,49 @@
+package nslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnrichHandler(t *testing.T) {
+	buf := new(bytes.Buffer)
+	options := &EnrichHandlerOptions{
+		Level: slog.LevelWarn,
+		Attrs: []slog.Attr{slog.String("region", "us-east-1")},
+		Funcs: []EnrichFunc{
+			func(ctx context.Context) slog.Attr { return slog.Int("dynamic", 42) },
+			func(ctx context.Context) slog.Attr { return slog.Attr{} },
+		},
+	}
+	log := slog.New(NewEnrichHandler(NewLogHandler(buf, &LogHandlerOptions{OmitTime: true, AddSourceLevel: slog.LevelError}), options)).WithGroup("Main").With("key1", "val1")
+	log.Info("info message", "key2", "val2")
+	log.Warn("warn message", "key2", "val2")
+	assert.Equal(t, "INFO. Main[key1=val1]: info message Main.key2=val2\n"+
+		"WARN. Main[key1=val1]: warn message Main.key2=val2 Main.region=us-east-1 Main.dynamic=42\n", buf.String())
+}
+
+func TestEnrichFuncs(t *testing.T) {
+	ctx := context.Background()
+	heap := EnrichMemory(ctx)
+	assert.Equal(t, "heap", heap.Key)
+	assert.Greater(t, heap.Value.Uint64(), uint64(0))
+	assert.Equal(t, "goroutines", EnrichGoroutines(ctx).Key)
+	assert.Equal(t, "uptime", EnrichUptime(ctx).Key)
+	if runtime.GOOS == "linux" {
+		fds := EnrichOpenFiles(ctx)
+		assert.Equal(t, "fds", fds.Key)
+		assert.GreaterOrEqual(t, fds.Value.Int64(), int64(3))
+	}
+}
+
+func TestEnrichChain(t *testing.T) {
+	buf := new(bytes.Buffer)
+	handler := Chain(NewLogHandler(buf, &LogHandlerOptions{OmitTime: true}), Enrich(&EnrichHandlerOptions{Funcs: []EnrichFunc{EnrichGoroutines}}))
+	slog.New(handler).Info("log message")
+	assert.Regexp(t, "^INFO\\. log message goroutines=[0-9]+\n$", buf.String())
+}
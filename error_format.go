@@ -0,0 +1,66 @@
+package nslog
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Get continuation lines of the error attribute by ExpandErrors option.
+// Errors of recovered panic by [nslog.Recover] always have the lines, so the stack is not lost by default.
+func (handler *LogHandler) expandError(attribute slog.Attr) []string {
+	if !handler.options.ExpandErrors {
+		if attribute.Value.Kind() != slog.KindAny {
+			return nil
+		}
+		if _, ok := attribute.Value.Any().(*PanicError); !ok {
+			return nil
+		}
+	}
+	return errorLines(attribute)
+}
+
+// Get continuation lines of the error attribute, which show the chain of wrapped errors.
+// If "%+v" of the error is different from its message (e.g. stack trace of pkg/errors), it is shown instead of the chain.
+func errorLines(attribute slog.Attr) []string {
+	if attribute.Value.Kind() != slog.KindAny {
+		return nil
+	}
+	err, ok := attribute.Value.Any().(error)
+	if !ok || err == nil {
+		return nil
+	}
+
+	var details []string
+	if verbose := fmt.Sprintf("%+v", err); verbose != err.Error() {
+		details = strings.Split(strings.TrimRight(verbose, "\n"), "\n")
+	} else {
+		details = errorChain(err)
+	}
+
+	lines := make([]string, 0, len(details))
+	for _, detail := range details {
+		lines = append(lines, "\t"+attribute.Key+": "+detail)
+	}
+	return lines
+}
+
+// Get messages of errors wrapped by the error.
+func errorChain(err error) []string {
+	var wrapped []error
+	switch err := err.(type) {
+	case interface{ Unwrap() error }:
+		if e := err.Unwrap(); e != nil {
+			wrapped = append(wrapped, e)
+		}
+	case interface{ Unwrap() []error }:
+		wrapped = err.Unwrap()
+	}
+
+	var chain []string
+	for _, e := range wrapped {
+		chain = append(chain, "caused by: "+e.Error())
+		chain = append(chain, errorChain(e)...)
+	}
+	return chain
+}
@@ -0,0 +1,58 @@
+package nslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stackError struct{}
+
+func (stackError) Error() string { return "stack error" }
+
+func (err stackError) Format(s fmt.State, verb rune) {
+	if s.Flag('+') {
+		fmt.Fprint(s, "stack error\nmain.main\n\tmain.go:10\n")
+		return
+	}
+	fmt.Fprint(s, err.Error())
+}
+
+func TestExpandErrors(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{ExpandErrors: true})
+	err := fmt.Errorf("read config: %w", fmt.Errorf("open: %w", errors.New("not found")))
+	log.Info("log message", "err", err, "key1", "val1")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. log message err=read config: open: not found key1=val1\n"+
+		"\terr: caused by: open: not found\n"+
+		"\terr: caused by: not found\n$", buf.String())
+}
+
+func TestExpandErrorsJoin(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{ExpandErrors: true})
+	log.Info("log message", "err", fmt.Errorf("failed: %w, %w", errors.New("error1"), errors.New("error2")))
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. log message err=failed: error1, error2\n"+
+		"\terr: caused by: error1\n"+
+		"\terr: caused by: error2\n$", buf.String())
+}
+
+func TestExpandErrorsVerbose(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{ExpandErrors: true})
+	log.Info("log message", "err", stackError{})
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. log message err=stack error\n"+
+		"\terr: stack error\n"+
+		"\terr: main\\.main\n"+
+		"\terr: \tmain\\.go:10\n$", buf.String())
+}
+
+func TestExpandErrorsDisabled(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, nil)
+	log.Info("log message", "err", fmt.Errorf("read config: %w", errors.New("not found")))
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. log message err=read config: not found\n$", buf.String())
+}
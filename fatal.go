@@ -0,0 +1,106 @@
+package nslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Level of records output by [nslog.Fatal] and [nslog.Panic], which is labeled as "FATAL".
+const LevelFatal = slog.LevelError + 4
+
+const DEFAULT_EXIT_TIMEOUT = 5 * time.Second
+
+// Function to exit the process called by [nslog.Fatal] with code 1, which can be replaced in tests. (default: os.Exit)
+var ExitFunc = os.Exit
+
+// Hooks called by [nslog.Fatal] and [nslog.Panic] in reverse order of registration.
+var exitHooks struct {
+	mutex sync.Mutex
+	hooks []func()
+}
+
+// Register a hook called by [nslog.Fatal] and [nslog.Panic] before exit and panic, such as to flush sinks.
+// Hooks are called in reverse order of registration like defer, and they are called on every call of Fatal and Panic.
+func RegisterExitHook(hook func()) {
+	exitHooks.mutex.Lock()
+	defer exitHooks.mutex.Unlock()
+	exitHooks.hooks = append(exitHooks.hooks, hook)
+}
+
+// Register a hook to shut down the components by [nslog.Shutdown] within DEFAULT_EXIT_TIMEOUT before exit and panic.
+// Errors of the shutdown are written to os.Stderr.
+func RegisterExitShutdown(components ...any) {
+	RegisterExitHook(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_EXIT_TIMEOUT)
+		defer cancel()
+		if err := Shutdown(ctx, components...); err != nil {
+			fmt.Fprintf(os.Stderr, "nslog: failed to shut down on exit: %v\n", err)
+		}
+	})
+}
+
+// Call the hooks registered by [nslog.RegisterExitHook] in reverse order.
+func runExitHooks() {
+	exitHooks.mutex.Lock()
+	hooks := append([]func(){}, exitHooks.hooks...)
+	exitHooks.mutex.Unlock()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i]()
+	}
+}
+
+// Output a record at LevelFatal with the logger (slog.Default if it is nil), write the crash file enabled by [nslog.SetCrashDump],
+// call the exit hooks, and exit the process by ExitFunc with code 1.
+func Fatal(logger *slog.Logger, msg string, args ...any) {
+	logFatal(logger, msg, args)
+	writeCrashDump(1)
+	runExitHooks()
+	ExitFunc(1)
+}
+
+// Output a record at LevelFatal with the message formatted by [fmt.Sprintf], write the crash file enabled by [nslog.SetCrashDump],
+// call the exit hooks, and exit the process by ExitFunc with code 1.
+func Fatalf(logger *slog.Logger, format string, args ...any) {
+	logFatal(logger, fmt.Sprintf(format, args...), nil)
+	writeCrashDump(1)
+	runExitHooks()
+	ExitFunc(1)
+}
+
+// Output a record at LevelFatal with the logger (slog.Default if it is nil), call the exit hooks, and panic with the message.
+func Panic(logger *slog.Logger, msg string, args ...any) {
+	logFatal(logger, msg, args)
+	runExitHooks()
+	panic(msg)
+}
+
+// Output a record at LevelFatal with the message formatted by [fmt.Sprintf], call the exit hooks, and panic with the message.
+func Panicf(logger *slog.Logger, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	logFatal(logger, msg, nil)
+	runExitHooks()
+	panic(msg)
+}
+
+// Output a record at LevelFatal, where the source is the caller of the exported function.
+func logFatal(logger *slog.Logger, msg string, args []any) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	ctx := context.Background()
+	if !logger.Enabled(ctx, LevelFatal) {
+		return
+	}
+
+	// skip [runtime.Callers], this function, and the exported function
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	record := slog.NewRecord(time.Now(), LevelFatal, msg, pcs[0])
+	record.Add(args...)
+	_ = logger.Handler().Handle(ctx, record)
+}
@@ -0,0 +1,82 @@
+package nslog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setExitFunc(t *testing.T, exit func(code int)) {
+	exitFunc := ExitFunc
+	ExitFunc = exit
+	t.Cleanup(func() { ExitFunc = exitFunc })
+}
+
+func resetExitHooks(t *testing.T) {
+	t.Cleanup(func() {
+		exitHooks.mutex.Lock()
+		exitHooks.hooks = nil
+		exitHooks.mutex.Unlock()
+	})
+}
+
+func TestFatal(t *testing.T) {
+	resetExitHooks(t)
+	var calls []string
+	setExitFunc(t, func(code int) { calls = append(calls, "exit") })
+	RegisterExitHook(func() { calls = append(calls, "hook1") })
+	RegisterExitHook(func() { calls = append(calls, "hook2") })
+
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true})
+	Fatal(log, "fatal message", "key1", "val1")
+	assert.Regexp(t, "^FATAL fatal message key1=val1 \\(fatal_test\\.go:[0-9]+\\)\n$", buf.String())
+	assert.Equal(t, []string{"hook2", "hook1", "exit"}, calls)
+
+	buf.Reset()
+	var code int
+	setExitFunc(t, func(c int) { code = c })
+	Fatalf(log, "fatal %d", 42)
+	assert.Regexp(t, "^FATAL fatal 42 \\(fatal_test\\.go:[0-9]+\\)\n$", buf.String())
+	assert.Equal(t, 1, code)
+
+	buf.Reset()
+	log = NewLogger(buf, &LogHandlerOptions{OmitTime: true, Format: FormatLTSV})
+	Fatal(log, "fatal message")
+	assert.Regexp(t, "^level:FATAL\tmsg:fatal message\tsource:fatal_test\\.go:[0-9]+\n$", buf.String())
+}
+
+func TestPanic(t *testing.T) {
+	resetExitHooks(t)
+	buf := new(bytes.Buffer)
+	flusher := &testFlusher{}
+	RegisterExitShutdown(flusher)
+
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true, LevelStyle: LevelStyleBracketed})
+	assert.PanicsWithValue(t, "panic message", func() { Panic(log, "panic message", "key1", "val1") })
+	assert.Regexp(t, "^\\[FATAL\\] panic message key1=val1 \\(fatal_test\\.go:[0-9]+\\)\n$", buf.String())
+	assert.Equal(t, 1, flusher.flushed)
+	assert.PanicsWithValue(t, "panic 42", func() { Panicf(nil, "panic %d", 42) })
+	assert.Equal(t, 2, flusher.flushed)
+}
+
+func TestParseLevelFatal(t *testing.T) {
+	level, err := ParseLevel("fatal")
+	assert.NoError(t, err)
+	assert.Equal(t, LevelFatal, level)
+	level, err = ParseLevel("FATAL+2")
+	assert.NoError(t, err)
+	assert.Equal(t, LevelFatal+2, level)
+	assert.Equal(t, slog.Level(12), LevelFatal)
+}
+
+type testFlusher struct {
+	flushed int
+}
+
+func (flusher *testFlusher) Flush() error {
+	flusher.flushed++
+	return nil
+}
@@ -0,0 +1,169 @@
+package nslog
+
+import (
+	"container/list"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const DEFAULT_MAX_OPEN_FILES = 64
+
+// An option to customize [nslog.FileCache].
+type FileCacheOptions struct {
+	MaxOpenFiles int                // Set maximum number of open files. The least recently used file is closed if exceeded. (default: 64)
+	IdleTimeout  time.Duration      // Set duration to close files which are not written. Files are not closed by idle if it is 0. (default: 0)
+	File         *FileWriterOptions // Set options to open files. (default: nil)
+}
+
+// A bounded cache of [nslog.FileWriter] for routing logs to many files, such as per tenant.
+// Files are opened on demand and closed when they are least recently used or idle, and reopened on the next write.
+type FileCache struct {
+	options FileCacheOptions
+	mutex   sync.Mutex
+	files   map[string]*list.Element
+	lru     *list.List // front is the most recently used
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+type fileCacheEntry struct {
+	writer   *FileWriter
+	lastUsed time.Time
+}
+
+// Create a new [nslog.FileCache] object. Call [FileCache.Close] to close all files.
+func NewFileCache(options *FileCacheOptions) *FileCache {
+	// set default parameters
+	if options == nil {
+		options = &FileCacheOptions{}
+	}
+	if options.MaxOpenFiles <= 0 {
+		options.MaxOpenFiles = DEFAULT_MAX_OPEN_FILES
+	}
+
+	cache := &FileCache{
+		options: *options,
+		files:   map[string]*list.Element{},
+		lru:     list.New(),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if options.IdleTimeout > 0 {
+		go cache.closeIdleFiles()
+	} else {
+		close(cache.done)
+	}
+	return cache
+}
+
+// Get a writer to write to the file of the path via the cache.
+func (cache *FileCache) Writer(path string) io.Writer {
+	return &fileCacheWriter{cache: cache, path: path}
+}
+
+// Write to the file of the path, which is opened if it is not open.
+func (cache *FileCache) Write(path string, p []byte) (int, error) {
+	for {
+		writer, err := cache.get(path)
+		if err != nil {
+			return 0, err
+		}
+		n, err := writer.Write(p)
+		if errors.Is(err, os.ErrClosed) {
+			// closed by other goroutine after get, so reopen
+			continue
+		}
+		return n, err
+	}
+}
+
+// Get number of open files.
+func (cache *FileCache) Len() int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	return cache.lru.Len()
+}
+
+// Close all files. Files are reopened if they are written after Close.
+func (cache *FileCache) Close() error {
+	select {
+	case <-cache.stop:
+	default:
+		close(cache.stop)
+	}
+	<-cache.done
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	var errs []error
+	for cache.lru.Len() > 0 {
+		errs = append(errs, cache.remove(cache.lru.Back()))
+	}
+	return errors.Join(errs...)
+}
+
+func (cache *FileCache) get(path string) (*FileWriter, error) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	if element, ok := cache.files[path]; ok {
+		entry := element.Value.(*fileCacheEntry)
+		entry.lastUsed = time.Now()
+		cache.lru.MoveToFront(element)
+		return entry.writer, nil
+	}
+
+	for cache.lru.Len() >= cache.options.MaxOpenFiles {
+		_ = cache.remove(cache.lru.Back())
+	}
+	var fileOptions FileWriterOptions
+	if cache.options.File != nil {
+		fileOptions = *cache.options.File
+	}
+	writer, err := NewFileWriter(path, &fileOptions)
+	if err != nil {
+		return nil, err
+	}
+	cache.files[path] = cache.lru.PushFront(&fileCacheEntry{writer: writer, lastUsed: time.Now()})
+	return writer, nil
+}
+
+// Remove the element from the cache and close the file. The mutex must be locked.
+func (cache *FileCache) remove(element *list.Element) error {
+	entry := cache.lru.Remove(element).(*fileCacheEntry)
+	delete(cache.files, entry.writer.Path())
+	return entry.writer.Close()
+}
+
+func (cache *FileCache) closeIdleFiles() {
+	defer close(cache.done)
+	ticker := time.NewTicker(cache.options.IdleTimeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-cache.stop:
+			return
+		case now := <-ticker.C:
+			cache.mutex.Lock()
+			for element := cache.lru.Back(); element != nil; element = cache.lru.Back() {
+				if now.Sub(element.Value.(*fileCacheEntry).lastUsed) < cache.options.IdleTimeout {
+					break
+				}
+				_ = cache.remove(element)
+			}
+			cache.mutex.Unlock()
+		}
+	}
+}
+
+type fileCacheWriter struct {
+	cache *FileCache
+	path  string
+}
+
+func (writer *fileCacheWriter) Write(p []byte) (int, error) {
+	return writer.cache.Write(writer.path, p)
+}
@@ -0,0 +1,42 @@
+package nslog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileCache(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewFileCache(&FileCacheOptions{MaxOpenFiles: 2})
+	defer cache.Close()
+
+	for _, tenant := range []string{"a", "b", "c", "a"} {
+		log := NewLogger(cache.Writer(filepath.Join(dir, tenant+".log")), nil)
+		log.Info("message", "tenant", tenant)
+		assert.LessOrEqual(t, cache.Len(), 2)
+	}
+	assert.NoError(t, cache.Close())
+	assert.Equal(t, 0, cache.Len())
+
+	data, err := os.ReadFile(filepath.Join(dir, "a.log"))
+	assert.NoError(t, err)
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. message tenant=a\n"+DEFAULT_TIME_REGEXP+" INFO\\. message tenant=a\n$", string(data))
+	data, err = os.ReadFile(filepath.Join(dir, "c.log"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "INFO. message tenant=c")
+}
+
+func TestFileCacheIdleTimeout(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewFileCache(&FileCacheOptions{IdleTimeout: 20 * time.Millisecond})
+	defer cache.Close()
+
+	_, err := cache.Write(filepath.Join(dir, "a.log"), []byte("message\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, cache.Len())
+	assert.Eventually(t, func() bool { return cache.Len() == 0 }, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,145 @@
+package nslog
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Prefix of lines of the file header, which are comments for tools reading log files.
+const FILE_HEADER_PREFIX = "# "
+
+// Version of the layout of log lines, which is increased when the layout changes incompatibly such as quoting and groups.
+// It is written in the file header, so [nslog.Scanner] can parse files written by older versions.
+const FORMAT_VERSION = 1
+
+// Get a header describing the column layout, the format with the version of nslog, and metadata of the process,
+// which is written on new files by Header option of [nslog.FileWriter] such as:
+//
+//	# nslog format: text (nslog v1.2.3)
+//	# format version: 1
+//	# columns: time level [attrs]groups: message key=value... (source)
+//	# time layout: 2006/01/02 15:04:05 (local)
+//	# process: pid=12345 exe=/usr/local/bin/app go=go1.21.0 host=web-1 revision=0123abc
+//	# created: 2024-10-31T11:22:33+09:00
+func (handler *LogHandler) FileHeader() string {
+	options := &handler.options
+
+	version := "unknown"
+	var revision string
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if info.Main.Path == "github.com/mikiepure/nslog" {
+			version = info.Main.Version
+		}
+		for _, dep := range info.Deps {
+			if dep.Path == "github.com/mikiepure/nslog" {
+				version = dep.Version
+			}
+		}
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				revision = setting.Value
+			}
+		}
+	}
+
+	zone := "local"
+	if options.UseUTC {
+		zone = "UTC"
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		executable = "unknown"
+	}
+	process := []string{"pid=" + strconv.Itoa(os.Getpid()), "exe=" + executable, "go=" + runtime.Version()}
+	if hostname, err := os.Hostname(); err == nil {
+		process = append(process, "host="+hostname)
+	}
+	if options.ServiceName != "" {
+		process = append(process, "service="+options.ServiceName)
+	}
+	if options.ServiceVersion != "" {
+		process = append(process, "version="+options.ServiceVersion)
+	}
+	if revision != "" {
+		process = append(process, "revision="+revision)
+	}
+
+	lines := []string{
+		"nslog format: " + options.Format.String() + " (nslog " + version + ")",
+		"format version: " + strconv.Itoa(FORMAT_VERSION),
+		"columns: " + handler.columns(),
+		"time layout: " + options.TimeLayout + " (" + zone + ")",
+		"process: " + strings.Join(process, " "),
+		"created: " + time.Now().Format(time.RFC3339),
+	}
+	var header string
+	for _, line := range lines {
+		header += FILE_HEADER_PREFIX + line + options.LineEnding
+	}
+	return header
+}
+
+// Get the column layout of log lines by the options.
+func (handler *LogHandler) columns() string {
+	options := &handler.options
+	var columns []string
+	switch options.Format {
+	case FormatLTSV:
+		for _, column := range []struct {
+			enabled bool
+			label   string
+		}{
+			{options.AddSequence, "seq"},
+			{!options.OmitTime, "time"},
+			{options.AddElapsed, "elapsed"},
+			{options.AddDelta, "delta"},
+			{options.AddPID, "pid"},
+			{options.AddGoroutineID, "goroutine"},
+			{true, "level"},
+			{true, "msg"},
+			{true, "key..."},
+			{options.TraceFormat != TraceFormatNone, "trace_id"},
+			{options.TraceFormat != TraceFormatNone, "span_id"},
+			{true, "source"},
+		} {
+			if column.enabled {
+				columns = append(columns, column.label)
+			}
+		}
+		return strings.Join(columns, "\t")
+	case FormatGlog:
+		return "Lmmdd hh:mm:ss.uuuuuu pid file:line] message key=value..."
+	case FormatCompact:
+		if !options.OmitTime {
+			columns = append(columns, "time")
+		}
+		return strings.Join(append(columns, "level", "message", "key=value...", "(source)"), " ")
+	}
+
+	for _, column := range []struct {
+		enabled bool
+		name    string
+	}{
+		{options.AddSequence, "#seq"},
+		{!options.OmitTime, "time"},
+		{options.AddElapsed, "+elapsed"},
+		{options.AddDelta, "+delta"},
+		{options.AddPID, "pid"},
+		{options.AddGoroutineID, "goroutine"},
+		{true, "level"},
+		{true, options.AttrsOpen + "attrs" + options.AttrsClose + "groups:"},
+		{true, "message"},
+		{true, "key" + options.KeyValueSeparator + "value..."},
+		{options.TraceFormat == TraceFormatSuffix, "[trace_id/span_id]"},
+		{true, "(source)"},
+	} {
+		if column.enabled {
+			columns = append(columns, column.name)
+		}
+	}
+	return strings.Join(columns, options.FieldSeparator)
+}
@@ -0,0 +1,66 @@
+package nslog
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	var handler *LogHandler
+	writer, err := NewFileWriter(path, &FileWriterOptions{MaxSize: 1024, Header: func() string { return handler.FileHeader() }})
+	assert.NoError(t, err)
+	handler = NewLogHandler(writer, &LogHandlerOptions{AddPID: true, ServiceName: "app"})
+	slog.New(handler).Info("message1")
+	assert.NoError(t, writer.Close())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Regexp(t, "^# nslog format: text \\(nslog .+\\)\n"+
+		"# format version: 1\n"+
+		"# columns: time pid level \\[attrs\\]groups: message key=value\\.\\.\\. \\(source\\)\n"+
+		"# time layout: 2006/01/02 15:04:05 \\(local\\)\n"+
+		"# process: pid="+strconv.Itoa(os.Getpid())+" exe=.+ go=go.+ service=app.*\n"+
+		"# created: .+\n"+
+		DEFAULT_TIME_REGEXP+" [0-9A-F]{4} INFO\\. message1 service=app\n$", string(data))
+
+	// header is not written on existing file
+	writer, err = NewFileWriter(path, &FileWriterOptions{Header: func() string { return "# header\n" }})
+	assert.NoError(t, err)
+	_, err = writer.Write([]byte("message2\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+	data, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Regexp(t, "INFO\\. message1 service=app\nmessage2\n$", string(data))
+}
+
+func TestFileHeaderRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	writer, err := NewFileWriter(path, &FileWriterOptions{MaxSize: 20, Header: func() string { return "# header\n" }})
+	assert.NoError(t, err)
+	_, _ = writer.Write([]byte("message1\n"))
+	_, _ = writer.Write([]byte("message2\n"))
+	assert.NoError(t, writer.Close())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "# header\nmessage2\n", string(data))
+	archives, _ := filepath.Glob(path + ".*")
+	assert.Len(t, archives, 1)
+	data, err = os.ReadFile(archives[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "# header\nmessage1\n", string(data))
+}
+
+func TestFileHeaderColumns(t *testing.T) {
+	handler := NewLogHandler(nil, &LogHandlerOptions{Format: FormatLTSV, OmitTime: true, AddSequence: true})
+	assert.Equal(t, "seq\tlevel\tmsg\tkey...\ttrace_id\tspan_id\tsource", handler.columns())
+	handler = NewLogHandler(nil, &LogHandlerOptions{Format: FormatCompact})
+	assert.Equal(t, "time level message key=value... (source)", handler.columns())
+}
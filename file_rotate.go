@@ -0,0 +1,117 @@
+package nslog
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layout of timestamp of archives, which does not contain characters invalid for file names on Windows.
+const archiveTimeLayout = "2006-01-02T15-04-05.000"
+
+type fileArchive struct {
+	path    string
+	time    time.Time
+	index   int
+	size    int64
+	modTime time.Time
+}
+
+// Rename the file to an archive, open a new file, and delete archives by retention policy.
+// The mutex must be locked by the caller.
+func (writer *FileWriter) rotate() error {
+	err := writer.file.Close()
+	if err != nil {
+		return err
+	}
+	writer.file = nil
+
+	renameErr := os.Rename(writer.path, archivePath(writer.path, time.Now()))
+	err = writer.open()
+	if err != nil {
+		return err
+	}
+	if renameErr != nil {
+		// keep appending to the current file, and retry rotation on the next write
+		return nil
+	}
+	writer.removeArchives()
+	return nil
+}
+
+// Get path of a new archive, which has suffix such as ".1" and ".2" if the archive of the same time exists,
+// so an archive is not overwritten by rotations in the same millisecond.
+func archivePath(path string, now time.Time) string {
+	archive := path + "." + now.Format(archiveTimeLayout)
+	candidate := archive
+	for index := 1; ; index++ {
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+		candidate = archive + "." + strconv.Itoa(index)
+	}
+}
+
+// Get archives of the file sorted from the newest.
+func (writer *FileWriter) archives() []fileArchive {
+	matches, _ := filepath.Glob(escapeGlob(writer.path) + ".*")
+	var archives []fileArchive
+	for _, match := range matches {
+		timestamp := strings.TrimPrefix(match, writer.path+".")
+		var index int
+		if len(timestamp) > len(archiveTimeLayout) {
+			suffix, ok := strings.CutPrefix(timestamp[len(archiveTimeLayout):], ".")
+			if !ok {
+				continue
+			}
+			var err error
+			index, err = strconv.Atoi(suffix)
+			if err != nil {
+				continue
+			}
+			timestamp = timestamp[:len(archiveTimeLayout)]
+		}
+		archiveTime, err := time.ParseInLocation(archiveTimeLayout, timestamp, time.Local)
+		if err != nil {
+			continue
+		}
+		info, err := os.Stat(match)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		archives = append(archives, fileArchive{path: match, time: archiveTime, index: index, size: info.Size(), modTime: info.ModTime()})
+	}
+	slices.SortFunc(archives, func(a, b fileArchive) int {
+		if c := b.time.Compare(a.time); c != 0 {
+			return c
+		}
+		return b.index - a.index
+	})
+	return archives
+}
+
+// Delete archives older than MaxAge option, and the oldest archives exceeding MaxTotalSize option.
+// Errors are ignored because the archives are retried on the next rotation.
+func (writer *FileWriter) removeArchives() {
+	if writer.options.MaxTotalSize <= 0 && writer.options.MaxAge <= 0 {
+		return
+	}
+	var total int64
+	for _, archive := range writer.archives() {
+		total += archive.size
+		expired := writer.options.MaxAge > 0 && time.Since(archive.modTime) > writer.options.MaxAge
+		exceeded := writer.options.MaxTotalSize > 0 && total > writer.options.MaxTotalSize
+		if expired || exceeded {
+			_ = os.Remove(archive.path)
+		}
+	}
+}
+
+// Escape meta characters of [filepath.Match] in the path.
+func escapeGlob(path string) string {
+	replacer := strings.NewReplacer("*", "[*]", "?", "[?]", "[", "[[]")
+	return replacer.Replace(path)
+}
@@ -0,0 +1,96 @@
+package nslog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileWriterMaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	writer, err := NewFileWriter(path, &FileWriterOptions{MaxSize: 10})
+	assert.NoError(t, err)
+	defer writer.Close()
+
+	_, err = writer.Write([]byte("line1\n"))
+	assert.NoError(t, err)
+	_, err = writer.Write([]byte("line2\n"))
+	assert.NoError(t, err)
+
+	archives := writer.archives()
+	assert.Len(t, archives, 1)
+	data, err := os.ReadFile(archives[0].path)
+	assert.NoError(t, err)
+	assert.Equal(t, "line1\n", string(data))
+	data, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "line2\n", string(data))
+}
+
+func TestFileWriterRotateInSameMillisecond(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	writer, err := NewFileWriter(path, &FileWriterOptions{MaxSize: 1})
+	assert.NoError(t, err)
+	defer writer.Close()
+
+	// the archive of the same time exists
+	now := time.Now()
+	assert.NoError(t, os.WriteFile(path+"."+now.Format(archiveTimeLayout), []byte("archive\n"), 0o644))
+	assert.Equal(t, path+"."+now.Format(archiveTimeLayout)+".1", archivePath(path, now))
+
+	for _, line := range []string{"line1\n", "line2\n", "line3\n", "line4\n"} {
+		_, err = writer.Write([]byte(line))
+		assert.NoError(t, err)
+	}
+
+	// all lines are kept even if rotated twice in quick succession
+	var lines []string
+	for _, archive := range writer.archives() {
+		data, err := os.ReadFile(archive.path)
+		assert.NoError(t, err)
+		lines = append(lines, string(data))
+	}
+	assert.Equal(t, []string{"line3\n", "line2\n", "line1\n", "archive\n"}, lines)
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "line4\n", string(data))
+}
+
+func TestFileWriterMaxTotalSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	for i, content := range []string{"archive1\n", "archive2\n", "archive3\n"} {
+		archive := path + "." + time.Now().Add(time.Duration(i-3)*time.Hour).Format(archiveTimeLayout)
+		assert.NoError(t, os.WriteFile(archive, []byte(content), 0o644))
+	}
+	assert.NoError(t, os.WriteFile(path+".backup", []byte("not archive\n"), 0o644))
+
+	writer, err := NewFileWriter(path, &FileWriterOptions{MaxTotalSize: 20})
+	assert.NoError(t, err)
+	defer writer.Close()
+
+	archives := writer.archives()
+	assert.Len(t, archives, 2)
+	data, err := os.ReadFile(archives[1].path)
+	assert.NoError(t, err)
+	assert.Equal(t, "archive2\n", string(data))
+	assert.FileExists(t, path+".backup")
+}
+
+func TestFileWriterMaxAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	old := path + "." + time.Now().Add(-48*time.Hour).Format(archiveTimeLayout)
+	assert.NoError(t, os.WriteFile(old, []byte("old\n"), 0o644))
+	assert.NoError(t, os.Chtimes(old, time.Now().Add(-48*time.Hour), time.Now().Add(-48*time.Hour)))
+	recent := path + "." + time.Now().Add(-time.Hour).Format(archiveTimeLayout)
+	assert.NoError(t, os.WriteFile(recent, []byte("recent\n"), 0o644))
+
+	writer, err := NewFileWriter(path, &FileWriterOptions{MaxAge: 24 * time.Hour})
+	assert.NoError(t, err)
+	defer writer.Close()
+
+	assert.NoFileExists(t, old)
+	assert.FileExists(t, recent)
+}
@@ -0,0 +1,8 @@
+//go:build !unix && !windows
+
+package nslog
+
+import "os"
+
+// SIGHUP and SIGUSR1 are not defined on this platform such as js and plan9, so the file is reopened only by Reopen.
+var reopenSignals []os.Signal
@@ -0,0 +1,10 @@
+//go:build unix
+
+package nslog
+
+import (
+	"os"
+	"syscall"
+)
+
+var reopenSignals = []os.Signal{syscall.SIGHUP, syscall.SIGUSR1}
@@ -0,0 +1,223 @@
+package nslog
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+const DEFAULT_FILE_PERM = 0o644
+
+// An option to customize [nslog.FileWriter].
+type FileWriterOptions struct {
+	Perm os.FileMode // Set permission of the file on creation. (default: 0644)
+
+	// Convert LF to CRLF if it is true, which is expected by some tools on Windows. (default: false)
+	//
+	// Deprecated: Set LineEnding option of [nslog.LogHandlerOptions] to "\r\n" instead,
+	// which also applies to the header by [LogHandler.FileHeader] and to writers other than files.
+	CRLF bool
+
+	// Rotate the file when its size exceeds the bytes, where the file is renamed to an archive with timestamp
+	// such as "app.log.2006-01-02T15-04-05.000". (default: 0, which means no rotation)
+	MaxSize int64
+
+	// Delete the oldest archives when total size of archives exceeds the bytes,
+	// so long-running appliances never fill the disk with logs. (default: 0, which means no limit)
+	MaxTotalSize int64
+
+	// Delete archives older than the duration. (default: 0, which means no limit)
+	MaxAge time.Duration
+
+	// Set function to make a header written before the first line of a new file, which is created, rotated, or reopened,
+	// such as [LogHandler.FileHeader] describing the column layout, so archived files are self-describing.
+	// It is called on the first write to the empty file, so the function may refer to the handler created after the writer. (default: nil)
+	Header func() string
+}
+
+// An option to customize [FileWriter.ReopenOnSignal].
+type ReopenOptions struct {
+	Signals []os.Signal // Set signals to reopen the file. (default: SIGHUP and SIGUSR1, SIGHUP on Windows, or none on other platforms such as js and plan9)
+	OnError func(error) // Set function called when reopening the file is failed. (default: nil)
+}
+
+// A writer to append log lines to a file.
+// On Windows, the file is opened with share modes so that external tools can read, rename, and delete it while logging,
+// and long paths (260 characters or more) are supported.
+type FileWriter struct {
+	path    string
+	options FileWriterOptions
+	mutex   sync.Mutex
+	file    *os.File
+	size    int64 // size of the file for MaxSize option
+	header  bool  // whether the header is to be written on the next write
+}
+
+// Create a new [nslog.FileWriter] object, which opens the file in append mode. The file is created if it does not exist.
+func NewFileWriter(path string, options *FileWriterOptions) (*FileWriter, error) {
+	// set default parameters
+	if options == nil {
+		options = &FileWriterOptions{}
+	}
+	if options.Perm == 0 {
+		options.Perm = DEFAULT_FILE_PERM
+	}
+
+	writer := &FileWriter{
+		path:    path,
+		options: *options,
+	}
+	err := writer.open()
+	if err != nil {
+		return nil, err
+	}
+	writer.removeArchives()
+	return writer, nil
+}
+
+// Open the file and get its size. The mutex must be locked by the caller if the writer is shared.
+func (writer *FileWriter) open() error {
+	file, err := openFile(writer.path, writer.options.Perm)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	writer.file = file
+	writer.size = info.Size()
+	writer.header = writer.options.Header != nil && writer.size == 0
+	return nil
+}
+
+// Get path of the file.
+func (writer *FileWriter) Path() string {
+	return writer.path
+}
+
+func (writer *FileWriter) Write(p []byte) (int, error) {
+	data := p
+	if writer.options.CRLF {
+		data = toCRLF(p)
+	}
+
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	if writer.file == nil {
+		return 0, os.ErrClosed
+	}
+	if writer.options.MaxSize > 0 && writer.size > 0 && writer.size+int64(len(data)) > writer.options.MaxSize {
+		err := writer.rotate()
+		if err != nil {
+			return 0, err
+		}
+	}
+	if writer.header {
+		writer.header = false
+		header := []byte(writer.options.Header())
+		if writer.options.CRLF {
+			header = toCRLF(header)
+		}
+		n, err := writer.file.Write(header)
+		writer.size += int64(n)
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := writer.file.Write(data)
+	writer.size += int64(n)
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Commit the written lines to stable storage.
+func (writer *FileWriter) Sync() error {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	if writer.file == nil {
+		return os.ErrClosed
+	}
+	return writer.file.Sync()
+}
+
+// Close the file and open the path again, so lines are written to a new file after the file is renamed by external tools
+// such as logrotate. Lines are written to the renamed file until it is reopened.
+func (writer *FileWriter) Reopen() error {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	if writer.file == nil {
+		return os.ErrClosed
+	}
+	old := writer.file
+	err := writer.open()
+	if err != nil {
+		return err
+	}
+	return old.Close()
+}
+
+// Reopen the file when the signal is received until the context is done,
+// so classic logrotate with "postrotate kill -HUP" works without copytruncate.
+func (writer *FileWriter) ReopenOnSignal(ctx context.Context, options *ReopenOptions) {
+	// set default parameters
+	if options == nil {
+		options = &ReopenOptions{}
+	}
+	if len(options.Signals) == 0 {
+		options.Signals = reopenSignals
+	}
+	if len(options.Signals) == 0 {
+		// signal.Notify relays all signals if no signal is given
+		return
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, options.Signals...)
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+				err := writer.Reopen()
+				if err != nil && options.OnError != nil {
+					options.OnError(err)
+				}
+			}
+		}
+	}()
+}
+
+func (writer *FileWriter) Close() error {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	if writer.file == nil {
+		return nil
+	}
+	err := writer.file.Close()
+	writer.file = nil
+	return err
+}
+
+// Convert LF to CRLF except for LF which is already preceded by CR.
+func toCRLF(p []byte) []byte {
+	if bytes.IndexByte(p, '\n') < 0 {
+		return p
+	}
+	converted := make([]byte, 0, len(p)+bytes.Count(p, []byte("\n")))
+	for i, c := range p {
+		if c == '\n' && (i == 0 || p[i-1] != '\r') {
+			converted = append(converted, '\r')
+		}
+		converted = append(converted, c)
+	}
+	return converted
+}
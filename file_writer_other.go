@@ -0,0 +1,9 @@
+//go:build !windows
+
+package nslog
+
+import "os"
+
+func openFile(path string, perm os.FileMode) (*os.File, error) {
+	return os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, perm)
+}
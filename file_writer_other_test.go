@@ -0,0 +1,31 @@
+//go:build !windows
+
+package nslog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileWriterReopenOnSignal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	writer, err := NewFileWriter(path, nil)
+	assert.NoError(t, err)
+	defer writer.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	writer.ReopenOnSignal(ctx, &ReopenOptions{Signals: []os.Signal{syscall.SIGUSR1}})
+
+	assert.NoError(t, os.Rename(path, path+".1"))
+	assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+}
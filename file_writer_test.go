@@ -0,0 +1,68 @@
+package nslog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	writer, err := NewFileWriter(path, nil)
+	assert.NoError(t, err)
+	log := NewLogger(writer, nil)
+	log.Info("message1")
+	assert.NoError(t, writer.Sync())
+	assert.NoError(t, writer.Close())
+
+	// append to existing file
+	writer, err = NewFileWriter(path, nil)
+	assert.NoError(t, err)
+	log = NewLogger(writer, nil)
+	log.Info("message2")
+	assert.NoError(t, writer.Close())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. message1\n"+DEFAULT_TIME_REGEXP+" INFO\\. message2\n$", string(data))
+
+	_, err = writer.Write([]byte("message3\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestFileWriterCRLF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	writer, err := NewFileWriter(path, &FileWriterOptions{CRLF: true})
+	assert.NoError(t, err)
+	n, err := writer.Write([]byte("line1\nline2\r\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 13, n)
+	assert.NoError(t, writer.Close())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "line1\r\nline2\r\n", string(data))
+}
+
+func TestFileWriterReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	writer, err := NewFileWriter(path, nil)
+	assert.NoError(t, err)
+	log := NewLogger(writer, nil)
+	log.Info("message1")
+	assert.NoError(t, os.Rename(path, path+".1"))
+	log.Info("message2")
+	assert.NoError(t, writer.Reopen())
+	log.Info("message3")
+	assert.NoError(t, writer.Close())
+	assert.ErrorIs(t, writer.Reopen(), os.ErrClosed)
+
+	data, err := os.ReadFile(path + ".1")
+	assert.NoError(t, err)
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. message1\n"+DEFAULT_TIME_REGEXP+" INFO\\. message2\n$", string(data))
+	data, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. message3\n$", string(data))
+}
@@ -0,0 +1,58 @@
+//go:build windows
+
+package nslog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// SIGUSR1 is not defined on Windows.
+var reopenSignals = []os.Signal{syscall.SIGHUP}
+
+// Maximum length of path without "\\?\" prefix, which is MAX_PATH minus 12 for the 8.3 file name.
+const windowsMaxPath = 248
+
+// Open the file in append mode with share modes, because os.OpenFile does not share delete access,
+// which makes external tools fail to rename (rotate) or delete the file while logging.
+func openFile(path string, perm os.FileMode) (*os.File, error) {
+	name, err := syscall.UTF16PtrFromString(longPath(path))
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+	attributes := uint32(syscall.FILE_ATTRIBUTE_NORMAL)
+	if perm&0o200 == 0 {
+		attributes = syscall.FILE_ATTRIBUTE_READONLY
+	}
+	handle, err := syscall.CreateFile(
+		name,
+		syscall.FILE_APPEND_DATA|syscall.FILE_WRITE_ATTRIBUTES|syscall.SYNCHRONIZE,
+		syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE,
+		nil,
+		syscall.OPEN_ALWAYS,
+		attributes,
+		0,
+	)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+	return os.NewFile(uintptr(handle), path), nil
+}
+
+// Add "\\?\" prefix to the absolute path if the path is long.
+func longPath(path string) string {
+	if len(path) < windowsMaxPath || strings.HasPrefix(path, `\\?\`) {
+		return path
+	}
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if strings.HasPrefix(absolute, `\\`) {
+		// UNC path such as \\server\share\file
+		return `\\?\UNC\` + absolute[2:]
+	}
+	return `\\?\` + absolute
+}
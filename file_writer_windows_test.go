@@ -0,0 +1,41 @@
+//go:build windows
+
+package nslog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileWriterShareMode(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.log")
+	writer, err := NewFileWriter(path, nil)
+	assert.NoError(t, err)
+	defer writer.Close()
+	NewLogger(writer, nil).Info("message1")
+
+	// read and rename (rotate) while logging
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "INFO. message1")
+	assert.NoError(t, os.Rename(path, filepath.Join(dir, "test.1.log")))
+}
+
+func TestFileWriterLongPath(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), strings.Repeat("a", 100), strings.Repeat("b", 100), strings.Repeat("c", 100))
+	assert.NoError(t, os.MkdirAll(longPath(dir), 0o755))
+	path := filepath.Join(dir, "test.log")
+	writer, err := NewFileWriter(path, nil)
+	assert.NoError(t, err)
+	NewLogger(writer, nil).Info("message1")
+	assert.NoError(t, writer.Close())
+
+	data, err := os.ReadFile(longPath(path))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "INFO. message1")
+}
@@ -0,0 +1,120 @@
+package nslog
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// An option to customize [nslog.FilterHandler].
+// A record matches if it satisfies all of the predicates which are set.
+type FilterHandlerOptions struct {
+	Message        *regexp.Regexp    // Set pattern which message of the record matches. (default: nil)
+	RequiredAttrs  map[string]string // Set attributes which the record or the handler has with the values. (default: nil)
+	ForbiddenAttrs map[string]string // Set attributes which neither the record nor the handler has with the values. (default: nil)
+	GroupPrefix    string            // Set prefix of groups of the handler joined by "." such as "db" for "db.migrations". (default: "")
+	Exclude        bool              // Drop matched records if it is true. Drop records which are not matched if it is false. (default: false)
+}
+
+// A handler to drop records by predicates of message, attributes, and groups before passing them to the next handler,
+// which enables to silence a subsystem without changing level globally.
+// Attributes are compared by key and string of the value without groups.
+type FilterHandler struct {
+	next    slog.Handler
+	options FilterHandlerOptions
+	attrs   []slog.Attr
+	groups  []string
+}
+
+// Create a new [nslog.FilterHandler] object.
+func NewFilterHandler(next slog.Handler, options *FilterHandlerOptions) *FilterHandler {
+	// set default parameters
+	if options == nil {
+		options = &FilterHandlerOptions{}
+	}
+
+	return &FilterHandler{
+		next:    next,
+		options: *options,
+	}
+}
+
+func (handler *FilterHandler) clone() *FilterHandler {
+	return &FilterHandler{
+		next:    handler.next,
+		options: handler.options,
+		attrs:   slices.Clip(handler.attrs),
+		groups:  slices.Clip(handler.groups),
+	}
+}
+
+func (handler *FilterHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return handler.next.Enabled(ctx, level)
+}
+
+func (handler *FilterHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	new_handler := handler.clone()
+	new_handler.next = handler.next.WithAttrs(attrs)
+	new_handler.attrs = append(new_handler.attrs, attrs...)
+	return new_handler
+}
+
+func (handler *FilterHandler) WithGroup(name string) slog.Handler {
+	new_handler := handler.clone()
+	new_handler.next = handler.next.WithGroup(name)
+	new_handler.groups = append(new_handler.groups, name)
+	return new_handler
+}
+
+func (handler *FilterHandler) Handle(ctx context.Context, record slog.Record) error {
+	if handler.match(record) == handler.options.Exclude {
+		return nil
+	}
+	return handler.next.Handle(ctx, record)
+}
+
+// Check whether the record satisfies all of the predicates.
+func (handler *FilterHandler) match(record slog.Record) bool {
+	if handler.options.Message != nil && !handler.options.Message.MatchString(record.Message) {
+		return false
+	}
+	if handler.options.GroupPrefix != "" {
+		groups := strings.Join(handler.groups, ".")
+		if groups != handler.options.GroupPrefix && !strings.HasPrefix(groups, handler.options.GroupPrefix+".") {
+			return false
+		}
+	}
+	if len(handler.options.RequiredAttrs) == 0 && len(handler.options.ForbiddenAttrs) == 0 {
+		return true
+	}
+
+	// resolve only values of keys to compare, so values such as nslog.Lazy are not computed for dropped records
+	values := map[string][]string{}
+	add := func(attribute slog.Attr) {
+		_, required := handler.options.RequiredAttrs[attribute.Key]
+		_, forbidden := handler.options.ForbiddenAttrs[attribute.Key]
+		if required || forbidden {
+			values[attribute.Key] = append(values[attribute.Key], attribute.Value.Resolve().String())
+		}
+	}
+	for _, attribute := range handler.attrs {
+		add(attribute)
+	}
+	record.Attrs(func(attribute slog.Attr) bool {
+		add(attribute)
+		return true
+	})
+	for key, value := range handler.options.RequiredAttrs {
+		if !slices.Contains(values[key], value) {
+			return false
+		}
+	}
+	for key, value := range handler.options.ForbiddenAttrs {
+		if slices.Contains(values[key], value) {
+			return false
+		}
+	}
+	return true
+}
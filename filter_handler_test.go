@@ -0,0 +1,48 @@
+package nslog
+
+import (
+	"bytes"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterHandlerMessage(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := slog.New(NewFilterHandler(NewLogHandler(buf, nil), &FilterHandlerOptions{Message: regexp.MustCompile("^health"), Exclude: true}))
+	log.Info("health check")
+	log.Info("request")
+	assert.NotContains(t, buf.String(), "health check")
+	assert.Contains(t, buf.String(), "INFO. request\n")
+}
+
+func TestFilterHandlerAttrs(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := slog.New(NewFilterHandler(NewLogHandler(buf, nil), &FilterHandlerOptions{
+		RequiredAttrs:  map[string]string{"tenant": "a"},
+		ForbiddenAttrs: map[string]string{"status": "200"},
+	}))
+	log.With("tenant", "a").Info("message1", "status", 500)
+	log.With("tenant", "a").Info("message2", "status", 200)
+	log.Info("message3", "tenant", "b")
+	log.Info("message4", "tenant", "a")
+	assert.Contains(t, buf.String(), "message1")
+	assert.NotContains(t, buf.String(), "message2")
+	assert.NotContains(t, buf.String(), "message3")
+	assert.Contains(t, buf.String(), "message4")
+}
+
+func TestFilterHandlerGroupPrefix(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := slog.New(NewFilterHandler(NewLogHandler(buf, nil), &FilterHandlerOptions{GroupPrefix: "db", Exclude: true}))
+	log.WithGroup("db").Info("message1")
+	log.WithGroup("db").WithGroup("migrations").Info("message2")
+	log.WithGroup("dbx").Info("message3")
+	log.Info("message4")
+	assert.NotContains(t, buf.String(), "message1")
+	assert.NotContains(t, buf.String(), "message2")
+	assert.Contains(t, buf.String(), "message3")
+	assert.Contains(t, buf.String(), "message4")
+}
@@ -0,0 +1,195 @@
+package nslog
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// A level implementing [flag.Value] and [encoding.TextUnmarshaler], which can be set to Level option.
+// It is parsed by [nslog.ParseLevel].
+//
+// Options of levels such as Level and AddSourceLevel are [slog.Leveler] interfaces, which cannot be unmarshaled
+// from JSON or YAML into [nslog.LogHandlerOptions] unless they are set to a *LevelFlag before unmarshaling.
+// GroupLevels is not supported, so use [nslog.Config] to load levels from a config file.
+//
+//	var level nslog.LevelFlag
+//	flag.Var(&level, "log-level", "level to output log message")
+type LevelFlag slog.Level
+
+func (level LevelFlag) Level() slog.Level {
+	return slog.Level(level)
+}
+
+func (level LevelFlag) String() string {
+	return slog.Level(level).String()
+}
+
+func (level *LevelFlag) Set(s string) error {
+	parsed, err := ParseLevel(s)
+	if err != nil {
+		return err
+	}
+	*level = LevelFlag(parsed)
+	return nil
+}
+
+func (level LevelFlag) MarshalText() ([]byte, error) {
+	return []byte(level.String()), nil
+}
+
+func (level *LevelFlag) UnmarshalText(text []byte) error {
+	return level.Set(string(text))
+}
+
+var colorModeNames = []string{"default", "auto", "always", "never"}
+
+func (mode ColorMode) String() string {
+	if mode < 0 || int(mode) >= len(colorModeNames) {
+		return fmt.Sprintf("ColorMode(%d)", int(mode))
+	}
+	return colorModeNames[mode]
+}
+
+// Set color mode by name such as "auto", "always", or "never" (case-insensitive).
+// "true" or "1" is set as ColorModeAlways, and "false" or "0" is set as ColorModeNever.
+func (mode *ColorMode) Set(s string) error {
+	name := strings.ToLower(strings.TrimSpace(s))
+	switch name {
+	case "true", "1":
+		name = "always"
+	case "false", "0":
+		name = "never"
+	}
+	for i, modeName := range colorModeNames {
+		if name == modeName {
+			*mode = ColorMode(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("nslog: invalid color mode %q", s)
+}
+
+func (mode ColorMode) MarshalText() ([]byte, error) {
+	return []byte(mode.String()), nil
+}
+
+func (mode *ColorMode) UnmarshalText(text []byte) error {
+	return mode.Set(string(text))
+}
+
+var levelStyleNames = []string{"dotted", "padded", "short", "bracketed"}
+
+func (style LevelStyle) String() string {
+	if style < 0 || int(style) >= len(levelStyleNames) {
+		return fmt.Sprintf("LevelStyle(%d)", int(style))
+	}
+	return levelStyleNames[style]
+}
+
+// Set level style by name such as "dotted", "padded", "short", or "bracketed" (case-insensitive).
+func (style *LevelStyle) Set(s string) error {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for i, styleName := range levelStyleNames {
+		if name == styleName {
+			*style = LevelStyle(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("nslog: invalid level style %q", s)
+}
+
+func (style LevelStyle) MarshalText() ([]byte, error) {
+	return []byte(style.String()), nil
+}
+
+func (style *LevelStyle) UnmarshalText(text []byte) error {
+	return style.Set(string(text))
+}
+
+var levelSymbolNames = []string{"none", "prefix", "replace"}
+
+func (symbol LevelSymbol) String() string {
+	if symbol < 0 || int(symbol) >= len(levelSymbolNames) {
+		return fmt.Sprintf("LevelSymbol(%d)", int(symbol))
+	}
+	return levelSymbolNames[symbol]
+}
+
+// Set level symbol by name such as "none", "prefix", or "replace" (case-insensitive).
+func (symbol *LevelSymbol) Set(s string) error {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for i, symbolName := range levelSymbolNames {
+		if name == symbolName {
+			*symbol = LevelSymbol(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("nslog: invalid level symbol %q", s)
+}
+
+func (symbol LevelSymbol) MarshalText() ([]byte, error) {
+	return []byte(symbol.String()), nil
+}
+
+func (symbol *LevelSymbol) UnmarshalText(text []byte) error {
+	return symbol.Set(string(text))
+}
+
+var outputFormatNames = []string{"text", "ltsv", "glog", "compact"}
+
+func (format OutputFormat) String() string {
+	if format < 0 || int(format) >= len(outputFormatNames) {
+		return fmt.Sprintf("OutputFormat(%d)", int(format))
+	}
+	return outputFormatNames[format]
+}
+
+// Set output format by name such as "text", "ltsv", "glog", or "compact" (case-insensitive).
+func (format *OutputFormat) Set(s string) error {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for i, formatName := range outputFormatNames {
+		if name == formatName {
+			*format = OutputFormat(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("nslog: invalid output format %q", s)
+}
+
+func (format OutputFormat) MarshalText() ([]byte, error) {
+	return []byte(format.String()), nil
+}
+
+func (format *OutputFormat) UnmarshalText(text []byte) error {
+	return format.Set(string(text))
+}
+
+var overflowNames = []string{"none", "wrap", "truncate"}
+
+func (overflow Overflow) String() string {
+	if overflow < 0 || int(overflow) >= len(overflowNames) {
+		return fmt.Sprintf("Overflow(%d)", int(overflow))
+	}
+	return overflowNames[overflow]
+}
+
+// Set overflow by name such as "none", "wrap", or "truncate" (case-insensitive).
+func (overflow *Overflow) Set(s string) error {
+	name := strings.ToLower(strings.TrimSpace(s))
+	for i, overflowName := range overflowNames {
+		if name == overflowName {
+			*overflow = Overflow(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("nslog: invalid overflow %q", s)
+}
+
+func (overflow Overflow) MarshalText() ([]byte, error) {
+	return []byte(overflow.String()), nil
+}
+
+func (overflow *Overflow) UnmarshalText(text []byte) error {
+	return overflow.Set(string(text))
+}
@@ -0,0 +1,89 @@
+package nslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelFlag(t *testing.T) {
+	var level LevelFlag
+	var colorMode ColorMode
+	var levelStyle LevelStyle
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.Var(&level, "log-level", "")
+	flags.Var(&colorMode, "log-color", "")
+	flags.Var(&levelStyle, "log-level-style", "")
+	err := flags.Parse([]string{"-log-level", "debug", "-log-color", "auto", "-log-level-style", "SHORT"})
+	assert.NoError(t, err)
+	assert.Equal(t, slog.LevelDebug, level.Level())
+	assert.Equal(t, ColorModeAuto, colorMode)
+	assert.Equal(t, LevelStyleShort, levelStyle)
+
+	assert.Error(t, flags.Parse([]string{"-log-level", "verbose"}))
+	assert.Error(t, flags.Parse([]string{"-log-color", "sometimes"}))
+	assert.NoError(t, flags.Parse([]string{"-log-color", "TRUE"}))
+	assert.Equal(t, ColorModeAlways, colorMode)
+	assert.NoError(t, flags.Parse([]string{"-log-color", "0"}))
+	assert.Equal(t, ColorModeNever, colorMode)
+	assert.Error(t, flags.Parse([]string{"-log-level-style", "fancy"}))
+
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{Level: level})
+	log.Debug("log message")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" DEBUG log message\n$", buf.String())
+}
+
+func TestLevelFlagUnmarshal(t *testing.T) {
+	// levels are unmarshaled only if they are set to a *LevelFlag
+	err := json.Unmarshal([]byte(`{"Level": "WARN"}`), &LogHandlerOptions{})
+	assert.Error(t, err)
+
+	options := LogHandlerOptions{Level: new(LevelFlag), AddSourceLevel: new(LevelFlag)}
+	err = json.Unmarshal([]byte(`{"Level": "WARN", "AddSourceLevel": "ERROR", "ColorMode": "never", "LevelStyle": "bracketed"}`), &options)
+	assert.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, options.Level.Level())
+	assert.Equal(t, slog.LevelError, options.AddSourceLevel.Level())
+	assert.Equal(t, ColorModeNever, options.ColorMode)
+	assert.Equal(t, LevelStyleBracketed, options.LevelStyle)
+
+	text, err := json.Marshal(struct {
+		Level      LevelFlag
+		ColorMode  ColorMode
+		LevelStyle LevelStyle
+	}{LevelFlag(slog.LevelDebug + 2), ColorModeAlways, LevelStylePadded})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"Level":"DEBUG+2","ColorMode":"always","LevelStyle":"padded"}`, string(text))
+	assert.Equal(t, "ColorMode(9)", ColorMode(9).String())
+}
+
+func TestOutputFormatFlag(t *testing.T) {
+	var format OutputFormat
+	assert.NoError(t, format.Set("LTSV"))
+	assert.Equal(t, FormatLTSV, format)
+	assert.Equal(t, "ltsv", format.String())
+	assert.Error(t, format.Set("xml"))
+	assert.Equal(t, "OutputFormat(9)", OutputFormat(9).String())
+}
+
+func TestOverflowFlag(t *testing.T) {
+	var overflow Overflow
+	assert.NoError(t, overflow.Set("Wrap"))
+	assert.Equal(t, OverflowWrap, overflow)
+	assert.Equal(t, "wrap", overflow.String())
+	assert.Error(t, overflow.Set("ellipsis"))
+	assert.Equal(t, "Overflow(9)", Overflow(9).String())
+}
+
+func TestLevelSymbolFlag(t *testing.T) {
+	var symbol LevelSymbol
+	assert.NoError(t, symbol.Set("Replace"))
+	assert.Equal(t, LevelSymbolReplace, symbol)
+	assert.Equal(t, "replace", symbol.String())
+	assert.Error(t, symbol.Set("emoji"))
+	assert.Equal(t, "LevelSymbol(9)", LevelSymbol(9).String())
+}
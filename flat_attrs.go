@@ -0,0 +1,48 @@
+package nslog
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// An attribute of the flat formats such as LTSV, glog, and compact, which have no brackets of groups.
+type flatAttr struct {
+	key           string   // key qualified by groups of the handler for attributes of the handler
+	value         string   // value formatted by ValueFormat option, or the payload
+	continuations []string // lines of the payload or the expanded error
+}
+
+// Flatten attributes of the handler and the record in order.
+// Payloads are output as the value if inline is true, or summarized with continuation lines if inline is false.
+func (handler *LogHandler) flatAttrs(ctx context.Context, record slog.Record, inline bool) []flatAttr {
+	var attrs []flatAttr
+	for depth, handlerAttrs := range handler.attrs {
+		var qualifier string
+		if depth > 0 {
+			qualifier = strings.Join(handler.groups[:depth], handler.options.GroupSeparator) + handler.options.GroupSeparator
+		}
+		for _, attribute := range handlerAttrs {
+			attrs = append(attrs, flatAttr{key: qualifier + attribute.Key, value: handler.options.ValueFormat.format(attribute.Value)})
+		}
+	}
+	for _, attribute := range handler.recordAttrs(ctx, record) {
+		if inline {
+			if data, cut, ok := payloadData(attribute, handler.options.PayloadMaxSize); ok {
+				if cut > 0 {
+					data += truncatedMarker(cut)
+				}
+				attrs = append(attrs, flatAttr{key: attribute.Key, value: data})
+				continue
+			}
+			attrs = append(attrs, flatAttr{key: attribute.Key, value: handler.options.ValueFormat.format(attribute.Value)})
+			continue
+		}
+		if summary, lines, ok := payloadLines(attribute, handler.options.PayloadMaxSize); ok {
+			attrs = append(attrs, flatAttr{key: attribute.Key, value: summary, continuations: lines})
+			continue
+		}
+		attrs = append(attrs, flatAttr{key: attribute.Key, value: handler.options.ValueFormat.format(attribute.Value), continuations: handler.expandError(attribute)})
+	}
+	return attrs
+}
@@ -0,0 +1,130 @@
+package nslog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// An option to customize [nslog.GCPHandler].
+type GCPHandlerOptions struct {
+	Level     slog.Leveler      // Set minimum level to output records. (default: slog.LevelInfo)
+	ProjectID string            // Set project ID to qualify trace ID as "projects/<id>/traces/<trace>". (default: "", which outputs trace ID as is)
+	Labels    map[string]string // Set labels added to every entry. (default: nil)
+	AddSource bool              // Add source location of the record if it is true. (default: false)
+}
+
+// A handler to output records in the structured JSON format of Google Cloud Logging, which is collected from stdout on GKE and Cloud Run.
+// Levels are mapped to severities, and attributes are output as fields of jsonPayload where groups are nested objects.
+type GCPHandler struct {
+	writer  io.Writer
+	mutex   *sync.Mutex
+	options GCPHandlerOptions
+	fields  jsonAttrs
+}
+
+// Create a new [nslog.GCPHandler] object.
+func NewGCPHandler(writer io.Writer, options *GCPHandlerOptions) *GCPHandler {
+	// set default parameters
+	if options == nil {
+		options = &GCPHandlerOptions{}
+	}
+	if options.Level == nil {
+		options.Level = slog.LevelInfo
+	}
+
+	return &GCPHandler{
+		writer:  writer,
+		mutex:   &sync.Mutex{},
+		options: *options,
+	}
+}
+
+func (handler *GCPHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= handler.options.Level.Level()
+}
+
+func (handler *GCPHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	new_handler := *handler
+	new_handler.fields = handler.fields.withAttrs(attrs)
+	return &new_handler
+}
+
+func (handler *GCPHandler) WithGroup(name string) slog.Handler {
+	new_handler := *handler
+	new_handler.fields = handler.fields.withGroup(name)
+	return &new_handler
+}
+
+func (handler *GCPHandler) Handle(ctx context.Context, record slog.Record) error {
+	// attributes of the handler and the record as fields of jsonPayload
+	entry := handler.fields.object(record)
+
+	// special fields of Cloud Logging, which overwrite attributes of the same keys
+	entry["severity"] = GCPSeverity(record.Level)
+	entry["message"] = record.Message
+	if !record.Time.IsZero() {
+		entry["time"] = record.Time.Format(time.RFC3339Nano)
+	}
+	if len(handler.options.Labels) > 0 {
+		entry["logging.googleapis.com/labels"] = handler.options.Labels
+	}
+	if handler.options.AddSource && record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		entry["logging.googleapis.com/sourceLocation"] = map[string]string{
+			"file":     frame.File,
+			"line":     strconv.Itoa(frame.Line),
+			"function": frame.Function,
+		}
+	}
+	spanContext := oteltrace.SpanContextFromContext(ctx)
+	if spanContext.IsValid() {
+		trace := spanContext.TraceID().String()
+		if handler.options.ProjectID != "" {
+			trace = "projects/" + handler.options.ProjectID + "/traces/" + trace
+		}
+		entry["logging.googleapis.com/trace"] = trace
+		entry["logging.googleapis.com/spanId"] = spanContext.SpanID().String()
+		entry["logging.googleapis.com/trace_sampled"] = spanContext.IsSampled()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("nslog: failed to marshal gcp entry: %w", err)
+	}
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+	_, err = handler.writer.Write(append(line, '\n'))
+	return err
+}
+
+// Get severity of Google Cloud Logging for the level, such as "ERROR" for slog.LevelError.
+// Levels above Error are "CRITICAL", "ALERT", and "EMERGENCY" in steps of 4.
+func GCPSeverity(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError+12:
+		return "EMERGENCY"
+	case level >= slog.LevelError+8:
+		return "ALERT"
+	case level >= slog.LevelError+4:
+		return "CRITICAL"
+	case level >= slog.LevelError:
+		return "ERROR"
+	case level >= slog.LevelWarn:
+		return "WARNING"
+	case level >= slog.LevelInfo+2:
+		return "NOTICE"
+	case level >= slog.LevelInfo:
+		return "INFO"
+	default:
+		return "DEBUG"
+	}
+}
@@ -0,0 +1,52 @@
+package nslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGCPHandler(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := slog.New(NewGCPHandler(buf, &GCPHandlerOptions{ProjectID: "my-project", Labels: map[string]string{"app": "test"}, AddSource: true}))
+	log.With("key1", "val1").WithGroup("Main").With("key2", 2).ErrorContext(newSpanContext(), "error message", "err", errors.New("failed"), "elapsed", time.Second)
+	log.WithGroup("Empty").Debug("debug message")
+	log.WithGroup("Empty").Warn("warn message")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 2)
+	entry := map[string]any{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "ERROR", entry["severity"])
+	assert.Equal(t, "error message", entry["message"])
+	assert.Equal(t, "val1", entry["key1"])
+	assert.Equal(t, map[string]any{"key2": float64(2), "err": "failed", "elapsed": "1s"}, entry["Main"])
+	assert.Equal(t, map[string]any{"app": "test"}, entry["logging.googleapis.com/labels"])
+	assert.Equal(t, "projects/my-project/traces/0af7651916cd43dd8448eb211c80319c", entry["logging.googleapis.com/trace"])
+	assert.Equal(t, "b7ad6b7169203331", entry["logging.googleapis.com/spanId"])
+	assert.Contains(t, entry["logging.googleapis.com/sourceLocation"].(map[string]any)["file"], "gcp_handler_test.go")
+	assert.Contains(t, entry, "time")
+
+	entry = map[string]any{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, "WARNING", entry["severity"])
+	assert.NotContains(t, entry, "Empty")
+	assert.NotContains(t, entry, "logging.googleapis.com/trace")
+}
+
+func TestGCPSeverity(t *testing.T) {
+	assert.Equal(t, "DEBUG", GCPSeverity(slog.LevelDebug))
+	assert.Equal(t, "INFO", GCPSeverity(slog.LevelInfo))
+	assert.Equal(t, "NOTICE", GCPSeverity(slog.LevelInfo+2))
+	assert.Equal(t, "WARNING", GCPSeverity(slog.LevelWarn))
+	assert.Equal(t, "ERROR", GCPSeverity(slog.LevelError))
+	assert.Equal(t, "CRITICAL", GCPSeverity(slog.LevelError+4))
+	assert.Equal(t, "ALERT", GCPSeverity(slog.LevelError+8))
+	assert.Equal(t, "EMERGENCY", GCPSeverity(slog.LevelError+12))
+}
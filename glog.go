@@ -0,0 +1,64 @@
+package nslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Layout of time of the glog header such as "0102 15:04:05.000000".
+const GLOG_TIME_LAYOUT = "0102 15:04:05.000000"
+
+// Format the record with the header of glog and klog such as "I0102 15:04:05.000000   12345 file.go:42] message key=value"
+// by FormatGlog option. The header always has the PID and the source, and attributes follow the message in the text format.
+func (handler *LogHandler) formatGlog(ctx context.Context, record slog.Record) []byte {
+	// severity such as "I", where Debug is Info since glog has no lower severity
+	severity := byte('I')
+	switch {
+	case record.Level >= LevelFatal:
+		severity = 'F'
+	case record.Level >= slog.LevelError:
+		severity = 'E'
+	case record.Level >= slog.LevelWarn:
+		severity = 'W'
+	}
+
+	recordTime := record.Time
+	if handler.options.UseUTC {
+		recordTime = recordTime.UTC()
+	}
+
+	file, line := "???", 1
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		file, line = filepath.Base(frame.File), frame.Line
+	}
+
+	header := fmt.Sprintf("%c%s %7d %s:%d]", severity, recordTime.Format(GLOG_TIME_LAYOUT), os.Getpid(), file, line)
+	log_strings := []string{header, record.Message}
+
+	// attributes of the handler and the record
+	var attributes []string
+	var continuations []string
+	for _, attribute := range handler.flatAttrs(ctx, record, false) {
+		attributes = append(attributes, handler.attrString(attribute.key, attribute.value))
+		continuations = append(continuations, attribute.continuations...)
+	}
+	attributesText := strings.Join(attributes, " ")
+	if attributesText != "" {
+		log_strings = append(log_strings, attributesText)
+	}
+
+	log_line := strings.Join(log_strings, " ")
+	if handler.options.MaxLineLength > 0 {
+		log_line = truncateLine(log_line, attributesText, handler.options.MaxLineLength)
+	}
+	for _, continuation := range continuations {
+		log_line += handler.options.LineEnding + continuation
+	}
+	return []byte(log_line + handler.options.LineEnding)
+}
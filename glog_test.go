@@ -0,0 +1,39 @@
+package nslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatGlog(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{Format: FormatGlog, Level: slog.LevelDebug, ExpandErrors: true})
+	log.With("key1", "val1").WithGroup("Main").Warn("warn message", "key2", "val2")
+	log.Debug("debug message")
+	log.Error("error message", "err", fmt.Errorf("outer: %w", errors.New("inner")))
+
+	pid := fmt.Sprintf("%7d", os.Getpid())
+	lines := bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n"))
+	assert.Len(t, lines, 4)
+	assert.Regexp(t, "^W[0-9]{4} [0-9]{2}:[0-9]{2}:[0-9]{2}\\.[0-9]{6} "+pid+" glog_test\\.go:[0-9]+\\] warn message key1=val1 Main\\.key2=val2$", string(lines[0]))
+	assert.Regexp(t, "^I[0-9]{4} .+ glog_test\\.go:[0-9]+\\] debug message$", string(lines[1]))
+	assert.Regexp(t, "^E[0-9]{4} .+ glog_test\\.go:[0-9]+\\] error message err=outer: inner$", string(lines[2]))
+	assert.Equal(t, "\terr: caused by: inner", string(lines[3]))
+}
+
+func TestFormatGlogTime(t *testing.T) {
+	buf := new(bytes.Buffer)
+	handler := NewLogHandler(buf, &LogHandlerOptions{Format: FormatGlog, UseUTC: true})
+	record := slog.NewRecord(time.Date(2024, 1, 2, 15, 4, 5, 123456000, time.UTC), slog.LevelInfo, "log message", 0)
+	_ = handler.Handle(context.Background(), record)
+	assert.Equal(t, "I0102 15:04:05.123456 "+fmt.Sprintf("%7s", strconv.Itoa(os.Getpid()))+" ???:1] log message\n", buf.String())
+}
@@ -0,0 +1,148 @@
+// The grpclog package provides gRPC interceptors for the nslog package.
+package grpclog
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mikiepure/nslog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const DEFAULT_MESSAGE = "grpc call"
+
+var DEFAULT_METADATA_KEYS = []string{"x-request-id"}
+
+// An option to customize logging of interceptors.
+type Options struct {
+	Level        slog.Level // Set level of successful calls. Failed calls are logged at Warn or Error level by code. (default: slog.LevelInfo)
+	MetadataKeys []string   // Set keys of incoming metadata added to the context by [nslog.AddContextAttrs] on server. (default: ["x-request-id"])
+	Message      string     // Set message of call log. (default: "grpc call")
+}
+
+func newOptions(options *Options) Options {
+	// set default parameters
+	if options == nil {
+		options = &Options{}
+	}
+	opts := *options
+	if opts.MetadataKeys == nil {
+		opts.MetadataKeys = DEFAULT_METADATA_KEYS
+	}
+	if opts.Message == "" {
+		opts.Message = DEFAULT_MESSAGE
+	}
+	return opts
+}
+
+// Create an interceptor to log unary calls on server.
+func UnaryServerInterceptor(logger *slog.Logger, options *Options) grpc.UnaryServerInterceptor {
+	opts := newOptions(options)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx = extractMetadata(ctx, opts.MetadataKeys)
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, logger, opts, info.FullMethod, err, time.Since(start), peerAddr(ctx))
+		return resp, err
+	}
+}
+
+// Create an interceptor to log stream calls on server.
+func StreamServerInterceptor(logger *slog.Logger, options *Options) grpc.StreamServerInterceptor {
+	opts := newOptions(options)
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := extractMetadata(stream.Context(), opts.MetadataKeys)
+		start := time.Now()
+		err := handler(srv, &serverStream{ServerStream: stream, ctx: ctx})
+		logCall(ctx, logger, opts, info.FullMethod, err, time.Since(start), peerAddr(ctx))
+		return err
+	}
+}
+
+// Create an interceptor to log unary calls on client.
+func UnaryClientInterceptor(logger *slog.Logger, options *Options) grpc.UnaryClientInterceptor {
+	opts := newOptions(options)
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+		logCall(ctx, logger, opts, method, err, time.Since(start), cc.Target())
+		return err
+	}
+}
+
+// Create an interceptor to log stream calls on client. The call is logged when the stream is created.
+func StreamClientInterceptor(logger *slog.Logger, options *Options) grpc.StreamClientInterceptor {
+	opts := newOptions(options)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		stream, err := streamer(ctx, desc, cc, method, callOpts...)
+		logCall(ctx, logger, opts, method, err, time.Since(start), cc.Target())
+		return stream, err
+	}
+}
+
+func logCall(ctx context.Context, logger *slog.Logger, opts Options, method string, err error, duration time.Duration, peer string) {
+	code := status.Code(err)
+	level := opts.Level
+	switch code {
+	case codes.OK:
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable, codes.DataLoss:
+		level = slog.LevelError
+	default:
+		level = max(level, slog.LevelWarn)
+	}
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Duration("duration", duration),
+		slog.String("peer", peer),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+	}
+	logger.LogAttrs(ctx, level, opts.Message, attrs...)
+}
+
+// Add values of the metadata keys to the context as attributes.
+func extractMetadata(ctx context.Context, keys []string) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	var attrs []slog.Attr
+	for _, key := range keys {
+		if values := md.Get(key); len(values) > 0 {
+			attrs = append(attrs, slog.String(key, values[0]))
+		}
+	}
+	if len(attrs) == 0 {
+		return ctx
+	}
+	return nslog.AddContextAttrs(ctx, attrs...)
+}
+
+func peerAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// A wrapper of [grpc.ServerStream] to override the context.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *serverStream) Context() context.Context {
+	return stream.ctx
+}
@@ -0,0 +1,53 @@
+package grpclog
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+
+	"github.com/mikiepure/nslog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestInterceptors(t *testing.T) {
+	serverBuf := new(bytes.Buffer)
+	serverLog := nslog.NewLogger(serverBuf, nil)
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(serverLog, nil)),
+		grpc.StreamInterceptor(StreamServerInterceptor(serverLog, nil)),
+	)
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("ok", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	clientBuf := new(bytes.Buffer)
+	clientLog := nslog.NewLogger(clientBuf, nil)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(clientLog, nil)),
+	)
+	assert.NoError(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "1234")
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "ok"})
+	assert.NoError(t, err)
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+	assert.Error(t, err)
+
+	assert.Regexp(t, "INFO\\. grpc call method=/grpc\\.health\\.v1\\.Health/Check code=OK duration=.+ peer=bufconn x-request-id=1234\n", serverBuf.String())
+	assert.Regexp(t, "WARN\\. grpc call method=/grpc\\.health\\.v1\\.Health/Check code=NotFound duration=.+ peer=bufconn error=unknown service x-request-id=1234 \\(.+\\)\n", serverBuf.String())
+	assert.Regexp(t, "INFO\\. grpc call method=/grpc\\.health\\.v1\\.Health/Check code=OK duration=.+ peer=passthrough:///bufnet\n", clientBuf.String())
+}
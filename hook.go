@@ -0,0 +1,37 @@
+package nslog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// A set of functions called by [nslog.LogHandler] on handling records, which enables metrics, auditing,
+// and enrichment without wrapper handlers. Functions which are nil are not called.
+type Hook struct {
+	// Called before formatting the record. The record can be modified such as by [slog.Record.AddAttrs],
+	// and it is dropped if the function returns false.
+	BeforeFormat func(ctx context.Context, record *slog.Record) bool
+
+	// Called after writing the record with the written line and the error of the writer.
+	// The line must not be modified or retained after the function returns.
+	AfterWrite func(ctx context.Context, record slog.Record, line []byte, err error)
+}
+
+// Call BeforeFormat of the hooks in order, and return false if the record is dropped by one of them.
+func (handler *LogHandler) beforeFormat(ctx context.Context, record *slog.Record) bool {
+	for _, hook := range handler.options.Hooks {
+		if hook.BeforeFormat != nil && !hook.BeforeFormat(ctx, record) {
+			return false
+		}
+	}
+	return true
+}
+
+// Call AfterWrite of the hooks in order.
+func (handler *LogHandler) afterWrite(ctx context.Context, record slog.Record, line []byte, err error) {
+	for _, hook := range handler.options.Hooks {
+		if hook.AfterWrite != nil {
+			hook.AfterWrite(ctx, record, line, err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package nslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHooks(t *testing.T) {
+	buf := new(bytes.Buffer)
+	var lines []string
+	log := NewLogger(buf, &LogHandlerOptions{Hooks: []Hook{
+		{
+			BeforeFormat: func(ctx context.Context, record *slog.Record) bool {
+				if record.Message == "secret" {
+					return false
+				}
+				record.AddAttrs(slog.String("hooked", "true"))
+				return true
+			},
+		},
+		{
+			AfterWrite: func(ctx context.Context, record slog.Record, line []byte, err error) {
+				assert.NoError(t, err)
+				lines = append(lines, string(line))
+			},
+		},
+	}})
+	log.Info("log message", "key1", "val1")
+	log.Info("secret")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. log message key1=val1 hooked=true\n$", buf.String())
+	assert.Equal(t, []string{buf.String()}, lines)
+}
+
+func TestHooksError(t *testing.T) {
+	var errs []error
+	log := NewLogger(errorWriter{}, &LogHandlerOptions{Hooks: []Hook{{
+		AfterWrite: func(ctx context.Context, record slog.Record, line []byte, err error) {
+			errs = append(errs, err)
+		},
+	}}})
+	log.Info("log message")
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "write error")
+}
@@ -0,0 +1,106 @@
+// The httplog package provides HTTP logging for the nslog package.
+package httplog
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const DEFAULT_MESSAGE = "http request"
+
+// An option to customize access logging of [httplog.Middleware].
+type Options struct {
+	Level         slog.Level    // Set level of access log. Server errors (5xx) are logged at Error level. (default: slog.LevelInfo)
+	SlowThreshold time.Duration // Log requests at Warn level if they take longer than it. Disabled if it is 0. (default: 0)
+	Headers       []string      // Set request headers to capture as attributes. (default: nil)
+	Message       string        // Set message of access log. (default: "http request")
+}
+
+// Create a middleware to log method, path, status, bytes, duration, and remote address of each request.
+func Middleware(logger *slog.Logger, options *Options) func(http.Handler) http.Handler {
+	// set default parameters
+	if options == nil {
+		options = &Options{}
+	}
+	if options.Message == "" {
+		options.Message = DEFAULT_MESSAGE
+	}
+	opts := *options
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			serveLogged(logger, opts, next, w, r)
+		})
+	}
+}
+
+// Serve the request by the handler and log it by the logger.
+func serveLogged(logger *slog.Logger, opts Options, next http.Handler, w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+	next.ServeHTTP(recorder, r)
+	duration := time.Since(start)
+
+	level := opts.Level
+	if recorder.status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	} else if opts.SlowThreshold > 0 && duration > opts.SlowThreshold && level < slog.LevelWarn {
+		level = slog.LevelWarn
+	}
+	if !logger.Enabled(r.Context(), level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.Int("status", recorder.status),
+		slog.Int64("bytes", recorder.bytes),
+		slog.Duration("duration", duration),
+		slog.String("remote", r.RemoteAddr),
+	}
+	if opts.SlowThreshold > 0 && duration > opts.SlowThreshold {
+		attrs = append(attrs, slog.Bool("slow", true))
+	}
+	for _, header := range opts.Headers {
+		if value := r.Header.Get(header); value != "" {
+			attrs = append(attrs, slog.String(header, value))
+		}
+	}
+	logger.LogAttrs(r.Context(), level, opts.Message, attrs...)
+}
+
+// A wrapper of [http.ResponseWriter] to record status and size of response.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (recorder *responseRecorder) WriteHeader(status int) {
+	if !recorder.wroteHeader {
+		recorder.status = status
+		recorder.wroteHeader = true
+	}
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *responseRecorder) Write(p []byte) (int, error) {
+	recorder.wroteHeader = true
+	n, err := recorder.ResponseWriter.Write(p)
+	recorder.bytes += int64(n)
+	return n, err
+}
+
+func (recorder *responseRecorder) Flush() {
+	if flusher, ok := recorder.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Get the original [http.ResponseWriter] for [http.ResponseController].
+func (recorder *responseRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
@@ -0,0 +1,48 @@
+package httplog
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mikiepure/nslog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := nslog.NewLogger(buf, nil)
+	handler := Middleware(logger, &Options{Headers: []string{"User-Agent"}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	}))
+
+	request := httptest.NewRequest(http.MethodPost, "/users", nil)
+	request.Header.Set("User-Agent", "test-agent")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	assert.Regexp(t, "INFO\\. http request method=POST path=/users status=201 bytes=5 duration=.+ remote=192\\.0\\.2\\.1:1234 User-Agent=test-agent\n", buf.String())
+}
+
+func TestMiddlewareServerError(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := nslog.NewLogger(buf, nil)
+	handler := Middleware(logger, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "error", http.StatusInternalServerError)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Contains(t, buf.String(), "ERROR http request method=GET path=/ status=500")
+}
+
+func TestMiddlewareSlow(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := nslog.NewLogger(buf, nil)
+	handler := Middleware(logger, &Options{SlowThreshold: time.Millisecond})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Regexp(t, "WARN\\. http request method=GET path=/ status=200 bytes=0 duration=.+ remote=.+ slow=true", buf.String())
+}
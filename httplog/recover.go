@@ -0,0 +1,39 @@
+package httplog
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/mikiepure/nslog"
+)
+
+// Create a middleware to recover panic of the handler, which logs the value and the stack at Error level
+// and responds 500 Internal Server Error if the response is not written yet.
+// If logger is nil, the logger in the request context such as by [httplog.RequestLogger] is used.
+// [http.ErrAbortHandler] is not logged and panics again to abort the response.
+func Recoverer(logger *slog.Logger, options *nslog.RecoverOptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			defer func() {
+				value := recover()
+				if value == nil {
+					return
+				}
+				if err, ok := value.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(value)
+				}
+				if !recorder.wroteHeader {
+					http.Error(recorder, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+				requestLogger := logger
+				if requestLogger == nil {
+					requestLogger = nslog.FromContext(r.Context())
+				}
+				nslog.LogPanic(r.Context(), requestLogger, value, options)
+			}()
+			next.ServeHTTP(recorder, r)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package httplog
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mikiepure/nslog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoverer(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := nslog.NewLogger(buf, &nslog.LogHandlerOptions{ExpandErrors: true})
+	handler := RequestLogger(logger, nil)(Recoverer(nil, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})))
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+	assert.Regexp(t, "ERROR \\[request_id=[0-9a-f]{32}\\]: panic recovered panic=boom \\(recover_test\\.go:[0-9]+\\)\n\tpanic: boom\n\tpanic: goroutine ", buf.String())
+	assert.Contains(t, buf.String(), "ERROR [request_id=")
+	assert.Contains(t, buf.String(), "http request method=GET path=/ status=500")
+}
+
+func TestRecovererAbort(t *testing.T) {
+	buf := new(bytes.Buffer)
+	handler := Recoverer(nslog.NewLogger(buf, nil), nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+	assert.Equal(t, "", buf.String())
+}
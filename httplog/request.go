@@ -0,0 +1,106 @@
+package httplog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+
+	"github.com/mikiepure/nslog"
+)
+
+const DEFAULT_REQUEST_ID_HEADER = "X-Request-ID"
+const DEFAULT_REQUEST_ID_KEY = "request_id"
+const DEFAULT_START_MESSAGE = "http request started"
+const MAX_REQUEST_ID_LENGTH = 128
+
+type requestIDKey struct{}
+
+// An option to customize [httplog.RequestLogger].
+type RequestOptions struct {
+	Header         string        // Set header to take and return request ID. (default: "X-Request-ID")
+	Key            string        // Set attribute key of request ID. (default: "request_id")
+	Generate       func() string // Set function to generate request ID. (default: 32 random hex digits)
+	IgnoreIncoming bool          // Generate request ID even if the request has it, such as for untrusted clients. (default: false)
+	StartLevel     slog.Leveler  // Set level of log on start of request. (default: slog.LevelDebug)
+	Access         *Options      // Set options of access log on finish of request, same as [httplog.Middleware]. (default: nil)
+}
+
+// Create a middleware for request-scoped logging, which takes request ID from the header or generates it,
+// returns it in the response header, and stores the logger with the request ID attribute in the request context.
+// The logger is taken by [nslog.FromContext], and the request ID is taken by [httplog.RequestID].
+// Start and finish of each request are logged by the logger.
+func RequestLogger(logger *slog.Logger, options *RequestOptions) func(http.Handler) http.Handler {
+	// set default parameters
+	if options == nil {
+		options = &RequestOptions{}
+	}
+	if options.Header == "" {
+		options.Header = DEFAULT_REQUEST_ID_HEADER
+	}
+	if options.Key == "" {
+		options.Key = DEFAULT_REQUEST_ID_KEY
+	}
+	if options.Generate == nil {
+		options.Generate = generateRequestID
+	}
+	if options.StartLevel == nil {
+		options.StartLevel = slog.LevelDebug
+	}
+	var access Options
+	if options.Access != nil {
+		access = *options.Access
+	}
+	if access.Message == "" {
+		access.Message = DEFAULT_MESSAGE
+	}
+	opts := *options
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(opts.Header)
+			if opts.IgnoreIncoming || !validRequestID(id) {
+				id = opts.Generate()
+			}
+			r.Header.Set(opts.Header, id)
+			w.Header().Set(opts.Header, id)
+
+			requestLogger := logger.With(slog.String(opts.Key, id))
+			ctx := nslog.NewContext(r.Context(), requestLogger)
+			ctx = context.WithValue(ctx, requestIDKey{}, id)
+			r = r.WithContext(ctx)
+
+			requestLogger.LogAttrs(ctx, opts.StartLevel.Level(), DEFAULT_START_MESSAGE,
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+			)
+			serveLogged(requestLogger, access, next, w, r)
+		})
+	}
+}
+
+// Get the request ID stored by [httplog.RequestLogger], or empty string if the context has no request ID.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func generateRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// Check whether the incoming request ID is safe to log, which has printable ASCII characters without spaces.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > MAX_REQUEST_ID_LENGTH {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
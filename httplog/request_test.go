@@ -0,0 +1,58 @@
+package httplog
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mikiepure/nslog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := nslog.NewLogger(buf, &nslog.LogHandlerOptions{Level: slog.LevelDebug})
+	var id string
+	handler := RequestLogger(logger, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id = RequestID(r.Context())
+		nslog.FromContext(r.Context()).Info("in handler")
+	}))
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Regexp(t, "^[0-9a-f]{32}$", id)
+	assert.Equal(t, id, response.Header().Get("X-Request-ID"))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "DEBUG [request_id="+id+"]: http request started method=GET path=/users")
+	assert.Contains(t, lines[1], "INFO. [request_id="+id+"]: in handler")
+	assert.Contains(t, lines[2], "INFO. [request_id="+id+"]: http request method=GET path=/users status=200")
+}
+
+func TestRequestLoggerIncoming(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := nslog.NewLogger(buf, nil)
+	handler := RequestLogger(logger, &RequestOptions{Header: "X-Trace", Key: "rid"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("X-Trace", "abc-123")
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	assert.Equal(t, "abc-123", response.Header().Get("X-Trace"))
+	assert.Contains(t, buf.String(), "INFO. [rid=abc-123]: http request method=GET")
+
+	// invalid request ID is replaced
+	request = httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set("X-Trace", "abc 123")
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	assert.Regexp(t, "^[0-9a-f]{32}$", response.Header().Get("X-Trace"))
+}
+
+func TestRequestID(t *testing.T) {
+	assert.Equal(t, "", RequestID(httptest.NewRequest(http.MethodGet, "/", nil).Context()))
+}
@@ -0,0 +1,106 @@
+package httplog
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const DEFAULT_TRANSPORT_MESSAGE = "http client request"
+const DEFAULT_MAX_BODY_SIZE = 1024
+
+// An option to customize logging of [httplog.Transport].
+type TransportOptions struct {
+	Level       slog.Level // Set level of request log. Failed requests are logged at Error level. (default: slog.LevelInfo)
+	LogBodies   bool       // Log request and response bodies if it is true. (default: false)
+	MaxBodySize int        // Set maximum size of logged bodies. Bodies are truncated if exceeded. (default: 1024)
+	Message     string     // Set message of request log. (default: "http client request")
+}
+
+// A [http.RoundTripper] to log outgoing requests and responses.
+type Transport struct {
+	base    http.RoundTripper
+	logger  *slog.Logger
+	options TransportOptions
+}
+
+// Create a new [httplog.Transport] object, which wraps base. [http.DefaultTransport] is used if base is nil.
+func NewTransport(base http.RoundTripper, logger *slog.Logger, options *TransportOptions) *Transport {
+	// set default parameters
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if options == nil {
+		options = &TransportOptions{}
+	}
+	if options.MaxBodySize <= 0 {
+		options.MaxBodySize = DEFAULT_MAX_BODY_SIZE
+	}
+	if options.Message == "" {
+		options.Message = DEFAULT_TRANSPORT_MESSAGE
+	}
+
+	return &Transport{
+		base:    base,
+		logger:  logger,
+		options: *options,
+	}
+}
+
+func (transport *Transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	attrs := []slog.Attr{
+		slog.String("method", request.Method),
+		slog.String("url", request.URL.Redacted()),
+	}
+	if transport.options.LogBodies && request.Body != nil && request.GetBody != nil {
+		// read copy of body to keep the original body for the base transport
+		body, err := request.GetBody()
+		if err == nil {
+			attrs = append(attrs, slog.String("request_body", transport.readBody(body)))
+			body.Close()
+		}
+	}
+
+	start := time.Now()
+	response, err := transport.base.RoundTrip(request)
+	attrs = append(attrs, slog.Duration("latency", time.Since(start)))
+
+	level := transport.options.Level
+	if err != nil {
+		level = slog.LevelError
+		attrs = append(attrs, slog.String("error", err.Error()))
+	} else {
+		attrs = append(attrs, slog.Int("status", response.StatusCode))
+		if response.StatusCode >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		if transport.options.LogBodies && response.Body != nil {
+			// read head of body and put it back to the response
+			head, _ := io.ReadAll(io.LimitReader(response.Body, int64(transport.options.MaxBodySize)+1))
+			attrs = append(attrs, slog.String("response_body", transport.truncate(head)))
+			response.Body = &multiReadCloser{Reader: io.MultiReader(bytes.NewReader(head), response.Body), Closer: response.Body}
+		}
+	}
+
+	transport.logger.LogAttrs(request.Context(), level, transport.options.Message, attrs...)
+	return response, err
+}
+
+func (transport *Transport) readBody(body io.Reader) string {
+	head, _ := io.ReadAll(io.LimitReader(body, int64(transport.options.MaxBodySize)+1))
+	return transport.truncate(head)
+}
+
+func (transport *Transport) truncate(body []byte) string {
+	if len(body) > transport.options.MaxBodySize {
+		return string(body[:transport.options.MaxBodySize]) + "..."
+	}
+	return string(body)
+}
+
+type multiReadCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,39 @@
+package httplog
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mikiepure/nslog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(append([]byte("echo:"), body...))
+	}))
+	defer server.Close()
+
+	buf := new(bytes.Buffer)
+	client := &http.Client{Transport: NewTransport(nil, nslog.NewLogger(buf, nil), &TransportOptions{LogBodies: true, MaxBodySize: 8})}
+	response, err := client.Post(server.URL+"/path?q=1", "text/plain", strings.NewReader("hello"))
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+
+	assert.Equal(t, "echo:hello", string(body))
+	assert.Regexp(t, "INFO\\. http client request method=POST url=http://.+/path\\?q=1 request_body=hello latency=.+ status=200 response_body=echo:hel\\.\\.\\.\n", buf.String())
+}
+
+func TestTransportError(t *testing.T) {
+	buf := new(bytes.Buffer)
+	client := &http.Client{Transport: NewTransport(nil, nslog.NewLogger(buf, nil), nil)}
+	_, err := client.Get("http://127.0.0.1:0/")
+	assert.Error(t, err)
+	assert.Regexp(t, "ERROR http client request method=GET url=http://127\\.0\\.0\\.1:0/ latency=.+ error=.+", buf.String())
+}
@@ -0,0 +1,244 @@
+package nslog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Read lines of the slog JSON format written by [slog.JSONHandler] from the reader and output them by the handler until EOF.
+// Time, level, and message are restored as fields of the record, and the source is added as "source" attribute
+// like [nslog.CollectPipe]. Nested objects are restored as groups.
+// Lines which are not JSON objects are output as messages of Info level.
+func ConvertJSON(reader io.Reader, handler slog.Handler) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), PIPE_MAX_ENTRY_SIZE)
+	for scanner.Scan() {
+		err := convertJSONLine(handler, scanner.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// A writer to output lines of the slog JSON format by the handler, such as stdout of a child process.
+type JSONConverter struct {
+	handler slog.Handler
+	mutex   sync.Mutex
+	buffer  []byte
+}
+
+// Create a new [nslog.JSONConverter] object. Call [JSONConverter.Close] to output the last line without newline.
+func NewJSONConverter(handler slog.Handler) *JSONConverter {
+	return &JSONConverter{handler: handler}
+}
+
+// Output complete lines of the bytes by the handler. The rest of the bytes is kept until newline is written.
+func (converter *JSONConverter) Write(p []byte) (int, error) {
+	converter.mutex.Lock()
+	defer converter.mutex.Unlock()
+
+	converter.buffer = append(converter.buffer, p...)
+	for {
+		index := bytes.IndexByte(converter.buffer, '\n')
+		if index < 0 {
+			break
+		}
+		line := converter.buffer[:index]
+		converter.buffer = converter.buffer[index+1:]
+		err := convertJSONLine(converter.handler, line)
+		if err != nil {
+			return len(p), err
+		}
+	}
+	return len(p), nil
+}
+
+// Output the last line without newline by the handler.
+func (converter *JSONConverter) Close() error {
+	converter.mutex.Lock()
+	defer converter.mutex.Unlock()
+
+	line := converter.buffer
+	converter.buffer = nil
+	if len(bytes.TrimSpace(line)) == 0 {
+		return nil
+	}
+	return convertJSONLine(converter.handler, line)
+}
+
+func convertJSONLine(handler slog.Handler, line []byte) error {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	if len(bytes.TrimSpace(line)) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	record, source, err := decodeJSONRecord(line)
+	if err != nil {
+		// output the line as is
+		record = slog.NewRecord(time.Now(), slog.LevelInfo, string(line), 0)
+	}
+	if !handler.Enabled(ctx, record.Level) {
+		return nil
+	}
+	if source != "" {
+		// the source is not qualified by groups
+		ctx = AddContextAttrs(ctx, slog.String("source", source))
+	}
+	return handler.Handle(ctx, record)
+}
+
+// Parse a line of the slog JSON format written by [slog.JSONHandler] in the same way as [nslog.ConvertJSON],
+// where attributes in groups are flattened with keys qualified by groups such as "req.method".
+func ParseJSON(line string) (*ParsedRecord, error) {
+	record, source, err := decodeJSONRecord([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+	parsed := &ParsedRecord{
+		Time:    record.Time,
+		Level:   record.Level,
+		Message: record.Message,
+		Source:  source,
+	}
+	record.Attrs(func(attribute slog.Attr) bool {
+		parsed.Attrs = appendFlatAttrs(parsed.Attrs, "", attribute)
+		return true
+	})
+	return parsed, nil
+}
+
+// Append the attribute with string value, where attributes in the group are flattened with keys qualified by the group.
+func appendFlatAttrs(attrs []slog.Attr, qualifier string, attribute slog.Attr) []slog.Attr {
+	if attribute.Value.Kind() != slog.KindGroup {
+		return append(attrs, slog.String(qualifier+attribute.Key, attribute.Value.String()))
+	}
+	for _, member := range attribute.Value.Group() {
+		attrs = appendFlatAttrs(attrs, qualifier+attribute.Key+".", member)
+	}
+	return attrs
+}
+
+// Decode a line of the slog JSON format into a record and the source such as "main.go:12".
+func decodeJSONRecord(line []byte) (slog.Record, string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	token, err := decoder.Token()
+	if err != nil {
+		return slog.Record{}, "", err
+	}
+	if token != json.Delim('{') {
+		return slog.Record{}, "", errors.New("nslog: json line is not an object")
+	}
+
+	var recordTime time.Time
+	var level = slog.LevelInfo
+	var message, source string
+	var attrs []slog.Attr
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return slog.Record{}, "", err
+		}
+		key, _ := token.(string)
+		value, err := decodeJSONValue(decoder)
+		if err != nil {
+			return slog.Record{}, "", err
+		}
+
+		switch {
+		case key == slog.TimeKey && value.Kind() == slog.KindString:
+			recordTime, _ = time.Parse(time.RFC3339Nano, value.String())
+		case key == slog.LevelKey && value.Kind() == slog.KindString:
+			if parsed, err := ParseLevel(value.String()); err == nil {
+				level = parsed
+			}
+		case key == slog.MessageKey && value.Kind() == slog.KindString:
+			message = value.String()
+		case key == slog.SourceKey && value.Kind() == slog.KindGroup:
+			var file string
+			var line int64
+			for _, attribute := range value.Group() {
+				switch attribute.Key {
+				case "file":
+					file = attribute.Value.String()
+				case "line":
+					line = attribute.Value.Int64()
+				}
+			}
+			if file != "" {
+				source = filepath.Base(file) + ":" + strconv.FormatInt(line, 10)
+			}
+		default:
+			attrs = append(attrs, slog.Attr{Key: key, Value: value})
+		}
+	}
+	_, err = decoder.Token()
+	if err != nil {
+		return slog.Record{}, "", err
+	}
+
+	record := slog.NewRecord(recordTime, level, message, 0)
+	record.AddAttrs(attrs...)
+	return record, source, nil
+}
+
+// Decode the next JSON value, where objects are decoded as groups to keep order of keys.
+func decodeJSONValue(decoder *json.Decoder) (slog.Value, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return slog.Value{}, err
+	}
+	switch token := token.(type) {
+	case json.Delim:
+		if token == '{' {
+			var attrs []slog.Attr
+			for decoder.More() {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return slog.Value{}, err
+				}
+				key, _ := keyToken.(string)
+				value, err := decodeJSONValue(decoder)
+				if err != nil {
+					return slog.Value{}, err
+				}
+				attrs = append(attrs, slog.Attr{Key: key, Value: value})
+			}
+			_, err = decoder.Token()
+			return slog.GroupValue(attrs...), err
+		}
+		values := []any{}
+		for decoder.More() {
+			value, err := decodeJSONValue(decoder)
+			if err != nil {
+				return slog.Value{}, err
+			}
+			values = append(values, value.Any())
+		}
+		_, err = decoder.Token()
+		return slog.AnyValue(values), err
+	case json.Number:
+		if i, err := token.Int64(); err == nil {
+			return slog.Int64Value(i), nil
+		}
+		f, err := token.Float64()
+		return slog.Float64Value(f), err
+	case string:
+		return slog.StringValue(token), nil
+	case bool:
+		return slog.BoolValue(token), nil
+	default:
+		return slog.AnyValue(nil), nil
+	}
+}
@@ -0,0 +1,61 @@
+package nslog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertJSON(t *testing.T) {
+	input := new(bytes.Buffer)
+	log := slog.New(slog.NewJSONHandler(input, &slog.HandlerOptions{AddSource: true, Level: slog.LevelDebug}))
+	log.WithGroup("Main").Warn("log message1", "key1", "val1", "key2", 2, "key3", 1.5, "key4", true)
+	log.Debug("log message2", slog.Group("req", "method", "GET", "ids", []int{1, 2}))
+	input.WriteString("not json\n")
+
+	buf := new(bytes.Buffer)
+	err := ConvertJSON(input, NewLogHandler(buf, &LogHandlerOptions{Level: slog.LevelInfo}))
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 2)
+	assert.Regexp(t, DEFAULT_TIME_REGEXP+" WARN\\. log message1 Main\\.key1=val1 Main\\.key2=2 Main\\.key3=1\\.5 Main\\.key4=true source=json_converter_test\\.go:[0-9]+$", lines[0])
+	assert.Regexp(t, DEFAULT_TIME_REGEXP+" INFO\\. not json$", lines[1])
+}
+
+func TestConvertJSONGroup(t *testing.T) {
+	buf := new(bytes.Buffer)
+	input := `{"time":"2024-10-31T11:22:33.123Z","level":"DEBUG+2","msg":"log message","req":{"method":"GET","ids":[1,2]},"nil":null}` + "\n"
+	err := ConvertJSON(strings.NewReader(input), NewLogHandler(buf, &LogHandlerOptions{Level: slog.LevelDebug, UseUTC: true}))
+	assert.NoError(t, err)
+	assert.Equal(t, "2024/10/31 11:22:33 UNSET log message req.method=GET req.ids=[1 2] nil=<nil>\n", buf.String())
+}
+
+func TestParseJSON(t *testing.T) {
+	record, err := ParseJSON(`{"time":"2024-10-31T11:22:33.123Z","level":"WARN","msg":"log message","source":{"function":"main.main","file":"/src/main.go","line":12},"req":{"method":"GET","id":1}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 10, 31, 11, 22, 33, 123000000, time.UTC), record.Time)
+	assert.Equal(t, slog.LevelWarn, record.Level)
+	assert.Equal(t, "log message", record.Message)
+	assert.Equal(t, "main.go:12", record.Source)
+	assert.Equal(t, []slog.Attr{slog.String("req.method", "GET"), slog.String("req.id", "1")}, record.Attrs)
+
+	_, err = ParseJSON("not json")
+	assert.Error(t, err)
+}
+
+func TestJSONConverter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	converter := NewJSONConverter(NewLogHandler(buf, nil))
+	_, err := converter.Write([]byte(`{"time":"2024-10-31T11:22:33Z","level":"INFO","msg":"log `))
+	assert.NoError(t, err)
+	assert.Equal(t, "", buf.String())
+	_, err = converter.Write([]byte("message1\"}\n{\"level\":\"ERROR\",\"msg\":\"log message2\"}"))
+	assert.NoError(t, err)
+	assert.Regexp(t, DEFAULT_TIME_REGEXP+" INFO\\. log message1\n$", buf.String())
+	assert.NoError(t, converter.Close())
+	assert.Regexp(t, "\nERROR log message2\n$", buf.String())
+}
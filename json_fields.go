@@ -0,0 +1,117 @@
+package nslog
+
+import (
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// Attributes and groups of a handler to convert records to JSON objects, where groups are nested objects like slog.JSONHandler.
+type jsonAttrs struct {
+	attrs  [][]slog.Attr // attributes for each depth of groups
+	groups []string
+}
+
+func (fields jsonAttrs) withAttrs(attrs []slog.Attr) jsonAttrs {
+	if len(attrs) == 0 {
+		return fields
+	}
+	if len(fields.attrs) == 0 {
+		fields.attrs = [][]slog.Attr{nil}
+	}
+	fields.attrs = slices.Clone(fields.attrs)
+	depth := len(fields.attrs) - 1
+	fields.attrs[depth] = append(slices.Clip(fields.attrs[depth]), attrs...)
+	return fields
+}
+
+func (fields jsonAttrs) withGroup(name string) jsonAttrs {
+	if name == "" {
+		return fields
+	}
+	if len(fields.attrs) == 0 {
+		fields.attrs = [][]slog.Attr{nil}
+	}
+	fields.attrs = append(slices.Clip(fields.attrs), nil)
+	fields.groups = append(slices.Clip(fields.groups), name)
+	return fields
+}
+
+// Get an object of attributes of the handler and the record, which does not have empty groups.
+func (fields jsonAttrs) object(record slog.Record) jsonObject {
+	root := jsonObject{}
+	object := root
+	for depth, attrs := range fields.attrs {
+		if depth > 0 {
+			group := jsonObject{}
+			object[fields.groups[depth-1]] = group
+			object = group
+		}
+		for _, attribute := range attrs {
+			addJSONField(object, attribute)
+		}
+	}
+	record.Attrs(func(attribute slog.Attr) bool {
+		addJSONField(object, attribute)
+		return true
+	})
+	removeEmptyGroups(root)
+	return root
+}
+
+// An object of attributes, which is distinguished from map values of attributes to remove empty groups.
+type jsonObject map[string]any
+
+func addJSONField(fields jsonObject, attribute slog.Attr) {
+	attribute.Value = attribute.Value.Resolve()
+	if attribute.Equal(slog.Attr{}) {
+		return
+	}
+	if attribute.Value.Kind() == slog.KindGroup {
+		// attributes of the group with empty key are inlined
+		group := fields
+		if attribute.Key != "" {
+			group = jsonObject{}
+			fields[attribute.Key] = group
+		}
+		for _, groupAttribute := range attribute.Value.Group() {
+			addJSONField(group, groupAttribute)
+		}
+		return
+	}
+	fields[attribute.Key] = jsonValue(attribute.Value)
+}
+
+func jsonValue(value slog.Value) any {
+	switch value.Kind() {
+	case slog.KindTime:
+		return value.Time().Format(time.RFC3339Nano)
+	case slog.KindDuration:
+		return value.Duration().String()
+	case slog.KindAny:
+		switch v := value.Any().(type) {
+		case error:
+			return v.Error()
+		case json.Marshaler:
+			return v
+		}
+		// values which cannot be marshaled are output as strings
+		if _, err := json.Marshal(value.Any()); err != nil {
+			return value.String()
+		}
+		return value.Any()
+	default:
+		return value.Any()
+	}
+}
+
+// Remove groups without fields like slog.JSONHandler.
+func removeEmptyGroups(fields jsonObject) bool {
+	for key, field := range fields {
+		if group, ok := field.(jsonObject); ok && !removeEmptyGroups(group) {
+			delete(fields, key)
+		}
+	}
+	return len(fields) > 0
+}
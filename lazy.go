@@ -0,0 +1,21 @@
+package nslog
+
+import "log/slog"
+
+// A value computed by the function when it is resolved.
+type lazyValue func() any
+
+func (f lazyValue) LogValue() slog.Value {
+	return slog.AnyValue(f())
+}
+
+// Get the value computed by the function only when the record is output, so expensive attributes such as
+// big serializations and DB lookups cost nothing if the level is disabled or the record is dropped by filters:
+//
+//	logger.Debug("request received", "dump", nslog.Lazy(func() any { return dump(request) }))
+//
+// The value is resolved when the record is formatted by [nslog.LogHandler],
+// and when the handler is created for attributes added by With.
+func Lazy(f func() any) slog.LogValuer {
+	return lazyValue(f)
+}
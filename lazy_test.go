@@ -0,0 +1,44 @@
+package nslog
+
+import (
+	"bytes"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLazy(t *testing.T) {
+	calls := 0
+	dump := Lazy(func() any {
+		calls++
+		return "dumped"
+	})
+
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true})
+	log.Debug("debug message", "dump", dump)
+	assert.Equal(t, 0, calls)
+	log.Info("info message", "dump", dump)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "INFO. info message dump=dumped\n", buf.String())
+}
+
+func TestLazyFilter(t *testing.T) {
+	calls := 0
+	dump := Lazy(func() any {
+		calls++
+		return 42
+	})
+
+	buf := new(bytes.Buffer)
+	handler := NewFilterHandler(NewLogHandler(buf, &LogHandlerOptions{OmitTime: true}), &FilterHandlerOptions{Message: regexp.MustCompile("^keep"), RequiredAttrs: map[string]string{"id": "1"}})
+	log := slog.New(handler)
+	log.Info("drop message", "dump", dump, "id", 1)
+	log.Info("keep message", "dump", dump, "id", 2)
+	assert.Equal(t, 0, calls)
+	log.Info("keep message", "dump", dump, "id", 1)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "INFO. keep message dump=42 id=1\n", buf.String())
+}
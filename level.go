@@ -0,0 +1,35 @@
+package nslog
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// Parse level from string such as "ERROR", "WARN", "INFO", "DEBUG" (case-insensitive),
+// numeric value such as "-4", or name with offset such as "DEBUG-4" and "INFO+2".
+// "WARNING" is also accepted as "WARN", and "FATAL" is accepted as [nslog.LevelFatal].
+func ParseLevel(s string) (slog.Level, error) {
+	s = strings.TrimSpace(s)
+	number, err := strconv.Atoi(s)
+	if err == nil {
+		return slog.Level(number), nil
+	}
+
+	name := strings.ToUpper(s)
+	if strings.HasPrefix(name, "WARNING") {
+		name = "WARN" + name[len("WARNING"):]
+	}
+	var offset slog.Level
+	if strings.HasPrefix(name, "FATAL") {
+		name = "ERROR" + name[len("FATAL"):]
+		offset = LevelFatal - slog.LevelError
+	}
+	var level slog.Level
+	err = level.UnmarshalText([]byte(name))
+	if err != nil {
+		return 0, fmt.Errorf("nslog: invalid level %q", s)
+	}
+	return level + offset, nil
+}
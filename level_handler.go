@@ -0,0 +1,48 @@
+package nslog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// A handler whose Enabled uses its own minimum level, and which delegates everything else to the next handler.
+type LevelHandler struct {
+	next  slog.Handler
+	level slog.Leveler
+}
+
+// Create a new [nslog.LevelHandler] object, which gives the handler its own threshold such as for one noisy subsystem
+// when fanning out. The level of the next handler is not checked, so the threshold can be lowered as well as raised.
+func WithLevel(next slog.Handler, level slog.Leveler) *LevelHandler {
+	// unwrap to avoid nesting level handlers
+	if handler, ok := next.(*LevelHandler); ok {
+		next = handler.next
+	}
+
+	return &LevelHandler{
+		next:  next,
+		level: level,
+	}
+}
+
+func (handler *LevelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= handler.level.Level()
+}
+
+func (handler *LevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LevelHandler{
+		next:  handler.next.WithAttrs(attrs),
+		level: handler.level,
+	}
+}
+
+func (handler *LevelHandler) WithGroup(name string) slog.Handler {
+	return &LevelHandler{
+		next:  handler.next.WithGroup(name),
+		level: handler.level,
+	}
+}
+
+func (handler *LevelHandler) Handle(ctx context.Context, record slog.Record) error {
+	return handler.next.Handle(ctx, record)
+}
@@ -0,0 +1,27 @@
+package nslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelHandler(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := slog.New(WithLevel(NewLogHandler(buf, nil), slog.LevelWarn)).WithGroup("Main").With("key1", "val1")
+	log.Info("log message1")
+	log.Warn("log message2")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" WARN\\. Main\\[key1=val1\\]: log message2 \\(.+\\)\n$", buf.String())
+}
+
+func TestLevelHandlerLower(t *testing.T) {
+	buf := new(bytes.Buffer)
+	handler := WithLevel(WithLevel(NewLogHandler(buf, nil), slog.LevelError), slog.LevelDebug)
+	assert.IsType(t, &LogHandler{}, handler.next)
+	assert.True(t, handler.Enabled(context.Background(), slog.LevelDebug))
+	slog.New(handler).Debug("log message")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" DEBUG log message\n$", buf.String())
+}
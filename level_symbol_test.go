@@ -0,0 +1,41 @@
+package nslog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelSymbolPrefix(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{LevelSymbol: LevelSymbolPrefix, Level: slog.LevelDebug, OmitTime: true, AddSourceLevel: slog.LevelError + 1})
+	log.Error("message1")
+	log.Debug("message2")
+	assert.Equal(t, "✖ ERROR message1\n🐞 DEBUG message2\n", buf.String())
+}
+
+func TestLevelSymbolReplace(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{LevelSymbol: LevelSymbolReplace, LevelSymbols: map[slog.Level]string{slog.LevelInfo: "💡"}, OmitTime: true, AddSourceLevel: slog.LevelError + 1})
+	log.Info("message1")
+	log.Warn("message2")
+	assert.Equal(t, "💡 message1\nWARN. message2\n", buf.String())
+}
+
+func TestLevelSymbolPadLevel(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{LevelSymbol: LevelSymbolReplace, PadLevel: true, AddColor: true, Level: slog.LevelDebug, OmitTime: true})
+	log.Info("message1")
+	log.Debug("message2")
+	assert.Equal(t, "\x1b[92mℹ\x1b[0m  message1\n\x1b[96m🐞\x1b[0m message2\n", buf.String())
+}
+
+func TestLevelSymbolEnv(t *testing.T) {
+	t.Setenv("GO_NSLOG_LEVEL_SYMBOL", "PREFIX")
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true})
+	log.Info("log message")
+	assert.Equal(t, "ℹ INFO. log message\n", buf.String())
+}
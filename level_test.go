@@ -0,0 +1,42 @@
+package nslog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLevel(t *testing.T) {
+	for input, expected := range map[string]slog.Level{
+		"ERROR":   slog.LevelError,
+		"warn":    slog.LevelWarn,
+		"Warning": slog.LevelWarn,
+		"info":    slog.LevelInfo,
+		"DEBUG":   slog.LevelDebug,
+		"DEBUG-4": slog.LevelDebug - 4,
+		"info+2":  slog.LevelInfo + 2,
+		"-8":      slog.Level(-8),
+		" 12 ":    slog.Level(12),
+	} {
+		level, err := ParseLevel(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, level, input)
+	}
+
+	for _, input := range []string{"", "VERBOSE", "INFO+", "1.5"} {
+		_, err := ParseLevel(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestLevelEnv(t *testing.T) {
+	t.Setenv("GO_NSLOG_LEVEL", "debug-4")
+	t.Setenv("GO_NSLOG_ADD_SOURCE_LEVEL", "error")
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, nil)
+	log.Log(nil, slog.LevelDebug-4, "log message1")
+	log.Warn("log message2")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" UNSET log message1\n"+DEFAULT_TIME_REGEXP+" WARN\\. log message2\n$", buf.String())
+}
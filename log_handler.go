@@ -7,38 +7,360 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"os"
-	"path/filepath"
+	"path"
 	"runtime"
 	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/fatih/color"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 const DEFAULT_LEVEL = slog.LevelInfo
 const DEFAULT_TIME_LAYOUT = "2006/01/02 15:04:05"
+
+// Presets of TimeLayout option.
+const (
+	TIME_LAYOUT_MILLIS       = "2006/01/02 15:04:05.000"
+	TIME_LAYOUT_MICROS       = "2006/01/02 15:04:05.000000"
+	TIME_LAYOUT_RFC3339      = time.RFC3339
+	TIME_LAYOUT_RFC3339_NANO = time.RFC3339Nano
+)
 const DEFAULT_SOURCE_LEVEL = slog.LevelWarn
 
+// Default separators of log lines.
+const (
+	DEFAULT_FIELD_SEPARATOR     = " "
+	DEFAULT_KEY_VALUE_SEPARATOR = "="
+	DEFAULT_ATTRS_OPEN          = "["
+	DEFAULT_ATTRS_CLOSE         = "]"
+	DEFAULT_GROUP_SEPARATOR     = "."
+)
+const DEFAULT_ENV_PREFIX = "GO_NSLOG_"
+const DEFAULT_LINE_ENDING = "\n"
+
+// A format of trace ID and span ID of OpenTelemetry span.
+type TraceFormat int
+
+const (
+	TraceFormatAttrs  TraceFormat = iota // Add trace_id and span_id attributes.
+	TraceFormatSuffix                    // Add compact suffix such as "[trace_id/span_id]".
+	TraceFormatNone                      // Do not add trace ID and span ID.
+)
+
+// A style of level label.
+type LevelStyle int
+
+const (
+	LevelStyleDotted    LevelStyle = iota // "ERROR", "WARN.", "INFO.", and "DEBUG"
+	LevelStylePadded                      // "ERROR", "WARN ", "INFO ", and "DEBUG"
+	LevelStyleShort                       // "E", "W", "I", and "D"
+	LevelStyleBracketed                   // "[ERROR]", "[WARN]", "[INFO]", and "[DEBUG]"
+)
+
+// A way to show symbols of levels such as "✖".
+type LevelSymbol int
+
+const (
+	LevelSymbolNone    LevelSymbol = iota // do not show symbols
+	LevelSymbolPrefix                     // add symbols before labels such as "✖ ERROR"
+	LevelSymbolReplace                    // replace labels with symbols such as "✖"
+)
+
+// Default symbols of levels used by LevelSymbol option.
+var DEFAULT_LEVEL_SYMBOLS = map[slog.Level]string{
+	LevelFatal:      "💀",
+	slog.LevelError: "✖",
+	slog.LevelWarn:  "⚠",
+	slog.LevelInfo:  "ℹ",
+	slog.LevelDebug: "🐞",
+}
+
+// A format of log lines.
+type OutputFormat int
+
+const (
+	FormatText    OutputFormat = iota // "2024/10/31 11:22:33 INFO. message key=value"
+	FormatLTSV                        // "time:2024/10/31 11:22:33<TAB>level:INFO<TAB>msg:message<TAB>key:value" (Labeled Tab-Separated Values)
+	FormatGlog                        // "I1031 11:22:33.123456   12345 main.go:19] message key=value" (header of glog and klog)
+	FormatCompact                     // "11:22:33 INF message    key=value" (compact style like ConsoleWriter of zerolog)
+)
+
+// A way to handle a field exceeding the width of the terminal.
+type Overflow int
+
+const (
+	OverflowNone     Overflow = iota // keep the field as is
+	OverflowWrap                     // wrap the field onto continuation lines indented by tab
+	OverflowTruncate                 // truncate the field with an ellipsis "…"
+)
+
 type LogHandler struct {
 	options LogHandlerOptions
-	attrs   []slog.Attr
+	attrs   [][]slog.Attr // attributes added by WithAttrs, which are indexed by number of groups opened at that time
 	groups  []string
 	mutex   *sync.Mutex
 	writer  io.Writer
+	levels  map[slog.Level]string // level strings prepared on creation because coloring per record is costly
+	colors  fieldColors           // colors of fields, which are nil if color is not added
+	pid     string                // pid string prepared on creation because os.Getpid is a system call
+	state   *handlerState         // state shared with derived handlers for elapsed time, delta time, and sequence
+	meta    []slog.Attr           // hostname, service name, and version prepared on creation
+	skip    int                   // number of frames to skip for source
+	batch   *batchWriter          // writer to coalesce lines by BatchWrite option, which is nil if the option is false
+	live    *progressState        // state of progress line by LiveProgress option, which is nil if the option is false or the writer is not a terminal
+	level   slog.Leveler          // level of the groups by GroupLevels option, which is nil if no group matches
+	width   int                   // width of the terminal by AlignSource, MessageOverflow, and AttrsOverflow options, which is 0 if it is not used
+}
+
+type handlerState struct {
+	start    time.Time
+	last     atomic.Int64 // time of the previous record in unix nanoseconds
+	sequence atomic.Uint64
+	stats    handlerStats
+	runtime  atomic.Int64 // time of the previous snapshot of runtime stats in unix nanoseconds
 }
 
 // An option to customize output of log message.
 type LogHandlerOptions struct {
-	Level          slog.Leveler // Set level to output log message. By default, Error, Warn, and Info logs are output.
-	AddColor       bool         // Add console color for level if it is true. (default: false)
-	TimeLayout     string       // Set own time layout for [Time.Format]. (default: "2006/01/02 15:04:05")
-	AddPID         bool         // Add PID as hex string if it is true. (default: false)
-	AddGoroutineID bool         // Add Goroutine ID as hex string if it is true. (default: false)
+	Level         slog.Leveler // Set level to output log message. By default, Error, Warn, and Info logs are output.
+	AddColor      bool         // Add console color for level if it is true. (default: false)
+	ColorMode     ColorMode    // Set mode to add color. ColorModeAuto adds color only if the writer is a terminal. (default: ColorModeDefault, which follows AddColor)
+	Theme         *Theme       // Set colors for levels and fields used when AddColor is true. (default: DEFAULT_THEME)
+	ColorTime     bool         // Add color for time (dim by default) if it is true and AddColor is true. (default: false)
+	ColorAttrKeys bool         // Add color for keys of attributes (cyan by default) if it is true and AddColor is true. (default: false)
+	ColorSource   bool         // Add color for source (magenta by default) if it is true and AddColor is true. (default: false)
+	LevelStyle    LevelStyle   // Set style of level label. (default: LevelStyleDotted)
+	LevelSymbol   LevelSymbol  // Set way to show symbols of levels such as LevelSymbolPrefix for "✖ ERROR", which is colored and padded with the label. (default: LevelSymbolNone)
+	LineEnding    string       // Set line ending such as "\r\n" for Windows-centric toolchains and protocols requiring CRLF. (default: "\n")
+	Format        OutputFormat // Set format of log lines such as FormatLTSV. Color is not added except for FormatText. (default: FormatText)
+	PadLevel      bool         // Pad labels of levels with spaces to the same width such as "WARN " if it is true, which also applies to LevelLabels. (default: false)
+	MessageColumn int          // Set column to start message such as 40, so messages are aligned regardless of width of time, groups, and so on. (default: 0, which means no alignment)
+	AlignSource   bool         // Right-align source at the width of the terminal if it is true. The writer must be a terminal unless TerminalWidth is set. (default: false)
+	TerminalWidth int          // Set width of the terminal, which is used even if the writer is not a terminal. (default: 0, which uses COLUMNS environment variable or size of the terminal)
+
+	// Set own labels of levels, which take precedence over LevelStyle.
+	// Levels not in the map use the label of LevelStyle. (default: nil)
+	LevelLabels map[slog.Level]string
+
+	// Set own symbols of levels used by LevelSymbol option such as {slog.LevelError: "🔥"}.
+	// Levels not in the map have no symbol. (default: nil, which uses DEFAULT_LEVEL_SYMBOLS)
+	LevelSymbols map[slog.Level]string
+
+	// Set how message and attributes of the text format exceeding the width of the terminal are handled, such as OverflowWrap to wrap
+	// attributes onto continuation lines indented by tab, or OverflowTruncate to truncate them with an ellipsis. (default: OverflowNone)
+	MessageOverflow Overflow
+	AttrsOverflow   Overflow
+
+	// Set separators to reproduce legacy formats for existing tools such as grep and awk. FieldSeparator separates fields and attributes,
+	// and AttrsOpen and AttrsClose enclose attributes added by With of the text format. KeyValueSeparator separates keys and values,
+	// and GroupSeparator joins groups of keys of all formats. (default: DEFAULT_FIELD_SEPARATOR, DEFAULT_KEY_VALUE_SEPARATOR,
+	// DEFAULT_ATTRS_OPEN, DEFAULT_ATTRS_CLOSE, and DEFAULT_GROUP_SEPARATOR, which are used if they are empty)
+	FieldSeparator    string
+	KeyValueSeparator string
+	AttrsOpen         string
+	AttrsClose        string
+	GroupSeparator    string
+
+	// Set levels per name of groups joined by "." such as {"db": slog.LevelWarn, "db.migrations": slog.LevelDebug}, which take
+	// precedence over Level. The longest name matched with groups of WithGroup is used, like category levels of log4j. (default: nil)
+	GroupLevels map[string]slog.Leveler
+
+	TimeLayout  string // Set own time layout for [Time.Format]. Presets such as TIME_LAYOUT_MILLIS are available. (default: "2006/01/02 15:04:05")
+	UseUTC      bool   // Output time in UTC if it is true. Output time in local time zone if it is false. (default: false)
+	OmitTime    bool   // Omit wall-clock time if it is true, which is useful with AddElapsed or AddDelta. (default: false)
+	AddElapsed  bool   // Add elapsed time since creation of the handler such as "+1.234s" if it is true. (default: false)
+	AddDelta    bool   // Add delta time since the previous record such as "+12ms" if it is true. (default: false)
+	AddSequence bool   // Add sequence number such as "#42" incremented atomically per record, which is shared with derived handlers. (default: false)
+	AddPID      bool   // Add PID as hex string if it is true. (default: false)
+
+	// Add Goroutine ID as hex string if it is true. (default: false)
+	//
+	// Deprecated: The ID is parsed from runtime.Stack for each record, which takes about 10 microseconds growing
+	// with depth of the stack, and it cannot be cached since a handler is shared by goroutines.
+	// Use PprofLabels option or [nslog.AddContextAttrs] to identify requests and workers at low cost.
+	AddGoroutineID bool
+
 	AddSourceLevel slog.Leveler // Set level to output log source, which is the file and line number that called the function. By default, Error and Warn logs are output with source.
 	SourceFilePath bool         // Use filepath for source if it is true. Use filename for source if it is false.
+	SourceModule   bool         // Use filepath relative to the module root such as "pkg/http/server.go" for source if it is true. It takes precedence over SourceFilePath. (default: false)
+	SourceFunction bool         // Add function name such as "http.(*Server).Serve" to source if it is true. (default: false)
+	SourceLink     string       // Set URL template to make source a terminal hyperlink (OSC 8) when AddColor is true, such as SOURCE_LINK_VSCODE. (default: "", which adds no link)
+	TraceFormat    TraceFormat  // Set format of trace ID and span ID of OpenTelemetry span in the context. (default: TraceFormatAttrs)
+	AddHostname    bool         // Add hostname as "host" attribute if it is true. (default: false)
+	ServiceName    string       // Add service name as "service" attribute if it is not empty. (default: "")
+	ServiceVersion string       // Add service version as "version" attribute if it is not empty. (default: "")
+	MetaHeader     bool         // Output hostname, service name, and version once as a header record on creation instead of every record if it is true. (default: false)
+	AddBanner      bool         // Output a startup banner record with PID, executable path, Go version, VCS revision, and configuration on creation if it is true. (default: false)
+
+	// Set function to format source instead of "(file:line)". The source is omitted if the function returns empty string. (default: nil)
+	SourceFormatter func(frame runtime.Frame) string
+
+	// Add labels of pprof in the context set by [pprof.Do] or [pprof.WithLabels] as attributes such as "worker=3",
+	// which identify requests and workers stably and also show up in profiles, unlike AddGoroutineID.
+	// Set keys of labels to add, or "*" to add all labels sorted by key. (default: nil)
+	PprofLabels []string
+
+	// Output chain of wrapped errors, or "%+v" of the error such as stack trace of pkg/errors,
+	// on continuation lines for error attributes if it is true. (default: false)
+	ExpandErrors bool
+
+	// Set glob patterns of keys for [path.Match] to remove attributes of the handler and the record such as "*password".
+	// Keys are matched as output, which are qualified by groups such as "group.key". (default: nil)
+	DropKeys []string
+
+	// Set glob patterns of keys for [path.Match] to keep only matched attributes of the handler and the record.
+	// All attributes are kept if it is empty. (default: nil)
+	KeepOnlyKeys []string
+
+	// Sort attributes of the record by key if it is true, so lines are stable regardless of order of arguments. (default: false)
+	SortAttrs bool
+
+	// Remove attributes of the record with repeated keys except the last one if it is true. (default: false)
+	DedupAttrs bool
+
+	// Set maximum size of payloads of [nslog.Payload] in bytes. The exceeded payload is truncated with a marker
+	// such as "…(truncated 12 bytes)". (default: DEFAULT_PAYLOAD_MAX_SIZE, and negative value means no limit)
+	PayloadMaxSize int
+
+	// Set maximum length of log line in bytes such as 2048 for syslog. The exceeded line is truncated with a marker such as
+	// "…(truncated 12 bytes)", where attributes are cut preferentially to keep the message.
+	// Continuation lines are not counted. (default: 0, which means no limit)
+	MaxLineLength int
+
+	// Set format of attribute values per kind such as durations, times, floats, byte slices, and integers. (default: nil, which uses [slog.Value.String])
+	ValueFormat *ValueFormat
+
+	// Coalesce lines of records logged concurrently into a single Write call if it is true,
+	// which reduces lock contention and system calls under load. Handle returns after the line is written. (default: false)
+	BatchWrite bool
+
+	// Set level to call Sync of the writer such as [os.File] and [nslog.FileWriter] after the record is written,
+	// so crash-adjacent lines are durably persisted before the process dies.
+	// Writers without Sync method are not synced. (default: nil, which never calls Sync)
+	SyncLevel slog.Leveler
+
+	// Rewrite the current line by records with [nslog.Progress] attribute instead of appending if it is true and the writer is a terminal,
+	// so long-running CLI tools can show live progress. The last progress line is kept when the next record is output. (default: false)
+	LiveProgress bool
+
+	// Set level to add a snapshot of runtime stats such as heap in use, number of goroutines, and GC pauses as "runtime" group
+	// to records at or above the level such as slog.LevelError, which gives context of OOM-adjacent failures from logs alone.
+	// Snapshots stop the world briefly, so they are added at most once per RuntimeStatsInterval. (default: nil, which never adds)
+	RuntimeStatsLevel    slog.Leveler
+	RuntimeStatsInterval time.Duration // Set minimum interval of snapshots shared with derived handlers. (default: DEFAULT_RUNTIME_STATS_INTERVAL)
+
+	// Count records by level, records dropped by hooks, and write errors if it is true,
+	// which are got by [LogHandler.Stats] or published by [LogHandler.PublishExpvar]. (default: false)
+	CollectStats bool
+
+	// Set hooks called before formatting and after writing records in order. (default: nil)
+	Hooks []Hook
+
+	// Set function to rewrite or remove attributes before output, same as [slog.HandlerOptions.ReplaceAttr].
+	// The attribute is removed if the function returns an attribute with empty key.
+	// Time, level, message, and source are not passed to the function. (default: nil)
+	ReplaceAttr func(groups []string, a slog.Attr) slog.Attr
+
+	// Set prefix of environment variables to override options such as "MYLIB_LOG_" for "MYLIB_LOG_LEVEL". (default: "GO_NSLOG_")
+	EnvPrefix string
+
+	// Do not override options by environment variables if it is true,
+	// so embedded uses are not reconfigured by environment of the host process. (default: false)
+	DisableEnv bool
+}
+
+// Get environment variable of the name with EnvPrefix option. It returns empty string if DisableEnv option is true.
+func (options *LogHandlerOptions) getenv(name string) string {
+	if options.DisableEnv {
+		return ""
+	}
+	prefix := options.EnvPrefix
+	if prefix == "" {
+		prefix = DEFAULT_ENV_PREFIX
+	}
+	return os.Getenv(prefix + name)
+}
+
+// Environment variables read by getenv, with the function to check the value, which is nil for any string.
+// It is used by [nslog.NewLogHandlerE] to report values ignored by [nslog.NewLogHandler], so a new variable should be added here.
+var envVars = []struct {
+	name     string
+	validate func(value string) error
+}{
+	{"LEVEL", validateEnvLevel},
+	{"GROUP_LEVELS", validateEnvGroupLevels},
+	{"ADD_COLOR", validateEnvAddColor},
+	{"COLOR_TIME", validateEnvBool},
+	{"COLOR_ATTR_KEYS", validateEnvBool},
+	{"COLOR_SOURCE", validateEnvBool},
+	{"THEME", validateEnvTheme},
+	{"TIME_LAYOUT", validateEnvTimeLayout},
+	{"USE_UTC", validateEnvBool},
+	{"OMIT_TIME", validateEnvBool},
+	{"ADD_ELAPSED", validateEnvBool},
+	{"ADD_DELTA", validateEnvBool},
+	{"ADD_SEQUENCE", validateEnvBool},
+	{"ADD_PID", validateEnvBool},
+	{"ADD_GOROUTINEID", validateEnvBool},
+	{"ADD_SOURCE_LEVEL", validateEnvLevel},
+	{"SOURCE_FILE_PATH", validateEnvBool},
+	{"SOURCE_MODULE", validateEnvBool},
+	{"SOURCE_FUNCTION", validateEnvBool},
+	{"SOURCE_LINK", validateEnvSourceLink},
+	{"EXPAND_ERRORS", validateEnvBool},
+	{"SORT_ATTRS", validateEnvBool},
+	{"DEDUP_ATTRS", validateEnvBool},
+	{"MAX_LINE_LENGTH", validateEnvInt},
+	{"PAYLOAD_MAX_SIZE", validateEnvInt},
+	{"DROP_KEYS", validateEnvKeyPatterns},
+	{"PPROF_LABELS", nil},
+	{"KEEP_ONLY_KEYS", validateEnvKeyPatterns},
+	{"TRACE_FORMAT", validateEnvTraceFormat},
+	{"LEVEL_STYLE", new(LevelStyle).Set},
+	{"LEVEL_SYMBOL", new(LevelSymbol).Set},
+	{"LINE_ENDING", nil},
+	{"FORMAT", new(OutputFormat).Set},
+	{"FIELD_SEPARATOR", validateEnvSeparator},
+	{"KEY_VALUE_SEPARATOR", validateEnvSeparator},
+	{"ATTRS_OPEN", validateEnvSeparator},
+	{"ATTRS_CLOSE", validateEnvSeparator},
+	{"GROUP_SEPARATOR", validateEnvSeparator},
+	{"ADD_HOSTNAME", validateEnvBool},
+	{"SERVICE_NAME", nil},
+	{"SERVICE_VERSION", nil},
+	{"ADD_BANNER", validateEnvBool},
+	{"META_HEADER", validateEnvBool},
+	{"BATCH_WRITE", validateEnvBool},
+	{"PAD_LEVEL", validateEnvBool},
+	{"MESSAGE_COLUMN", validateEnvInt},
+	{"TERMINAL_WIDTH", validateEnvInt},
+	{"MESSAGE_OVERFLOW", new(Overflow).Set},
+	{"ATTRS_OVERFLOW", new(Overflow).Set},
+	{"ALIGN_SOURCE", validateEnvBool},
+	{"LIVE_PROGRESS", validateEnvBool},
+	{"SYNC_LEVEL", validateEnvLevel},
+	{"RUNTIME_STATS_LEVEL", validateEnvLevel},
+	{"OUTPUT", validateEnvOutput},
+}
+
+// Convert [slog.HandlerOptions] to [nslog.LogHandlerOptions] for codebases constructing slog.HandlerOptions centrally.
+// AddSource is mapped to AddSourceLevel: source is output for all levels if it is true, or never output if it is false.
+func FromSlogHandlerOptions(options slog.HandlerOptions) LogHandlerOptions {
+	sourceLevel := slog.Level(math.MaxInt32)
+	if options.AddSource {
+		sourceLevel = slog.Level(math.MinInt32)
+	}
+	return LogHandlerOptions{
+		Level:          options.Level,
+		AddSourceLevel: sourceLevel,
+		ReplaceAttr:    options.ReplaceAttr,
+	}
 }
 
 // Create a new [slog.Logger] object that implements [nslog.LogHandler].
@@ -62,33 +384,146 @@ func NewLogHandler(writer io.Writer, options *LogHandlerOptions) *LogHandler {
 	if options.AddSourceLevel == nil {
 		options.AddSourceLevel = DEFAULT_SOURCE_LEVEL
 	}
+	if options.FieldSeparator == "" {
+		options.FieldSeparator = DEFAULT_FIELD_SEPARATOR
+	}
+	if options.KeyValueSeparator == "" {
+		options.KeyValueSeparator = DEFAULT_KEY_VALUE_SEPARATOR
+	}
+	if options.AttrsOpen == "" {
+		options.AttrsOpen = DEFAULT_ATTRS_OPEN
+	}
+	if options.AttrsClose == "" {
+		options.AttrsClose = DEFAULT_ATTRS_CLOSE
+	}
+	if options.GroupSeparator == "" {
+		options.GroupSeparator = DEFAULT_GROUP_SEPARATOR
+	}
+	if options.LineEnding == "" {
+		options.LineEnding = DEFAULT_LINE_ENDING
+	}
+	if options.PayloadMaxSize == 0 {
+		options.PayloadMaxSize = DEFAULT_PAYLOAD_MAX_SIZE
+	}
+	if options.RuntimeStatsInterval <= 0 {
+		options.RuntimeStatsInterval = DEFAULT_RUNTIME_STATS_INTERVAL
+	}
 
 	// override parameters by environment variables
-	switch os.Getenv("GO_NSLOG_LEVEL") {
-	case "ERROR":
-		options.Level = slog.LevelError
-	case "WARN":
-		options.Level = slog.LevelWarn
-	case "INFO":
-		options.Level = slog.LevelInfo
-	case "DEBUG":
-		options.Level = slog.LevelDebug
-	default:
-		// do not use environment variable for Level
+	nslogLevel, err := ParseLevel(options.getenv("LEVEL"))
+	if err == nil {
+		options.Level = nslogLevel
+	}
+	nslogGroupLevels := options.getenv("GROUP_LEVELS")
+	if nslogGroupLevels != "" {
+		options.GroupLevels = map[string]slog.Leveler{}
+		for _, groupLevel := range strings.Split(nslogGroupLevels, ",") {
+			name, value, _ := strings.Cut(groupLevel, "=")
+			level, err := ParseLevel(value)
+			if err == nil {
+				options.GroupLevels[name] = level
+			}
+		}
 	}
-	nslogAddColor := os.Getenv("GO_NSLOG_ADD_COLOR")
+	nslogAddColor := options.getenv("ADD_COLOR")
 	if strings.EqualFold(nslogAddColor, "false") || nslogAddColor == "0" {
 		options.AddColor = false
+		options.ColorMode = ColorModeDefault
 	} else if strings.EqualFold(nslogAddColor, "true") || nslogAddColor == "1" {
 		options.AddColor = true
+		options.ColorMode = ColorModeDefault
+	} else if strings.EqualFold(nslogAddColor, "auto") {
+		options.ColorMode = ColorModeAuto
 	} else {
 		// do not use environment variable for AddColor flag
 	}
-	nslogTimeLayout := os.Getenv("GO_NSLOG_TIME_LAYOUT")
-	if nslogTimeLayout != "" {
+	nslogColorTime := options.getenv("COLOR_TIME")
+	if strings.EqualFold(nslogColorTime, "false") || nslogColorTime == "0" {
+		options.ColorTime = false
+	} else if strings.EqualFold(nslogColorTime, "true") || nslogColorTime == "1" {
+		options.ColorTime = true
+	} else {
+		// do not use environment variable for ColorTime flag
+	}
+	nslogColorAttrKeys := options.getenv("COLOR_ATTR_KEYS")
+	if strings.EqualFold(nslogColorAttrKeys, "false") || nslogColorAttrKeys == "0" {
+		options.ColorAttrKeys = false
+	} else if strings.EqualFold(nslogColorAttrKeys, "true") || nslogColorAttrKeys == "1" {
+		options.ColorAttrKeys = true
+	} else {
+		// do not use environment variable for ColorAttrKeys flag
+	}
+	nslogColorSource := options.getenv("COLOR_SOURCE")
+	if strings.EqualFold(nslogColorSource, "false") || nslogColorSource == "0" {
+		options.ColorSource = false
+	} else if strings.EqualFold(nslogColorSource, "true") || nslogColorSource == "1" {
+		options.ColorSource = true
+	} else {
+		// do not use environment variable for ColorSource flag
+	}
+	nslogTheme := options.getenv("THEME")
+	if nslogTheme != "" {
+		theme, err := ParseTheme(nslogTheme)
+		if err == nil {
+			options.Theme = theme
+		}
+	}
+	nslogTimeLayout := options.getenv("TIME_LAYOUT")
+	switch nslogTimeLayout {
+	case "":
+		// do not use environment variable for TimeLayout
+	case "MILLIS":
+		options.TimeLayout = TIME_LAYOUT_MILLIS
+	case "MICROS":
+		options.TimeLayout = TIME_LAYOUT_MICROS
+	case "RFC3339":
+		options.TimeLayout = TIME_LAYOUT_RFC3339
+	case "RFC3339NANO":
+		options.TimeLayout = TIME_LAYOUT_RFC3339_NANO
+	default:
 		options.TimeLayout = nslogTimeLayout
 	}
-	nslogAddPID := os.Getenv("GO_NSLOG_ADD_PID")
+	nslogUseUTC := options.getenv("USE_UTC")
+	if strings.EqualFold(nslogUseUTC, "false") || nslogUseUTC == "0" {
+		options.UseUTC = false
+	} else if strings.EqualFold(nslogUseUTC, "true") || nslogUseUTC == "1" {
+		options.UseUTC = true
+	} else {
+		// do not use environment variable for UseUTC flag
+	}
+	nslogOmitTime := options.getenv("OMIT_TIME")
+	if strings.EqualFold(nslogOmitTime, "false") || nslogOmitTime == "0" {
+		options.OmitTime = false
+	} else if strings.EqualFold(nslogOmitTime, "true") || nslogOmitTime == "1" {
+		options.OmitTime = true
+	} else {
+		// do not use environment variable for OmitTime flag
+	}
+	nslogAddElapsed := options.getenv("ADD_ELAPSED")
+	if strings.EqualFold(nslogAddElapsed, "false") || nslogAddElapsed == "0" {
+		options.AddElapsed = false
+	} else if strings.EqualFold(nslogAddElapsed, "true") || nslogAddElapsed == "1" {
+		options.AddElapsed = true
+	} else {
+		// do not use environment variable for AddElapsed flag
+	}
+	nslogAddDelta := options.getenv("ADD_DELTA")
+	if strings.EqualFold(nslogAddDelta, "false") || nslogAddDelta == "0" {
+		options.AddDelta = false
+	} else if strings.EqualFold(nslogAddDelta, "true") || nslogAddDelta == "1" {
+		options.AddDelta = true
+	} else {
+		// do not use environment variable for AddDelta flag
+	}
+	nslogAddSequence := options.getenv("ADD_SEQUENCE")
+	if strings.EqualFold(nslogAddSequence, "false") || nslogAddSequence == "0" {
+		options.AddSequence = false
+	} else if strings.EqualFold(nslogAddSequence, "true") || nslogAddSequence == "1" {
+		options.AddSequence = true
+	} else {
+		// do not use environment variable for AddSequence flag
+	}
+	nslogAddPID := options.getenv("ADD_PID")
 	if strings.EqualFold(nslogAddPID, "false") || nslogAddPID == "0" {
 		options.AddPID = false
 	} else if strings.EqualFold(nslogAddPID, "true") || nslogAddPID == "1" {
@@ -96,7 +531,7 @@ func NewLogHandler(writer io.Writer, options *LogHandlerOptions) *LogHandler {
 	} else {
 		// do not use environment variable for AddPID flag
 	}
-	nslogAddGoroutineID := os.Getenv("GO_NSLOG_ADD_GOROUTINEID")
+	nslogAddGoroutineID := options.getenv("ADD_GOROUTINEID")
 	if strings.EqualFold(nslogAddGoroutineID, "false") || nslogAddGoroutineID == "0" {
 		options.AddGoroutineID = false
 	} else if strings.EqualFold(nslogAddGoroutineID, "true") || nslogAddGoroutineID == "1" {
@@ -104,19 +539,11 @@ func NewLogHandler(writer io.Writer, options *LogHandlerOptions) *LogHandler {
 	} else {
 		// do not use environment variable for AddGoroutineID flag
 	}
-	switch os.Getenv("GO_NSLOG_ADD_SOURCE_LEVEL") {
-	case "ERROR":
-		options.AddSourceLevel = slog.LevelError
-	case "WARN":
-		options.AddSourceLevel = slog.LevelWarn
-	case "INFO":
-		options.AddSourceLevel = slog.LevelInfo
-	case "DEBUG":
-		options.AddSourceLevel = slog.LevelDebug
-	default:
-		// do not use environment variable for Source level
+	nslogAddSourceLevel, err := ParseLevel(options.getenv("ADD_SOURCE_LEVEL"))
+	if err == nil {
+		options.AddSourceLevel = nslogAddSourceLevel
 	}
-	nslogSourceFilePath := os.Getenv("GO_NSLOG_SOURCE_FILE_PATH")
+	nslogSourceFilePath := options.getenv("SOURCE_FILE_PATH")
 	if strings.EqualFold(nslogSourceFilePath, "false") || nslogSourceFilePath == "0" {
 		options.SourceFilePath = false
 	} else if strings.EqualFold(nslogSourceFilePath, "true") || nslogSourceFilePath == "1" {
@@ -124,14 +551,406 @@ func NewLogHandler(writer io.Writer, options *LogHandlerOptions) *LogHandler {
 	} else {
 		// do not use environment variable for SourceFilePath flag
 	}
+	nslogSourceModule := options.getenv("SOURCE_MODULE")
+	if strings.EqualFold(nslogSourceModule, "false") || nslogSourceModule == "0" {
+		options.SourceModule = false
+	} else if strings.EqualFold(nslogSourceModule, "true") || nslogSourceModule == "1" {
+		options.SourceModule = true
+	} else {
+		// do not use environment variable for SourceModule flag
+	}
+	nslogSourceFunction := options.getenv("SOURCE_FUNCTION")
+	if strings.EqualFold(nslogSourceFunction, "false") || nslogSourceFunction == "0" {
+		options.SourceFunction = false
+	} else if strings.EqualFold(nslogSourceFunction, "true") || nslogSourceFunction == "1" {
+		options.SourceFunction = true
+	} else {
+		// do not use environment variable for SourceFunction flag
+	}
+	switch nslogSourceLink := options.getenv("SOURCE_LINK"); nslogSourceLink {
+	case "":
+		// do not use environment variable for SourceLink
+	case "NONE":
+		options.SourceLink = ""
+	case "FILE":
+		options.SourceLink = SOURCE_LINK_FILE
+	case "VSCODE":
+		options.SourceLink = SOURCE_LINK_VSCODE
+	default:
+		options.SourceLink = nslogSourceLink
+	}
+	nslogExpandErrors := options.getenv("EXPAND_ERRORS")
+	if strings.EqualFold(nslogExpandErrors, "false") || nslogExpandErrors == "0" {
+		options.ExpandErrors = false
+	} else if strings.EqualFold(nslogExpandErrors, "true") || nslogExpandErrors == "1" {
+		options.ExpandErrors = true
+	} else {
+		// do not use environment variable for ExpandErrors flag
+	}
+	nslogSortAttrs := options.getenv("SORT_ATTRS")
+	if strings.EqualFold(nslogSortAttrs, "false") || nslogSortAttrs == "0" {
+		options.SortAttrs = false
+	} else if strings.EqualFold(nslogSortAttrs, "true") || nslogSortAttrs == "1" {
+		options.SortAttrs = true
+	} else {
+		// do not use environment variable for SortAttrs flag
+	}
+	nslogDedupAttrs := options.getenv("DEDUP_ATTRS")
+	if strings.EqualFold(nslogDedupAttrs, "false") || nslogDedupAttrs == "0" {
+		options.DedupAttrs = false
+	} else if strings.EqualFold(nslogDedupAttrs, "true") || nslogDedupAttrs == "1" {
+		options.DedupAttrs = true
+	} else {
+		// do not use environment variable for DedupAttrs flag
+	}
+	nslogMaxLineLength, err := strconv.Atoi(options.getenv("MAX_LINE_LENGTH"))
+	if err == nil {
+		options.MaxLineLength = nslogMaxLineLength
+	}
+	nslogPayloadMaxSize, err := strconv.Atoi(options.getenv("PAYLOAD_MAX_SIZE"))
+	if err == nil {
+		options.PayloadMaxSize = nslogPayloadMaxSize
+	}
+	nslogDropKeys := options.getenv("DROP_KEYS")
+	if nslogDropKeys != "" {
+		options.DropKeys = strings.Split(nslogDropKeys, ",")
+	}
+	nslogPprofLabels := options.getenv("PPROF_LABELS")
+	if nslogPprofLabels != "" {
+		options.PprofLabels = strings.Split(nslogPprofLabels, ",")
+	}
+	nslogKeepOnlyKeys := options.getenv("KEEP_ONLY_KEYS")
+	if nslogKeepOnlyKeys != "" {
+		options.KeepOnlyKeys = strings.Split(nslogKeepOnlyKeys, ",")
+	}
+	switch options.getenv("TRACE_FORMAT") {
+	case "ATTRS":
+		options.TraceFormat = TraceFormatAttrs
+	case "SUFFIX":
+		options.TraceFormat = TraceFormatSuffix
+	case "NONE":
+		options.TraceFormat = TraceFormatNone
+	default:
+		// do not use environment variable for TraceFormat
+	}
+	var nslogLevelStyle LevelStyle
+	err = nslogLevelStyle.Set(options.getenv("LEVEL_STYLE"))
+	if err == nil {
+		options.LevelStyle = nslogLevelStyle
+	}
+	var nslogLevelSymbol LevelSymbol
+	err = nslogLevelSymbol.Set(options.getenv("LEVEL_SYMBOL"))
+	if err == nil {
+		options.LevelSymbol = nslogLevelSymbol
+	}
+	nslogLineEnding := options.getenv("LINE_ENDING")
+	switch nslogLineEnding {
+	case "":
+		// do not use environment variable for LineEnding
+	case "LF":
+		options.LineEnding = "\n"
+	case "CRLF":
+		options.LineEnding = "\r\n"
+	default:
+		options.LineEnding = nslogLineEnding
+	}
+	var nslogFormat OutputFormat
+	err = nslogFormat.Set(options.getenv("FORMAT"))
+	if err == nil {
+		options.Format = nslogFormat
+	}
+	nslogFieldSeparator := options.getenv("FIELD_SEPARATOR")
+	if nslogFieldSeparator != "" {
+		options.FieldSeparator = nslogFieldSeparator
+	}
+	nslogKeyValueSeparator := options.getenv("KEY_VALUE_SEPARATOR")
+	if nslogKeyValueSeparator != "" {
+		options.KeyValueSeparator = nslogKeyValueSeparator
+	}
+	nslogAttrsOpen := options.getenv("ATTRS_OPEN")
+	if nslogAttrsOpen != "" {
+		options.AttrsOpen = nslogAttrsOpen
+	}
+	nslogAttrsClose := options.getenv("ATTRS_CLOSE")
+	if nslogAttrsClose != "" {
+		options.AttrsClose = nslogAttrsClose
+	}
+	nslogGroupSeparator := options.getenv("GROUP_SEPARATOR")
+	if nslogGroupSeparator != "" {
+		options.GroupSeparator = nslogGroupSeparator
+	}
+	nslogAddHostname := options.getenv("ADD_HOSTNAME")
+	if strings.EqualFold(nslogAddHostname, "false") || nslogAddHostname == "0" {
+		options.AddHostname = false
+	} else if strings.EqualFold(nslogAddHostname, "true") || nslogAddHostname == "1" {
+		options.AddHostname = true
+	} else {
+		// do not use environment variable for AddHostname flag
+	}
+	nslogServiceName := options.getenv("SERVICE_NAME")
+	if nslogServiceName != "" {
+		options.ServiceName = nslogServiceName
+	}
+	nslogServiceVersion := options.getenv("SERVICE_VERSION")
+	if nslogServiceVersion != "" {
+		options.ServiceVersion = nslogServiceVersion
+	}
+	nslogAddBanner := options.getenv("ADD_BANNER")
+	if strings.EqualFold(nslogAddBanner, "false") || nslogAddBanner == "0" {
+		options.AddBanner = false
+	} else if strings.EqualFold(nslogAddBanner, "true") || nslogAddBanner == "1" {
+		options.AddBanner = true
+	} else {
+		// do not use environment variable for AddBanner flag
+	}
+	nslogMetaHeader := options.getenv("META_HEADER")
+	if strings.EqualFold(nslogMetaHeader, "false") || nslogMetaHeader == "0" {
+		options.MetaHeader = false
+	} else if strings.EqualFold(nslogMetaHeader, "true") || nslogMetaHeader == "1" {
+		options.MetaHeader = true
+	} else {
+		// do not use environment variable for MetaHeader flag
+	}
+	nslogBatchWrite := options.getenv("BATCH_WRITE")
+	if strings.EqualFold(nslogBatchWrite, "false") || nslogBatchWrite == "0" {
+		options.BatchWrite = false
+	} else if strings.EqualFold(nslogBatchWrite, "true") || nslogBatchWrite == "1" {
+		options.BatchWrite = true
+	} else {
+		// do not use environment variable for BatchWrite flag
+	}
+	nslogPadLevel := options.getenv("PAD_LEVEL")
+	if strings.EqualFold(nslogPadLevel, "false") || nslogPadLevel == "0" {
+		options.PadLevel = false
+	} else if strings.EqualFold(nslogPadLevel, "true") || nslogPadLevel == "1" {
+		options.PadLevel = true
+	} else {
+		// do not use environment variable for PadLevel flag
+	}
+	nslogMessageColumn, err := strconv.Atoi(options.getenv("MESSAGE_COLUMN"))
+	if err == nil {
+		options.MessageColumn = nslogMessageColumn
+	}
+	nslogTerminalWidth, err := strconv.Atoi(options.getenv("TERMINAL_WIDTH"))
+	if err == nil {
+		options.TerminalWidth = nslogTerminalWidth
+	}
+	var nslogMessageOverflow Overflow
+	err = nslogMessageOverflow.Set(options.getenv("MESSAGE_OVERFLOW"))
+	if err == nil {
+		options.MessageOverflow = nslogMessageOverflow
+	}
+	var nslogAttrsOverflow Overflow
+	err = nslogAttrsOverflow.Set(options.getenv("ATTRS_OVERFLOW"))
+	if err == nil {
+		options.AttrsOverflow = nslogAttrsOverflow
+	}
+	nslogAlignSource := options.getenv("ALIGN_SOURCE")
+	if strings.EqualFold(nslogAlignSource, "false") || nslogAlignSource == "0" {
+		options.AlignSource = false
+	} else if strings.EqualFold(nslogAlignSource, "true") || nslogAlignSource == "1" {
+		options.AlignSource = true
+	} else {
+		// do not use environment variable for AlignSource flag
+	}
+	nslogLiveProgress := options.getenv("LIVE_PROGRESS")
+	if strings.EqualFold(nslogLiveProgress, "false") || nslogLiveProgress == "0" {
+		options.LiveProgress = false
+	} else if strings.EqualFold(nslogLiveProgress, "true") || nslogLiveProgress == "1" {
+		options.LiveProgress = true
+	} else {
+		// do not use environment variable for LiveProgress flag
+	}
+	nslogSyncLevel, err := ParseLevel(options.getenv("SYNC_LEVEL"))
+	if err == nil {
+		options.SyncLevel = nslogSyncLevel
+	}
+	nslogRuntimeStatsLevel, err := ParseLevel(options.getenv("RUNTIME_STATS_LEVEL"))
+	if err == nil {
+		options.RuntimeStatsLevel = nslogRuntimeStatsLevel
+	}
 
-	return &LogHandler{
+	// resolve color mode to AddColor flag
+	options.AddColor = options.ColorMode.addColor(options.AddColor, writer)
+
+	handler := &LogHandler{
 		options: *options,
 		mutex:   &sync.Mutex{},
 		writer:  writer,
+		levels:  newLevelStrings(options),
+		colors:  newFieldColors(options),
+		pid:     newPIDString(options),
+		state:   &handlerState{start: time.Now()},
+		meta:    newMetaAttrs(options),
+		width:   newTerminalWidth(options, writer),
+	}
+	if options.BatchWrite {
+		handler.batch = newBatchWriter(writer, handler.mutex)
+	}
+	if options.LiveProgress && isTerminal(writer) {
+		handler.live = &progressState{}
+	}
+
+	// banner
+	if options.AddBanner && writer != nil {
+		_, _ = writer.Write(handler.format(context.Background(), newBannerRecord(options)))
+	}
+
+	// header
+	if options.MetaHeader && len(handler.meta) > 0 && writer != nil {
+		record := slog.NewRecord(time.Now(), slog.LevelInfo, "log metadata", 0)
+		record.AddAttrs(handler.meta...)
+		_, _ = writer.Write(handler.format(context.Background(), record))
+	}
+
+	return handler
+}
+
+func newPIDString(options *LogHandlerOptions) string {
+	if !options.AddPID {
+		return ""
+	}
+	return fmt.Sprintf("%04X", os.Getpid())
+}
+
+func newMetaAttrs(options *LogHandlerOptions) []slog.Attr {
+	var meta []slog.Attr
+	if options.AddHostname {
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = "unknown"
+		}
+		meta = append(meta, slog.String("host", hostname))
+	}
+	if options.ServiceName != "" {
+		meta = append(meta, slog.String("service", options.ServiceName))
+	}
+	if options.ServiceVersion != "" {
+		meta = append(meta, slog.String("version", options.ServiceVersion))
+	}
+	return meta
+}
+
+func newLevelStrings(options *LogHandlerOptions) map[slog.Level]string {
+	var levels map[slog.Level]string
+	switch options.LevelStyle {
+	case LevelStylePadded:
+		levels = map[slog.Level]string{
+			LevelFatal:      "FATAL",
+			slog.LevelError: "ERROR",
+			slog.LevelWarn:  "WARN ",
+			slog.LevelInfo:  "INFO ",
+			slog.LevelDebug: "DEBUG",
+		}
+	case LevelStyleShort:
+		levels = map[slog.Level]string{
+			LevelFatal:      "F",
+			slog.LevelError: "E",
+			slog.LevelWarn:  "W",
+			slog.LevelInfo:  "I",
+			slog.LevelDebug: "D",
+		}
+	case LevelStyleBracketed:
+		levels = map[slog.Level]string{
+			LevelFatal:      "[FATAL]",
+			slog.LevelError: "[ERROR]",
+			slog.LevelWarn:  "[WARN]",
+			slog.LevelInfo:  "[INFO]",
+			slog.LevelDebug: "[DEBUG]",
+		}
+	default:
+		levels = map[slog.Level]string{
+			LevelFatal:      "FATAL",
+			slog.LevelError: "ERROR",
+			slog.LevelWarn:  "WARN.",
+			slog.LevelInfo:  "INFO.",
+			slog.LevelDebug: "DEBUG",
+		}
+	}
+	for level, label := range options.LevelLabels {
+		levels[level] = label
+	}
+	if options.LevelSymbol != LevelSymbolNone {
+		symbols := options.LevelSymbols
+		if symbols == nil {
+			symbols = DEFAULT_LEVEL_SYMBOLS
+		}
+		for level, symbol := range symbols {
+			if label, ok := levels[level]; ok && options.LevelSymbol == LevelSymbolPrefix {
+				levels[level] = symbol + " " + label
+			} else {
+				levels[level] = symbol
+			}
+		}
+	}
+	if options.AddColor {
+		for level, label := range levels {
+			levels[level] = colorize(enableColor(options.theme().level(level)), label)
+		}
+	}
+	if options.PadLevel {
+		// pad outside of color not to extend background color
+		width := 0
+		for _, label := range levels {
+			width = max(width, visibleWidth(label))
+		}
+		for level, label := range levels {
+			levels[level] = padRight(label, width)
+		}
+	}
+	return levels
+}
+
+func newFieldColors(options *LogHandlerOptions) fieldColors {
+	if !options.AddColor {
+		return fieldColors{}
+	}
+	theme := options.theme()
+	return fieldColors{
+		time:    enableColor(fieldColor(theme.Time, options.ColorTime, DEFAULT_TIME_COLOR)),
+		source:  enableColor(fieldColor(theme.Source, options.ColorSource, DEFAULT_SOURCE_COLOR)),
+		attrKey: enableColor(fieldColor(theme.AttrKey, options.ColorAttrKeys, DEFAULT_ATTR_KEY_COLOR)),
 	}
 }
 
+// Get theme of the options, or the default theme if it is not set.
+func (options *LogHandlerOptions) theme() *Theme {
+	if options.Theme == nil {
+		return &DEFAULT_THEME
+	}
+	return options.Theme
+}
+
+// Format an attribute as "key=value" with color of the key.
+func (handler *LogHandler) attrString(key string, value string) string {
+	return colorize(handler.colors.attrKey, key) + handler.options.KeyValueSeparator + value
+}
+
+var stackBufferPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 64)
+		return &b
+	},
+}
+
+// Get ID of the current goroutine from the header of the stack, such as "goroutine 1 [running]:".
+// The ID is not cached because a handler is shared by goroutines, so runtime.Stack is called for each record,
+// which walks the stack even though only the header is kept in the buffer.
+func goroutineID() uint64 {
+	b := stackBufferPool.Get().(*[]byte)
+	defer stackBufferPool.Put(b)
+	stack := (*b)[:runtime.Stack(*b, false)]
+	stack = bytes.TrimPrefix(stack, []byte("goroutine "))
+	var id uint64
+	for _, c := range stack {
+		if c < '0' || c > '9' {
+			break
+		}
+		id = id*10 + uint64(c-'0')
+	}
+	return id
+}
+
 func (handler *LogHandler) clone() *LogHandler {
 	return &LogHandler{
 		options: handler.options,
@@ -139,92 +958,349 @@ func (handler *LogHandler) clone() *LogHandler {
 		groups:  slices.Clip(handler.groups),
 		writer:  handler.writer,
 		mutex:   handler.mutex,
+		levels:  handler.levels,
+		colors:  handler.colors,
+		pid:     handler.pid,
+		state:   handler.state,
+		meta:    handler.meta,
+		skip:    handler.skip,
+		batch:   handler.batch,
+		live:    handler.live,
+		level:   handler.level,
+		width:   handler.width,
 	}
 }
 
 func (handler *LogHandler) Enabled(_ context.Context, level slog.Level) bool {
-	return level >= handler.options.Level.Level()
+	minimum := handler.options.Level
+	if handler.level != nil {
+		minimum = handler.level
+	}
+	return level >= minimum.Level()
 }
 
 func (handler *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var resolved []slog.Attr
+	for _, attribute := range attrs {
+		resolved = handler.appendAttr(resolved, handler.groups, "", attribute)
+	}
+	if len(resolved) == 0 {
+		return handler
+	}
+
 	new_handler := handler.clone()
-	new_handler.attrs = append(new_handler.attrs, attrs...)
+	depth := len(handler.groups)
+	for len(new_handler.attrs) <= depth {
+		new_handler.attrs = append(new_handler.attrs, nil)
+	}
+	new_handler.attrs[depth] = append(slices.Clip(new_handler.attrs[depth]), resolved...)
 	return new_handler
 }
 
 func (handler *LogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return handler
+	}
 	new_handler := handler.clone()
 	new_handler.groups = append(new_handler.groups, name)
+	new_handler.level = groupLevel(handler.options.GroupLevels, new_handler.groups)
 	return new_handler
 }
 
-func (handler *LogHandler) Handle(_ context.Context, record slog.Record) error {
-	// time
-	time := record.Time.Format(handler.options.TimeLayout)
+// Get level of the longest name in the levels which matches the groups, such as "db" for groups "db.migrations".
+func groupLevel(levels map[string]slog.Leveler, groups []string) slog.Leveler {
+	for i := len(groups); i > 0; i-- {
+		level, ok := levels[strings.Join(groups[:i], ".")]
+		if ok {
+			return level
+		}
+	}
+	return nil
+}
+
+// Create a new [nslog.LogHandler] object which skips additional n frames for source,
+// so that the caller of wrapper functions of the logger is output as source.
+func (handler *LogHandler) WithCallerSkip(n int) *LogHandler {
+	new_handler := handler.clone()
+	new_handler.skip += n
+	return new_handler
+}
+
+// Create a new [nslog.LogHandler] object with options changed by the function, which keeps attributes and groups of the handler.
+// Environment variables are not applied again, and the banner and the header are not output.
+// Maps and slices of the options are shared with the handler, so replace them instead of modifying.
+func (handler *LogHandler) WithOptions(change func(options *LogHandlerOptions)) *LogHandler {
+	new_handler := handler.clone()
+	options := &new_handler.options
+	change(options)
 
-	// pid
-	var pid int = 0
-	if handler.options.AddPID {
-		pid = os.Getpid()
+	// set default parameters
+	if options.Level == nil {
+		options.Level = DEFAULT_LEVEL
+	}
+	if options.TimeLayout == "" {
+		options.TimeLayout = DEFAULT_TIME_LAYOUT
+	}
+	if options.AddSourceLevel == nil {
+		options.AddSourceLevel = DEFAULT_SOURCE_LEVEL
+	}
+	if options.FieldSeparator == "" {
+		options.FieldSeparator = DEFAULT_FIELD_SEPARATOR
+	}
+	if options.KeyValueSeparator == "" {
+		options.KeyValueSeparator = DEFAULT_KEY_VALUE_SEPARATOR
+	}
+	if options.AttrsOpen == "" {
+		options.AttrsOpen = DEFAULT_ATTRS_OPEN
+	}
+	if options.AttrsClose == "" {
+		options.AttrsClose = DEFAULT_ATTRS_CLOSE
+	}
+	if options.GroupSeparator == "" {
+		options.GroupSeparator = DEFAULT_GROUP_SEPARATOR
+	}
+	if options.LineEnding == "" {
+		options.LineEnding = DEFAULT_LINE_ENDING
+	}
+	if options.PayloadMaxSize == 0 {
+		options.PayloadMaxSize = DEFAULT_PAYLOAD_MAX_SIZE
+	}
+	if options.RuntimeStatsInterval <= 0 {
+		options.RuntimeStatsInterval = DEFAULT_RUNTIME_STATS_INTERVAL
 	}
 
-	// goroutineid
-	var goroutineID uint64 = 0
-	if handler.options.AddGoroutineID {
-		b := make([]byte, 64)
-		b = b[:runtime.Stack(b, false)]
-		b = bytes.TrimPrefix(b, []byte("goroutine "))
-		idField := b[:bytes.IndexByte(b, ' ')]
-		goroutineID, _ = strconv.ParseUint(string(idField), 10, 64)
+	// resolve color mode to AddColor flag
+	options.AddColor = options.ColorMode.addColor(options.AddColor, new_handler.writer)
+
+	new_handler.levels = newLevelStrings(options)
+	new_handler.colors = newFieldColors(options)
+	new_handler.pid = newPIDString(options)
+	new_handler.meta = newMetaAttrs(options)
+	new_handler.level = groupLevel(options.GroupLevels, new_handler.groups)
+	new_handler.width = newTerminalWidth(options, new_handler.writer)
+	if !options.BatchWrite {
+		new_handler.batch = nil
+	} else if new_handler.batch == nil {
+		new_handler.batch = newBatchWriter(new_handler.writer, new_handler.mutex)
+	}
+	if !options.LiveProgress || !isTerminal(new_handler.writer) {
+		new_handler.live = nil
+	} else if new_handler.live == nil {
+		new_handler.live = &progressState{}
 	}
+	return new_handler
+}
 
-	// level
-	var level string
-	switch record.Level {
-	case slog.LevelError:
-		if handler.options.AddColor {
-			level = color.HiRedString("ERROR")
-		} else {
-			level = "ERROR"
+// Create a new [nslog.LogHandler] object with the level, which takes precedence over GroupLevels option for the current groups.
+func (handler *LogHandler) WithLevel(level slog.Leveler) *LogHandler {
+	new_handler := handler.WithOptions(func(options *LogHandlerOptions) {
+		options.Level = level
+	})
+	new_handler.level = level
+	return new_handler
+}
+
+// Create a new [nslog.LogHandler] object which writes to the writer. Color is resolved again for the writer by ColorMode option.
+func (handler *LogHandler) WithWriter(writer io.Writer) *LogHandler {
+	new_handler := handler.clone()
+	new_handler.writer = writer
+	new_handler.mutex = &sync.Mutex{}
+	new_handler.batch = nil
+	new_handler.live = nil
+	return new_handler.WithOptions(func(*LogHandlerOptions) {})
+}
+
+func (handler *LogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if handler.skip > 0 && record.PC != 0 {
+		record.PC = skipCallers(record.PC, handler.skip)
+	}
+	if len(handler.options.Hooks) > 0 {
+		// clone not to modify attributes shared with the caller
+		record = record.Clone()
+		if !handler.beforeFormat(ctx, &record) {
+			if handler.options.CollectStats {
+				handler.state.stats.dropped.Add(1)
+			}
+			return nil
 		}
-	case slog.LevelWarn:
-		if handler.options.AddColor {
-			level = color.HiYellowString("WARN.")
+	}
+	log_bytes := handler.format(ctx, record)
+	var start time.Time
+	if handler.options.CollectStats {
+		start = time.Now()
+	}
+	var err error
+	if handler.live != nil {
+		// progress lines are not coalesced since they are written without newline
+		err = handler.live.write(handler.writer, handler.mutex, log_bytes, isProgress(record), handler.options.LineEnding)
+	} else if handler.batch != nil {
+		err = handler.batch.write(log_bytes)
+	} else {
+		handler.mutex.Lock()
+		_, err = handler.writer.Write(log_bytes)
+		handler.mutex.Unlock()
+	}
+	if handler.options.CollectStats {
+		if err != nil {
+			handler.state.stats.writeErrors.Add(1)
 		} else {
-			level = "WARN."
+			handler.state.stats.written(record.Level, len(log_bytes), time.Since(start))
 		}
-	case slog.LevelInfo:
-		if handler.options.AddColor {
-			level = color.HiGreenString("INFO.")
-		} else {
-			level = "INFO."
+	}
+	handler.afterWrite(ctx, record, log_bytes, err)
+	if err != nil {
+		return err
+	}
+	return handler.sync(record.Level)
+}
+
+// Call Sync of the writer if the level is SyncLevel option or higher.
+func (handler *LogHandler) sync(level slog.Level) error {
+	if handler.options.SyncLevel == nil || level < handler.options.SyncLevel.Level() {
+		return nil
+	}
+	syncer, ok := handler.writer.(interface{ Sync() error })
+	if !ok {
+		return nil
+	}
+
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+	return syncer.Sync()
+}
+
+// Append the attribute to attrs after resolving its value and applying ReplaceAttr option.
+// Attributes of a group are flattened with keys qualified by the group such as "group.key",
+// and the qualifier is prepended to the key. Empty attributes and empty groups are ignored.
+func (handler *LogHandler) appendAttr(attrs []slog.Attr, groups []string, qualifier string, attribute slog.Attr) []slog.Attr {
+	attribute.Value = attribute.Value.Resolve()
+	if attribute.Equal(slog.Attr{}) {
+		return attrs
+	}
+
+	if attribute.Value.Kind() == slog.KindGroup {
+		if attribute.Key != "" {
+			groups = append(slices.Clip(groups), attribute.Key)
+			qualifier += attribute.Key + handler.options.GroupSeparator
 		}
-	case slog.LevelDebug:
-		if handler.options.AddColor {
-			level = color.HiCyanString("DEBUG")
-		} else {
-			level = "DEBUG"
+		for _, member := range attribute.Value.Group() {
+			attrs = handler.appendAttr(attrs, groups, qualifier, member)
 		}
-	default:
-		level = "UNSET"
+		return attrs
 	}
 
-	// withGroup
-	withGroup := ""
-	if len(handler.groups) > 0 {
-		withGroup = strings.Join(handler.groups, ".")
+	if handler.options.ReplaceAttr != nil {
+		attribute = handler.options.ReplaceAttr(groups, attribute)
+		attribute.Value = attribute.Value.Resolve()
+		if attribute.Key == "" {
+			return attrs
+		}
 	}
+	attribute.Key = qualifier + attribute.Key
+	if !handler.keepKey(attribute.Key) {
+		return attrs
+	}
+	return append(attrs, attribute)
+}
 
-	// withAttributes
-	var withAttributes []string
-	for _, attribute := range handler.attrs {
-		withAttributes = append(withAttributes, attribute.Key+"="+attribute.Value.String())
+// Remove attributes with repeated keys except the last one, which keeps its position.
+func dedupAttrs(attrs []slog.Attr) []slog.Attr {
+	seen := make(map[string]bool, len(attrs))
+	deduped := make([]slog.Attr, 0, len(attrs))
+	for i := len(attrs) - 1; i >= 0; i-- {
+		if !seen[attrs[i].Key] {
+			seen[attrs[i].Key] = true
+			deduped = append(deduped, attrs[i])
+		}
 	}
+	slices.Reverse(deduped)
+	return deduped
+}
 
-	// with
-	with := withGroup
-	if len(withAttributes) > 0 {
-		with += "[" + strings.Join(withAttributes, " ") + "]"
+// Check whether the attribute of the key is kept by DropKeys and KeepOnlyKeys options.
+func (handler *LogHandler) keepKey(key string) bool {
+	for _, pattern := range handler.options.DropKeys {
+		if matched, _ := path.Match(pattern, key); matched {
+			return false
+		}
+	}
+	if len(handler.options.KeepOnlyKeys) == 0 {
+		return true
+	}
+	for _, pattern := range handler.options.KeepOnlyKeys {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Format a record to a log line terminated by newline.
+func (handler *LogHandler) format(ctx context.Context, record slog.Record) []byte {
+	switch handler.options.Format {
+	case FormatLTSV:
+		return handler.formatLTSV(ctx, record)
+	case FormatGlog:
+		return handler.formatGlog(ctx, record)
+	case FormatCompact:
+		return handler.formatCompact(ctx, record)
+	}
+
+	separator := handler.options.FieldSeparator
+
+	// sequence
+	var sequence string
+	if handler.options.AddSequence {
+		sequence = "#" + strconv.FormatUint(handler.state.sequence.Add(1), 10)
+	}
+
+	// elapsed and delta
+	var elapsed, delta string
+	if handler.options.AddElapsed && !record.Time.IsZero() {
+		elapsed = colorize(handler.colors.time, "+"+record.Time.Sub(handler.state.start).Round(time.Microsecond).String())
+	}
+	if handler.options.AddDelta && !record.Time.IsZero() {
+		delta = colorize(handler.colors.time, "+"+handler.delta(record.Time).Round(time.Microsecond).String())
+	}
+
+	// time
+	recordTime := record.Time
+	if handler.options.UseUTC {
+		recordTime = recordTime.UTC()
+	}
+	var time string
+	if !handler.options.OmitTime && !record.Time.IsZero() {
+		time = colorize(handler.colors.time, recordTime.Format(handler.options.TimeLayout))
+	}
+
+	// goroutineid
+	var goroutine_id uint64 = 0
+	if handler.options.AddGoroutineID {
+		goroutine_id = goroutineID()
+	}
+
+	// level
+	level, ok := handler.levels[record.Level]
+	if !ok {
+		level = "UNSET"
+	}
+
+	// with (groups and attributes added to the handler such as "[key=val]Group1[key=val].Group2")
+	var with string
+	for depth := 0; depth <= len(handler.groups); depth++ {
+		if depth > 0 {
+			if depth > 1 {
+				with += handler.options.GroupSeparator
+			}
+			with += handler.groups[depth-1]
+		}
+		if depth < len(handler.attrs) && len(handler.attrs[depth]) > 0 {
+			var withAttributes []string
+			for _, attribute := range handler.attrs[depth] {
+				withAttributes = append(withAttributes, handler.attrString(attribute.Key, handler.options.ValueFormat.format(attribute.Value)))
+			}
+			with += handler.options.AttrsOpen + strings.Join(withAttributes, separator) + handler.options.AttrsClose
+		}
 	}
 	if with != "" {
 		with += ":"
@@ -233,48 +1309,169 @@ func (handler *LogHandler) Handle(_ context.Context, record slog.Record) error {
 	// message
 	message := record.Message
 
-	// attributes
+	// attributes (qualified by groups of the handler)
+	recordAttrs := handler.recordAttrs(ctx, record)
+
 	var attributes []string
-	record.Attrs(func(attribute slog.Attr) bool {
-		attributes = append(attributes, attribute.Key+"="+attribute.Value.String())
-		return true
-	})
+	var continuations []string
+	for _, attribute := range recordAttrs {
+		if summary, lines, ok := payloadLines(attribute, handler.options.PayloadMaxSize); ok {
+			attributes = append(attributes, handler.attrString(attribute.Key, summary))
+			continuations = append(continuations, lines...)
+			continue
+		}
+		attributes = append(attributes, handler.attrString(attribute.Key, handler.options.ValueFormat.format(attribute.Value)))
+		continuations = append(continuations, handler.expandError(attribute)...)
+	}
+
+	// trace
+	var trace string
+	if handler.options.TraceFormat != TraceFormatNone {
+		spanContext := oteltrace.SpanContextFromContext(ctx)
+		if spanContext.IsValid() {
+			if handler.options.TraceFormat == TraceFormatSuffix {
+				trace = "[" + spanContext.TraceID().String() + "/" + spanContext.SpanID().String() + "]"
+			} else {
+				attributes = append(attributes, handler.attrString("trace_id", spanContext.TraceID().String()), handler.attrString("span_id", spanContext.SpanID().String()))
+			}
+		}
+	}
 
 	// source
 	var source string
 	if record.Level >= handler.options.AddSourceLevel.Level() {
 		if record.PC != 0 {
 			frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
-			if handler.options.SourceFilePath {
-				source = "(" + frame.File + ":" + strconv.Itoa(frame.Line) + ")"
-			} else {
-				source = "(" + filepath.Base(frame.File) + ":" + strconv.Itoa(frame.Line) + ")"
+			source = handler.sourceString(frame)
+			if source != "" {
+				source = colorize(handler.colors.source, source)
+				if handler.options.AddColor && handler.options.SourceLink != "" {
+					source = hyperlink(sourceLink(handler.options.SourceLink, frame), source)
+				}
 			}
 		}
 	}
 
-	log_strings := []string{time}
-	if pid > 0 {
-		log_strings = append(log_strings, fmt.Sprintf("%04X", pid))
+	var log_strings []string
+	if sequence != "" {
+		log_strings = append(log_strings, sequence)
 	}
-	if goroutineID > 0 {
-		log_strings = append(log_strings, fmt.Sprintf("%08X", goroutineID))
+	if time != "" {
+		log_strings = append(log_strings, time)
+	}
+	if elapsed != "" {
+		log_strings = append(log_strings, elapsed)
+	}
+	if delta != "" {
+		log_strings = append(log_strings, delta)
+	}
+	if handler.pid != "" {
+		log_strings = append(log_strings, handler.pid)
+	}
+	if goroutine_id > 0 {
+		log_strings = append(log_strings, fmt.Sprintf("%08X", goroutine_id))
 	}
 	log_strings = append(log_strings, level)
 	if with != "" {
 		log_strings = append(log_strings, with)
 	}
+	if handler.options.MessageColumn > 0 {
+		// pad the field before message, so message starts at the column after a space
+		if column := visibleWidth(strings.Join(log_strings, separator) + separator); column < handler.options.MessageColumn {
+			log_strings[len(log_strings)-1] += strings.Repeat(" ", handler.options.MessageColumn-column)
+		}
+	}
+	if handler.width > 0 {
+		var suffix int
+		if trace != "" {
+			suffix += visibleWidth(separator + trace)
+		}
+		if source != "" {
+			suffix += visibleWidth(separator + source)
+		}
+		var wrapped []string
+		message, attributes, wrapped = handler.fitWidth(visibleWidth(strings.Join(log_strings, separator)+separator), message, attributes, suffix)
+		continuations = append(wrapped, continuations...)
+	}
 	log_strings = append(log_strings, message)
-	if len(attributes) > 0 {
-		log_strings = append(log_strings, strings.Join(attributes, " "))
+	attributesText := strings.Join(attributes, separator)
+	if attributesText != "" {
+		log_strings = append(log_strings, attributesText)
+	}
+	if trace != "" {
+		log_strings = append(log_strings, trace)
 	}
+	log_line := strings.Join(log_strings, separator)
 	if source != "" {
-		log_strings = append(log_strings, source)
+		if handler.width > 0 {
+			// right-align source at the width of the terminal, which keeps a space as separator
+			log_line = padRight(log_line, handler.width-visibleWidth(source)-visibleWidth(separator))
+		}
+		log_line += separator + source
 	}
-	log_bytes := []byte(strings.Join(log_strings, " ") + "\n")
+	if handler.options.MaxLineLength > 0 {
+		log_line = truncateLine(log_line, attributesText, handler.options.MaxLineLength)
+	}
+	for _, continuation := range continuations {
+		log_line += handler.options.LineEnding + continuation
+	}
+	return []byte(log_line + handler.options.LineEnding)
+}
 
-	handler.mutex.Lock()
-	defer handler.mutex.Unlock()
-	_, err := handler.writer.Write(log_bytes)
-	return err
+// Get attributes of the record qualified by groups of the handler, followed by context attributes, pprof labels, and metadata.
+func (handler *LogHandler) recordAttrs(ctx context.Context, record slog.Record) []slog.Attr {
+	var recordAttrs []slog.Attr
+	var qualifier string
+	if len(handler.groups) > 0 {
+		qualifier = strings.Join(handler.groups, handler.options.GroupSeparator) + handler.options.GroupSeparator
+	}
+	record.Attrs(func(attribute slog.Attr) bool {
+		if attribute.Key != PROGRESS_KEY {
+			recordAttrs = handler.appendAttr(recordAttrs, handler.groups, qualifier, attribute)
+		}
+		return true
+	})
+
+	// context attributes
+	for _, attribute := range ContextAttrs(ctx) {
+		recordAttrs = handler.appendAttr(recordAttrs, nil, "", attribute)
+	}
+
+	// pprof labels
+	if len(handler.options.PprofLabels) > 0 {
+		for _, attribute := range pprofLabels(ctx, handler.options.PprofLabels) {
+			recordAttrs = handler.appendAttr(recordAttrs, nil, "", attribute)
+		}
+	}
+
+	// metadata
+	if !handler.options.MetaHeader {
+		for _, attribute := range handler.meta {
+			recordAttrs = handler.appendAttr(recordAttrs, nil, "", attribute)
+		}
+	}
+
+	// runtime stats
+	if attribute, ok := handler.runtimeStats(record.Level); ok {
+		recordAttrs = handler.appendAttr(recordAttrs, nil, "", attribute)
+	}
+
+	if handler.options.DedupAttrs {
+		recordAttrs = dedupAttrs(recordAttrs)
+	}
+	if handler.options.SortAttrs {
+		slices.SortStableFunc(recordAttrs, func(a, b slog.Attr) int {
+			return strings.Compare(a.Key, b.Key)
+		})
+	}
+	return recordAttrs
+}
+
+// Get time since the previous record, which is 0 for the first record.
+func (handler *LogHandler) delta(t time.Time) time.Duration {
+	last := handler.state.last.Swap(t.UnixNano())
+	if last == 0 {
+		return 0
+	}
+	return time.Duration(t.UnixNano() - last)
 }
@@ -2,10 +2,21 @@ package nslog
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"os"
+	"regexp"
+	"strings"
 	"testing"
+	"testing/slogtest"
+	"time"
 
+	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 ///////////////////////////////////////////////////////////////////////////////
@@ -117,6 +128,246 @@ func TestDebugLevel(t *testing.T) {
 	assert.Contains(t, buf.String(), "DEBUG log message")
 }
 
+///////////////////////////////////////////////////////////////////////////////
+// Option: ReplaceAttr
+///////////////////////////////////////////////////////////////////////////////
+
+func TestReplaceAttr(t *testing.T) {
+	buf := new(bytes.Buffer)
+	replace := func(groups []string, a slog.Attr) slog.Attr {
+		if a.Key == "password" {
+			return slog.String(a.Key, "***")
+		}
+		if a.Key == "drop" {
+			return slog.Attr{}
+		}
+		return a
+	}
+	log := NewLogger(buf, &LogHandlerOptions{ReplaceAttr: replace}).With("drop", 0, "id", 1)
+	log.Info("log message", "user", "foo", "password", "bar", "drop", 1)
+	assert.Contains(t, buf.String(), "INFO. [id=1]: log message user=foo password=***\n")
+}
+
+func TestFromSlogHandlerOptions(t *testing.T) {
+	buf := new(bytes.Buffer)
+	options := FromSlogHandlerOptions(slog.HandlerOptions{AddSource: true, Level: slog.LevelDebug})
+	log := NewLogger(buf, &options)
+	log.Debug("log message")
+	assert.Regexp(t, "DEBUG log message \\(log_handler_test\\.go:\\d+\\)", buf.String())
+
+	buf2 := new(bytes.Buffer)
+	options2 := FromSlogHandlerOptions(slog.HandlerOptions{})
+	log2 := NewLogger(buf2, &options2)
+	log2.Debug("log message")
+	log2.Error("log message")
+	assert.NotContains(t, buf2.String(), "DEBUG")
+	assert.Regexp(t, "ERROR log message\n", buf2.String())
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Option: DropKeys / KeepOnlyKeys
+///////////////////////////////////////////////////////////////////////////////
+
+func TestDropKeys(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{DropKeys: []string{"*password", "token"}})
+	log.With("token", "secret", "id", 1).Info("log message", "user", "alice", "password", "secret", slog.Group("db", "password", "secret"))
+	assert.Contains(t, buf.String(), "INFO. [id=1]: log message user=alice\n")
+}
+
+func TestKeepOnlyKeys(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{KeepOnlyKeys: []string{"id", "req.*"}})
+	log.With("token", "secret", "id", 1).Info("log message", "user", "alice", slog.Group("req", "method", "GET"))
+	assert.Contains(t, buf.String(), "INFO. [id=1]: log message req.method=GET\n")
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Option: SortAttrs / DedupAttrs
+///////////////////////////////////////////////////////////////////////////////
+
+func TestSortAttrs(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{SortAttrs: true})
+	log.Info("log message", "key3", "val3", "key1", "val1", slog.Group("key2", "b", 1, "a", 2))
+	assert.Contains(t, buf.String(), "INFO. log message key1=val1 key2.a=2 key2.b=1 key3=val3\n")
+}
+
+func TestDedupAttrs(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{DedupAttrs: true})
+	log.Info("log message", "key1", "val1", "key2", "val2", "key1", "val3")
+	assert.Contains(t, buf.String(), "INFO. log message key2=val2 key1=val3\n")
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Option: TraceFormat
+///////////////////////////////////////////////////////////////////////////////
+
+func newSpanContext() context.Context {
+	traceID, _ := oteltrace.TraceIDFromHex("0af7651916cd43dd8448eb211c80319c")
+	spanID, _ := oteltrace.SpanIDFromHex("b7ad6b7169203331")
+	spanContext := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: traceID, SpanID: spanID})
+	return oteltrace.ContextWithSpanContext(context.Background(), spanContext)
+}
+
+func TestTraceFormatAttrs(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, nil)
+	log.InfoContext(newSpanContext(), "log message", "key1", "val1")
+	log.InfoContext(context.Background(), "log message")
+	assert.Contains(t, buf.String(), "INFO. log message key1=val1 trace_id=0af7651916cd43dd8448eb211c80319c span_id=b7ad6b7169203331\n")
+	assert.Contains(t, buf.String(), "INFO. log message\n")
+}
+
+func TestTraceFormatSuffix(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{TraceFormat: TraceFormatSuffix})
+	log.WarnContext(newSpanContext(), "log message", "key1", "val1")
+	assert.Regexp(t, "WARN\\. log message key1=val1 \\[0af7651916cd43dd8448eb211c80319c/b7ad6b7169203331\\] \\(log_handler_test\\.go:\\d+\\)\n", buf.String())
+}
+
+func TestTraceFormatNone(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{TraceFormat: TraceFormatNone})
+	log.InfoContext(newSpanContext(), "log message")
+	assert.Contains(t, buf.String(), "INFO. log message\n")
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Option: GroupLevels
+///////////////////////////////////////////////////////////////////////////////
+
+func TestGroupLevels(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{GroupLevels: map[string]slog.Leveler{"db": slog.LevelWarn, "db.migrations": slog.LevelDebug}})
+	log.Info("message1")
+	log.WithGroup("db").Info("message2")
+	log.WithGroup("db").Warn("message3")
+	log.WithGroup("db").WithGroup("migrations").Debug("message4")
+	log.WithGroup("db").WithGroup("query").Info("message5")
+	log.WithGroup("http").Debug("message6")
+	assert.Contains(t, buf.String(), "message1")
+	assert.NotContains(t, buf.String(), "message2")
+	assert.Contains(t, buf.String(), "message3")
+	assert.Contains(t, buf.String(), "message4")
+	assert.NotContains(t, buf.String(), "message5")
+	assert.NotContains(t, buf.String(), "message6")
+}
+
+func TestGroupLevelsEnv(t *testing.T) {
+	t.Setenv("GO_NSLOG_GROUP_LEVELS", "db=DEBUG,http=ERROR")
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, nil)
+	log.WithGroup("db").Debug("message1")
+	log.WithGroup("http").Warn("message2")
+	assert.Contains(t, buf.String(), "message1")
+	assert.NotContains(t, buf.String(), "message2")
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Option: LevelStyle / LevelLabels
+///////////////////////////////////////////////////////////////////////////////
+
+func TestLevelStylePadded(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{LevelStyle: LevelStylePadded})
+	log.Info("log message")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO  log message\n$", buf.String())
+}
+
+func TestLevelStyleShort(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{LevelStyle: LevelStyleShort})
+	log.Info("log message")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" I log message\n$", buf.String())
+}
+
+func TestLevelStyleBracketed(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{LevelStyle: LevelStyleBracketed})
+	log.Info("log message")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" \\[INFO\\] log message\n$", buf.String())
+}
+
+func TestLevelLabels(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{LevelStyle: LevelStyleShort, LevelLabels: map[slog.Level]string{slog.LevelInfo: "info"}})
+	log.Info("log message")
+	log.Error("log message")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" info log message\n"+DEFAULT_TIME_REGEXP+" E log message \\(log_handler_test\\.go:\\d+\\)\n$", buf.String())
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Option: TimeLayout / UseUTC
+///////////////////////////////////////////////////////////////////////////////
+
+func TestTimeLayoutMillis(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{TimeLayout: TIME_LAYOUT_MILLIS})
+	log.Info("log message")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+"\\.\\d{3} INFO\\. log message\n$", buf.String())
+}
+
+func TestUseUTC(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{TimeLayout: TIME_LAYOUT_RFC3339_NANO, UseUTC: true})
+	log.Info("log message")
+	assert.Regexp(t, "^\\d{4}-\\d{2}-\\d{2}T\\d{2}:\\d{2}:\\d{2}(\\.\\d+)?Z INFO\\. log message\n$", buf.String())
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Option: OmitTime / AddElapsed / AddDelta
+///////////////////////////////////////////////////////////////////////////////
+
+func TestAddElapsed(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{AddElapsed: true})
+	log.Info("log message")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" \\+[0-9.]+[µnm]?s INFO\\. log message\n$", buf.String())
+}
+
+func TestAddDelta(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true, AddDelta: true})
+	log.Info("log message1")
+	time.Sleep(10 * time.Millisecond)
+	log.With("key1", "val1").Info("log message2")
+	assert.Regexp(t, "^\\+0s INFO\\. log message1\n\\+[0-9.]+ms INFO\\. \\[key1=val1\\]: log message2\n$", buf.String())
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Option: AddSequence
+///////////////////////////////////////////////////////////////////////////////
+
+func TestAddSequence(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{AddSequence: true})
+	log.Info("log message1")
+	log.WithGroup("Group1").Info("log message2")
+	log.Info("log message3")
+	assert.Regexp(t, "^#1 "+DEFAULT_TIME_REGEXP+" INFO\\. log message1\n#2 "+DEFAULT_TIME_REGEXP+" INFO\\. Group1: log message2\n#3 "+DEFAULT_TIME_REGEXP+" INFO\\. log message3\n$", buf.String())
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Option: AddHostname / ServiceName / ServiceVersion / MetaHeader
+///////////////////////////////////////////////////////////////////////////////
+
+func TestMeta(t *testing.T) {
+	hostname, _ := os.Hostname()
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{AddHostname: true, ServiceName: "svc", ServiceVersion: "1.2.3"})
+	log.Info("log message", "key1", "val1")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. log message key1=val1 host="+regexp.QuoteMeta(hostname)+" service=svc version=1\\.2\\.3\n$", buf.String())
+}
+
+func TestMetaHeader(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{Level: slog.LevelError, ServiceName: "svc", MetaHeader: true})
+	log.Error("log message")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. log metadata service=svc\n"+DEFAULT_TIME_REGEXP+" ERROR log message \\(log_handler_test\\.go:\\d+\\)\n$", buf.String())
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 // Groups
 ///////////////////////////////////////////////////////////////////////////////
@@ -165,7 +416,7 @@ func TestWith2(t *testing.T) {
 	buf2 := new(bytes.Buffer)
 	log2 := NewLogger(buf2, nil).WithGroup("Group1").With("pid", 0).WithGroup("Group2")
 	log2.Info("message")
-	assert.Contains(t, buf2.String(), "INFO. Group1.Group2[pid=0]: message")
+	assert.Contains(t, buf2.String(), "INFO. Group1[pid=0].Group2: message")
 }
 
 func TestWith3(t *testing.T) {
@@ -177,5 +428,231 @@ func TestWith3(t *testing.T) {
 	buf2 := new(bytes.Buffer)
 	log2 := NewLogger(buf2, nil).WithGroup("Group1").With("pid", "dead").WithGroup("Group2").With("tid", "beaf")
 	log2.Info("message")
-	assert.Contains(t, buf2.String(), "INFO. Group1.Group2[pid=dead tid=beaf]: message")
+	assert.Contains(t, buf2.String(), "INFO. Group1[pid=dead].Group2[tid=beaf]: message")
+}
+
+func TestWithGroupAttrs(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, nil).WithGroup("Group1")
+	log.Info("message", "key1", "val1", slog.Group("Group2", "key2", "val2"))
+	assert.Contains(t, buf.String(), "INFO. Group1: message Group1.key1=val1 Group1.Group2.key2=val2\n")
+}
+
+func TestWithGroupEmpty(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, nil).WithGroup("")
+	log.Info("message", slog.Group("Group1"), slog.Group("", "key1", "val1"), slog.Attr{})
+	assert.Contains(t, buf.String(), "INFO. message key1=val1\n")
+}
+
+type tokenValuer string
+
+func (token tokenValuer) LogValue() slog.Value {
+	return slog.StringValue("***")
+}
+
+func TestLogValuer(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, nil).With("token1", tokenValuer("secret"))
+	log.Info("message", "token2", tokenValuer("secret"))
+	assert.Contains(t, buf.String(), "INFO. [token1=***]: message token2=***\n")
+}
+
+// Parse a log line to a map for slogtest by [nslog.Parse], where keys qualified by groups are nested.
+func parseLogLine(t *testing.T, line string) map[string]any {
+	record, err := Parse(line, &ParserOptions{TimeLayout: time.RFC3339Nano})
+	assert.NoError(t, err, line)
+
+	result := map[string]any{slog.LevelKey: record.LevelLabel, slog.MessageKey: record.Message}
+	if !record.Time.IsZero() {
+		result[slog.TimeKey] = record.Time
+	}
+	for _, attribute := range record.Attrs {
+		current := result
+		keys := strings.Split(attribute.Key, ".")
+		for _, group := range keys[:len(keys)-1] {
+			if _, ok := current[group].(map[string]any); !ok {
+				current[group] = map[string]any{}
+			}
+			current = current[group].(map[string]any)
+		}
+		current[keys[len(keys)-1]] = attribute.Value.String()
+	}
+	return result
+}
+
+func TestSlogtest(t *testing.T) {
+	buf := new(bytes.Buffer)
+	handler := NewLogHandler(buf, &LogHandlerOptions{TimeLayout: time.RFC3339Nano})
+	err := slogtest.TestHandler(handler, func() []map[string]any {
+		var results []map[string]any
+		for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+			results = append(results, parseLogLine(t, line))
+		}
+		return results
+	})
+	assert.NoError(t, err)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Benchmark
+///////////////////////////////////////////////////////////////////////////////
+
+func benchmarkLogger(b *testing.B, options *LogHandlerOptions) {
+	log := NewLogger(io.Discard, options)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.Info("log message", "key1", "val1")
+	}
+}
+
+func BenchmarkDefault(b *testing.B) {
+	benchmarkLogger(b, nil)
+}
+
+func BenchmarkAddColor(b *testing.B) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+	benchmarkLogger(b, &LogHandlerOptions{AddColor: true})
+}
+
+func BenchmarkAddPID(b *testing.B) {
+	benchmarkLogger(b, &LogHandlerOptions{AddPID: true})
+}
+
+func BenchmarkAddGoroutineID(b *testing.B) {
+	benchmarkLogger(b, &LogHandlerOptions{AddGoroutineID: true})
+}
+
+func BenchmarkAddSource(b *testing.B) {
+	benchmarkLogger(b, &LogHandlerOptions{AddSourceLevel: slog.LevelInfo})
+}
+
+func BenchmarkAddSourceFilePath(b *testing.B) {
+	benchmarkLogger(b, &LogHandlerOptions{AddSourceLevel: slog.LevelInfo, SourceFilePath: true})
+}
+
+func BenchmarkAll(b *testing.B) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+	benchmarkLogger(b, &LogHandlerOptions{AddColor: true, AddPID: true, AddGoroutineID: true, AddSourceLevel: slog.LevelInfo})
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Option: EnvPrefix / DisableEnv
+///////////////////////////////////////////////////////////////////////////////
+
+func TestEnvPrefix(t *testing.T) {
+	t.Setenv("GO_NSLOG_LEVEL", "ERROR")
+	t.Setenv("MYLIB_LOG_LEVEL", "DEBUG")
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{EnvPrefix: "MYLIB_LOG_"})
+	log.Debug("log message")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" DEBUG log message\n$", buf.String())
+}
+
+func TestDisableEnv(t *testing.T) {
+	t.Setenv("GO_NSLOG_LEVEL", "ERROR")
+	t.Setenv("GO_NSLOG_ADD_PID", "TRUE")
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{DisableEnv: true})
+	log.Info("log message")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. log message\n$", buf.String())
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// WithOptions / WithLevel / WithWriter
+///////////////////////////////////////////////////////////////////////////////
+
+func TestWithOptions(t *testing.T) {
+	buf := new(bytes.Buffer)
+	handler := NewLogHandler(buf, nil).WithAttrs([]slog.Attr{slog.String("key1", "val1")}).(*LogHandler)
+	child := handler.WithOptions(func(options *LogHandlerOptions) {
+		options.LevelStyle = LevelStyleShort
+		options.AddPID = true
+	})
+	slog.New(child).Info("log message1")
+	slog.New(handler).Info("log message2")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" [0-9A-F]{4,} I \\[key1=val1\\]: log message1\n"+
+		DEFAULT_TIME_REGEXP+" INFO\\. \\[key1=val1\\]: log message2\n$", buf.String())
+}
+
+func TestWithLevel(t *testing.T) {
+	buf := new(bytes.Buffer)
+	handler := NewLogHandler(buf, &LogHandlerOptions{GroupLevels: map[string]slog.Leveler{"db": slog.LevelError}})
+	db := handler.WithGroup("db").(*LogHandler)
+	slog.New(db).Warn("log message1")
+	slog.New(db.WithLevel(slog.LevelDebug)).Debug("log message2")
+	slog.New(handler.WithLevel(slog.LevelWarn)).Info("log message3")
+	slog.New(handler).Info("log message4")
+	assert.NotContains(t, buf.String(), "message1")
+	assert.Contains(t, buf.String(), "message2")
+	assert.NotContains(t, buf.String(), "message3")
+	assert.Contains(t, buf.String(), "message4")
+}
+
+func TestWithWriter(t *testing.T) {
+	buf1 := new(bytes.Buffer)
+	buf2 := new(bytes.Buffer)
+	handler := NewLogHandler(buf1, &LogHandlerOptions{ColorMode: ColorModeAuto}).WithGroup("Main").(*LogHandler)
+	slog.New(handler.WithWriter(buf2)).Info("log message")
+	assert.Empty(t, buf1.String())
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. Main: log message\n$", buf2.String())
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Option: SyncLevel
+///////////////////////////////////////////////////////////////////////////////
+
+type syncWriter struct {
+	bytes.Buffer
+	syncs int
+}
+
+func (writer *syncWriter) Sync() error {
+	writer.syncs++
+	return nil
+}
+
+func TestSyncLevel(t *testing.T) {
+	writer := &syncWriter{}
+	log := NewLogger(writer, &LogHandlerOptions{SyncLevel: slog.LevelError})
+	log.Warn("log message1")
+	assert.Equal(t, 0, writer.syncs)
+	log.Error("log message2")
+	assert.Equal(t, 1, writer.syncs)
+
+	log = NewLogger(writer, &LogHandlerOptions{SyncLevel: slog.LevelWarn, BatchWrite: true})
+	log.Warn("log message3")
+	assert.Equal(t, 2, writer.syncs)
+}
+
+func TestSyncLevelEnv(t *testing.T) {
+	t.Setenv("GO_NSLOG_SYNC_LEVEL", "WARN")
+	writer := &syncWriter{}
+	NewLogger(writer, nil).Warn("log message")
+	assert.Equal(t, 1, writer.syncs)
+}
+
+func TestLineEnding(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{LineEnding: "\r\n", OmitTime: true, ExpandErrors: true})
+	log.Info("log message")
+	log.Error("error message", "err", fmt.Errorf("outer: %w", errors.New("inner")))
+	assert.Regexp(t, "^INFO. log message\r\nERROR error message err=outer: inner \\(log_handler_test\\.go:[0-9]+\\)\r\n\terr: caused by: inner\r\n$", buf.String())
+
+	buf.Reset()
+	log = NewLogger(buf, &LogHandlerOptions{LineEnding: "\r\n", OmitTime: true, Format: FormatLTSV})
+	log.Info("log message")
+	assert.Equal(t, "level:INFO\tmsg:log message\r\n", buf.String())
+}
+
+func TestLineEndingEnv(t *testing.T) {
+	t.Setenv("GO_NSLOG_LINE_ENDING", "CRLF")
+	buf := new(bytes.Buffer)
+	NewLogger(buf, &LogHandlerOptions{OmitTime: true}).Info("log message")
+	assert.Equal(t, "INFO. log message\r\n", buf.String())
 }
@@ -0,0 +1,167 @@
+package nslog
+
+import (
+	"bytes"
+	"html/template"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const DEFAULT_LOG_VIEWER_LIMIT = 200
+
+// An option to customize [nslog.LogViewer].
+type LogViewerOptions struct {
+	Limit int    // Set number of the last records shown by default, which can be changed by "n" query. (default: 200)
+	Title string // Set title of the page. (default: "Logs")
+}
+
+// An HTTP handler to render the last records of [nslog.RingHandler] as an HTML page with level filtering and search,
+// which is a zero-dependency "/debug/logs" page for internal services. The page accepts queries such as
+// "?level=warn&q=timeout&n=50" to show the last 50 records of Warn or higher level containing "timeout" (case-insensitive).
+type LogViewer struct {
+	ring    *RingHandler
+	options LogViewerOptions
+}
+
+type logViewerRow struct {
+	Level string
+	Class string
+	Line  string
+}
+
+type logViewerPage struct {
+	Title  string
+	Level  string
+	Query  string
+	Limit  int
+	Levels []string
+	Rows   []logViewerRow
+	Total  int
+}
+
+var logViewerTemplate = template.Must(template.New("viewer").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 1em; }
+table { border-collapse: collapse; width: 100%; }
+td, th { padding: 2px 8px; text-align: left; vertical-align: top; }
+td.line { font-family: monospace; white-space: pre-wrap; }
+tr.error { color: #c00; }
+tr.warn { color: #a60; }
+tr.debug { color: #888; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<form method="get">
+<select name="level">
+<option value="">ALL</option>
+{{- range .Levels}}
+<option{{if eq . $.Level}} selected{{end}}>{{.}}</option>
+{{- end}}
+</select>
+<input type="search" name="q" value="{{.Query}}" placeholder="search">
+<input type="number" name="n" value="{{.Limit}}" min="1">
+<input type="submit" value="Filter">
+</form>
+<p>{{len .Rows}} of {{.Total}} records</p>
+<table>
+<tr><th>Level</th><th>Line</th></tr>
+{{- range .Rows}}
+<tr class="{{.Class}}"><td>{{.Level}}</td><td class="line">{{.Line}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// Create a new [nslog.LogViewer] object, which renders records kept by the ring handler.
+func NewLogViewer(ring *RingHandler, options *LogViewerOptions) *LogViewer {
+	// set default parameters
+	if options == nil {
+		options = &LogViewerOptions{}
+	}
+	if options.Limit <= 0 {
+		options.Limit = DEFAULT_LOG_VIEWER_LIMIT
+	}
+	if options.Title == "" {
+		options.Title = "Logs"
+	}
+
+	return &LogViewer{
+		ring:    ring,
+		options: *options,
+	}
+}
+
+func (viewer *LogViewer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	page := logViewerPage{
+		Title:  viewer.options.Title,
+		Level:  strings.ToUpper(query.Get("level")),
+		Query:  query.Get("q"),
+		Limit:  viewer.options.Limit,
+		Levels: []string{"DEBUG", "INFO", "WARN", "ERROR"},
+	}
+	level := slog.Level(math.MinInt)
+	if page.Level != "" {
+		var err error
+		level, err = ParseLevel(page.Level)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if n := query.Get("n"); n != "" {
+		limit, err := strconv.Atoi(n)
+		if err != nil || limit <= 0 {
+			http.Error(writer, "nslog: invalid number of records "+strconv.Quote(n), http.StatusBadRequest)
+			return
+		}
+		page.Limit = limit
+	}
+	search := strings.ToLower(page.Query)
+
+	// filter records from the newest one, and show them from the oldest one
+	entries := viewer.ring.ring.snapshot()
+	page.Total = len(entries)
+	for i := len(entries) - 1; i >= 0 && len(page.Rows) < page.Limit; i-- {
+		entry := entries[i]
+		if entry.record.Level < level {
+			continue
+		}
+		line := string(bytes.TrimRight(StripColor(entry.line), "\r\n"))
+		if search != "" && !strings.Contains(strings.ToLower(line), search) {
+			continue
+		}
+		page.Rows = append(page.Rows, logViewerRow{
+			Level: entry.record.Level.String(),
+			Class: logViewerClass(entry.record.Level),
+			Line:  line,
+		})
+	}
+	slices.Reverse(page.Rows)
+
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = logViewerTemplate.Execute(writer, page)
+}
+
+func logViewerClass(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "error"
+	case level >= slog.LevelWarn:
+		return "warn"
+	case level >= slog.LevelInfo:
+		return "info"
+	default:
+		return "debug"
+	}
+}
@@ -0,0 +1,57 @@
+package nslog
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogViewer(t *testing.T) {
+	ring := NewRingHandler(nil, 10, &LogHandlerOptions{OmitTime: true, AddColor: true})
+	log := slog.New(ring)
+	log.Debug("debug message")
+	log.Info("connection <opened>")
+	log.Warn("connection timeout")
+	log.Error("request failed", "err", "Timeout")
+	viewer := NewLogViewer(ring, &LogViewerOptions{Title: "App Logs"})
+
+	recorder := httptest.NewRecorder()
+	viewer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/logs", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/html; charset=utf-8", recorder.Header().Get("Content-Type"))
+	body := recorder.Body.String()
+	assert.Contains(t, body, "<title>App Logs</title>")
+	assert.Contains(t, body, "<p>4 of 4 records</p>")
+	assert.Contains(t, body, `<tr class="debug"><td>DEBUG</td><td class="line">DEBUG debug message</td></tr>`)
+	assert.Contains(t, body, `<td class="line">INFO. connection &lt;opened&gt;</td>`)
+	assert.NotContains(t, body, "\x1b")
+	assert.Less(t, strings.Index(body, "debug message"), strings.Index(body, "request failed"))
+
+	recorder = httptest.NewRecorder()
+	viewer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/logs?level=warn&q=TIMEOUT", nil))
+	body = recorder.Body.String()
+	assert.Contains(t, body, "<p>2 of 4 records</p>")
+	assert.Contains(t, body, "<option selected>WARN</option>")
+	assert.Contains(t, body, `value="TIMEOUT"`)
+	assert.Contains(t, body, "connection timeout")
+	assert.Contains(t, body, "request failed")
+
+	recorder = httptest.NewRecorder()
+	viewer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/logs?n=1", nil))
+	body = recorder.Body.String()
+	assert.Contains(t, body, "<p>1 of 4 records</p>")
+	assert.Contains(t, body, "request failed")
+}
+
+func TestLogViewerInvalidQuery(t *testing.T) {
+	viewer := NewLogViewer(NewRingHandler(nil, 10, nil), nil)
+	for _, query := range []string{"?level=unknown", "?n=0", "?n=x"} {
+		recorder := httptest.NewRecorder()
+		viewer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/logs"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, query)
+	}
+}
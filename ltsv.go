@@ -0,0 +1,92 @@
+package nslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+var ltsvValueReplacer = strings.NewReplacer("\t", "\\t", "\n", "\\n", "\r", "\\r")
+var ltsvLabelReplacer = strings.NewReplacer("\t", "_", "\n", "_", "\r", "_", ":", "_")
+
+var ltsvLevels = map[slog.Level]string{
+	LevelFatal:      "FATAL",
+	slog.LevelError: "ERROR",
+	slog.LevelWarn:  "WARN",
+	slog.LevelInfo:  "INFO",
+	slog.LevelDebug: "DEBUG",
+}
+
+// Format the record as Labeled Tab-Separated Values by FormatLTSV option, which is a line without continuation lines.
+// Attributes are resolved in the same way as the text format, and attributes of the handler are qualified by their groups.
+func (handler *LogHandler) formatLTSV(ctx context.Context, record slog.Record) []byte {
+	var fields []string
+	field := func(label string, value string) string {
+		return ltsvLabelReplacer.Replace(label) + ":" + ltsvValueReplacer.Replace(value)
+	}
+
+	if handler.options.AddSequence {
+		fields = append(fields, field("seq", strconv.FormatUint(handler.state.sequence.Add(1), 10)))
+	}
+	if !handler.options.OmitTime && !record.Time.IsZero() {
+		recordTime := record.Time
+		if handler.options.UseUTC {
+			recordTime = recordTime.UTC()
+		}
+		fields = append(fields, field("time", recordTime.Format(handler.options.TimeLayout)))
+	}
+	if handler.options.AddElapsed && !record.Time.IsZero() {
+		fields = append(fields, field("elapsed", record.Time.Sub(handler.state.start).Round(time.Microsecond).String()))
+	}
+	if handler.options.AddDelta && !record.Time.IsZero() {
+		fields = append(fields, field("delta", handler.delta(record.Time).Round(time.Microsecond).String()))
+	}
+	if handler.pid != "" {
+		fields = append(fields, field("pid", handler.pid))
+	}
+	if handler.options.AddGoroutineID {
+		fields = append(fields, field("goroutine", fmt.Sprintf("%08X", goroutineID())))
+	}
+	level, ok := ltsvLevels[record.Level]
+	if !ok {
+		level = record.Level.String()
+	}
+	fields = append(fields, field("level", level))
+	fields = append(fields, field("msg", record.Message))
+
+	// attributes of the handler and the record
+	var attributes []string
+	for _, attribute := range handler.flatAttrs(ctx, record, true) {
+		attributes = append(attributes, field(attribute.key, attribute.value))
+	}
+	if handler.options.TraceFormat != TraceFormatNone {
+		spanContext := oteltrace.SpanContextFromContext(ctx)
+		if spanContext.IsValid() {
+			attributes = append(attributes, field("trace_id", spanContext.TraceID().String()), field("span_id", spanContext.SpanID().String()))
+		}
+	}
+	attributesText := strings.Join(attributes, "\t")
+	if attributesText != "" {
+		fields = append(fields, attributesText)
+	}
+
+	if record.Level >= handler.options.AddSourceLevel.Level() && record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		source := strings.TrimSuffix(strings.TrimPrefix(handler.sourceString(frame), "("), ")")
+		if source != "" {
+			fields = append(fields, field("source", source))
+		}
+	}
+
+	line := strings.Join(fields, "\t")
+	if handler.options.MaxLineLength > 0 {
+		line = truncateLine(line, attributesText, handler.options.MaxLineLength)
+	}
+	return []byte(line + handler.options.LineEnding)
+}
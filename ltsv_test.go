@@ -0,0 +1,35 @@
+package nslog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatLTSV(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{Format: FormatLTSV, AddColor: true})
+	log.With("key1", "val1").WithGroup("Main").With("key2", "val2").Info("log message", "key3", "val\t3")
+	log.Warn("warn message")
+	lines := bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n"))
+	assert.Len(t, lines, 2)
+	assert.Regexp(t, "^time:"+DEFAULT_TIME_REGEXP+"\tlevel:INFO\tmsg:log message\tkey1:val1\tMain\\.key2:val2\tMain\\.key3:val\\\\t3$", string(lines[0]))
+	assert.Regexp(t, "^time:"+DEFAULT_TIME_REGEXP+"\tlevel:WARN\tmsg:warn message\tsource:ltsv_test\\.go:[0-9]+$", string(lines[1]))
+}
+
+func TestFormatLTSVOptions(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{Format: FormatLTSV, OmitTime: true, AddSequence: true, Level: slog.LevelDebug, SortAttrs: true, DropKeys: []string{"password"}})
+	log.Debug("log message", "b", 2, "a:b", 1, "password", "secret")
+	assert.Equal(t, "seq:1\tlevel:DEBUG\tmsg:log message\ta_b:1\tb:2\n", buf.String())
+}
+
+func TestFormatLTSVEnv(t *testing.T) {
+	t.Setenv("GO_NSLOG_FORMAT", "LTSV")
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true})
+	log.Info("log message")
+	assert.Equal(t, "level:INFO\tmsg:log message\n", buf.String())
+}
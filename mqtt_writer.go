@@ -0,0 +1,228 @@
+package nslog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DEFAULT_MQTT_BUFFER_SIZE = 1 << 20
+const DEFAULT_MQTT_RETRY_INTERVAL = 5 * time.Second
+
+// A client to publish messages to MQTT, which is implemented by [nslog.MQTTPublishFunc] to adapt an existing connection such as:
+//
+//	nslog.MQTTPublishFunc(func(topic string, qos byte, retained bool, payload []byte) error {
+//		token := client.Publish(topic, qos, retained, payload)
+//		token.Wait()
+//		return token.Error()
+//	})
+type MQTTPublisher interface {
+	Publish(topic string, qos byte, retained bool, payload []byte) error
+}
+
+// A function to implement [nslog.MQTTPublisher].
+type MQTTPublishFunc func(topic string, qos byte, retained bool, payload []byte) error
+
+func (f MQTTPublishFunc) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	return f(topic, qos, retained, payload)
+}
+
+// An option to customize [nslog.MQTTWriter].
+type MQTTWriterOptions struct {
+	QoS           byte          // Set QoS of messages, which is 0, 1, or 2. (default: 0)
+	Retained      bool          // Publish messages as retained messages if it is true. (default: false)
+	BufferSize    int           // Set maximum bytes of messages buffered while offline. The oldest messages are dropped if it is exceeded. (default: 1 MiB)
+	RetryInterval time.Duration // Set interval to retry publishing after failure. (default: 5 seconds)
+	OnError       func(error)   // Set function called when publishing is failed. (default: nil)
+}
+
+// Counters of [nslog.MQTTWriter].
+type MQTTWriterStats struct {
+	Published uint64 `json:"published"` // number of messages published
+	Dropped   uint64 `json:"dropped"`   // number of messages dropped because the buffer was full or the writer was closed
+	Errors    uint64 `json:"errors"`    // number of publishes failed
+	Buffered  int    `json:"buffered"`  // bytes buffered in memory currently
+}
+
+// A writer to publish lines to an MQTT topic, so IoT devices can send logs on the connection which they already maintain.
+// Each Write is published as a message without the trailing newline by a goroutine, and messages are buffered in memory
+// and published later in order while the broker is offline.
+type MQTTWriter struct {
+	publisher  MQTTPublisher
+	topic      string
+	options    MQTTWriterOptions
+	mutex      sync.Mutex
+	cond       *sync.Cond
+	pending    [][]byte
+	size       int  // bytes of pending messages
+	publishing bool // whether the goroutine is publishing
+	closed     bool
+	stats      MQTTWriterStats
+	closing    chan struct{} // closed by Close to stop waiting for retry
+	done       chan struct{} // closed when the goroutine exits
+}
+
+// Create a new [nslog.MQTTWriter] object, which starts goroutine to publish messages to the topic.
+// Call [MQTTWriter.Close] to publish the buffered messages and stop the goroutine.
+func NewMQTTWriter(publisher MQTTPublisher, topic string, options *MQTTWriterOptions) (*MQTTWriter, error) {
+	// set default parameters
+	if options == nil {
+		options = &MQTTWriterOptions{}
+	}
+	if options.BufferSize <= 0 {
+		options.BufferSize = DEFAULT_MQTT_BUFFER_SIZE
+	}
+	if options.RetryInterval <= 0 {
+		options.RetryInterval = DEFAULT_MQTT_RETRY_INTERVAL
+	}
+
+	if topic == "" || strings.ContainsAny(topic, "+#") {
+		return nil, fmt.Errorf("nslog: invalid mqtt topic %q", topic)
+	}
+	if options.QoS > 2 {
+		return nil, fmt.Errorf("nslog: invalid mqtt qos %d", options.QoS)
+	}
+
+	writer := &MQTTWriter{
+		publisher: publisher,
+		topic:     topic,
+		options:   *options,
+		closing:   make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	writer.cond = sync.NewCond(&writer.mutex)
+	go writer.run()
+	return writer, nil
+}
+
+// Queue the data as a message without waiting for publishing.
+func (writer *MQTTWriter) Write(p []byte) (int, error) {
+	message := bytes.TrimSuffix(bytes.TrimSuffix(p, []byte("\n")), []byte("\r"))
+	message = bytes.Clone(message)
+
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	if writer.closed {
+		return 0, os.ErrClosed
+	}
+	if len(message) > writer.options.BufferSize {
+		writer.stats.Dropped++
+		return len(p), nil
+	}
+
+	// drop the oldest messages except the one being published
+	index := 0
+	if writer.publishing {
+		index = 1
+	}
+	for writer.size+len(message) > writer.options.BufferSize && len(writer.pending) > index {
+		writer.size -= len(writer.pending[index])
+		writer.pending = append(writer.pending[:index], writer.pending[index+1:]...)
+		writer.stats.Dropped++
+	}
+	writer.pending = append(writer.pending, message)
+	writer.size += len(message)
+	writer.cond.Broadcast()
+	return len(p), nil
+}
+
+// Wait until the buffered messages are published, or the context is done.
+func (writer *MQTTWriter) Flush(ctx context.Context) error {
+	stop := context.AfterFunc(ctx, func() {
+		writer.mutex.Lock()
+		writer.cond.Broadcast()
+		writer.mutex.Unlock()
+	})
+	defer stop()
+
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	for len(writer.pending) > 0 {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		writer.cond.Wait()
+	}
+	return nil
+}
+
+// Get counters of the writer.
+func (writer *MQTTWriter) Stats() MQTTWriterStats {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	stats := writer.stats
+	stats.Buffered = writer.size
+	return stats
+}
+
+// Publish the buffered messages and stop goroutine. Messages are dropped if publishing fails.
+// The connection of the publisher is not closed.
+func (writer *MQTTWriter) Close() error {
+	writer.mutex.Lock()
+	if !writer.closed {
+		writer.closed = true
+		close(writer.closing)
+	}
+	writer.cond.Broadcast()
+	writer.mutex.Unlock()
+	<-writer.done
+	return nil
+}
+
+func (writer *MQTTWriter) run() {
+	defer close(writer.done)
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	for {
+		for len(writer.pending) == 0 && !writer.closed {
+			writer.cond.Wait()
+		}
+		if len(writer.pending) == 0 {
+			return
+		}
+
+		message := writer.pending[0]
+		writer.publishing = true
+		writer.mutex.Unlock()
+		err := writer.publisher.Publish(writer.topic, writer.options.QoS, writer.options.Retained, message)
+		writer.mutex.Lock()
+		writer.publishing = false
+		if err == nil {
+			writer.pending = writer.pending[1:]
+			writer.size -= len(message)
+			writer.stats.Published++
+			writer.cond.Broadcast()
+			continue
+		}
+
+		writer.stats.Errors++
+		if writer.closed {
+			writer.stats.Dropped += uint64(len(writer.pending))
+			writer.pending = nil
+			writer.size = 0
+			writer.cond.Broadcast()
+		}
+		closed := writer.closed
+		writer.mutex.Unlock()
+		if writer.options.OnError != nil {
+			writer.options.OnError(fmt.Errorf("nslog: failed to publish mqtt message: %w", err))
+		}
+		if closed {
+			writer.mutex.Lock()
+			return
+		}
+
+		// keep the message to retry after the interval
+		timer := time.NewTimer(writer.options.RetryInterval)
+		select {
+		case <-timer.C:
+		case <-writer.closing:
+			timer.Stop()
+		}
+		writer.mutex.Lock()
+	}
+}
@@ -0,0 +1,98 @@
+package nslog
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mqttBroker struct {
+	mutex    sync.Mutex
+	online   bool
+	messages []string
+}
+
+func (broker *mqttBroker) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	if !broker.online {
+		return errors.New("not connected")
+	}
+	broker.messages = append(broker.messages, topic+" "+string(payload))
+	return nil
+}
+
+func (broker *mqttBroker) setOnline(online bool) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+	broker.online = online
+}
+
+func TestMQTTWriter(t *testing.T) {
+	broker := &mqttBroker{online: true}
+	writer, err := NewMQTTWriter(broker, "devices/1/logs", &MQTTWriterOptions{QoS: 1})
+	assert.NoError(t, err)
+	log := NewLogger(writer, &LogHandlerOptions{OmitTime: true})
+	log.Info("message 1")
+	log.Info("message 2")
+	assert.NoError(t, writer.Flush(context.Background()))
+	assert.NoError(t, writer.Close())
+
+	assert.Equal(t, []string{"devices/1/logs INFO. message 1", "devices/1/logs INFO. message 2"}, broker.messages)
+	assert.Equal(t, MQTTWriterStats{Published: 2}, writer.Stats())
+	_, err = writer.Write([]byte("message 3\n"))
+	assert.Error(t, err)
+}
+
+func TestMQTTWriterOffline(t *testing.T) {
+	broker := &mqttBroker{}
+	var mutex sync.Mutex
+	var errs []error
+	writer, _ := NewMQTTWriter(broker, "logs", &MQTTWriterOptions{BufferSize: 20, RetryInterval: time.Millisecond, OnError: func(err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		errs = append(errs, err)
+	}})
+	_, _ = writer.Write([]byte("message 1\n"))
+	_, _ = writer.Write([]byte("message 2\n"))
+	_, _ = writer.Write([]byte("message 3\n"))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, writer.Flush(ctx), context.DeadlineExceeded)
+
+	broker.setOnline(true)
+	assert.NoError(t, writer.Flush(context.Background()))
+	assert.NoError(t, writer.Close())
+
+	// an old message is dropped by the buffer size
+	assert.Len(t, broker.messages, 2)
+	assert.Equal(t, "logs message 3", broker.messages[1])
+	stats := writer.Stats()
+	assert.Equal(t, uint64(2), stats.Published)
+	assert.Equal(t, uint64(1), stats.Dropped)
+	assert.Greater(t, stats.Errors, uint64(0))
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.ErrorContains(t, errs[0], "nslog: failed to publish mqtt message: not connected")
+}
+
+func TestMQTTWriterClose(t *testing.T) {
+	broker := &mqttBroker{}
+	writer, _ := NewMQTTWriter(broker, "logs", &MQTTWriterOptions{RetryInterval: time.Hour})
+	_, _ = writer.Write([]byte("message 1\n"))
+	_, _ = writer.Write([]byte("message 2\n"))
+	assert.NoError(t, writer.Close())
+	assert.Empty(t, broker.messages)
+	assert.Equal(t, uint64(2), writer.Stats().Dropped)
+}
+
+func TestMQTTWriterInvalid(t *testing.T) {
+	_, err := NewMQTTWriter(MQTTPublishFunc(nil), "logs/#", nil)
+	assert.Error(t, err)
+	_, err = NewMQTTWriter(MQTTPublishFunc(nil), "logs", &MQTTWriterOptions{QoS: 3})
+	assert.Error(t, err)
+}
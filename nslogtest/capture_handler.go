@@ -0,0 +1,229 @@
+// The nslogtest package provides a handler to capture records and helpers to assert them in tests,
+// so tests do not need to match raw output by regexp.
+package nslogtest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// An entry captured by [nslogtest.CaptureHandler].
+type Entry struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Attrs   map[string]slog.Value // attributes of the handler and the record, where keys are qualified by groups such as "group.key"
+	Groups  []string              // groups of the handler
+	Source  *slog.Source          // source of the record, which is nil if the record has no PC
+}
+
+// An option to customize [nslogtest.CaptureHandler].
+type CaptureHandlerOptions struct {
+	Level slog.Leveler // Set level to capture records. (default: slog.LevelDebug)
+}
+
+// A handler to capture records as entries for assertions.
+type CaptureHandler struct {
+	state  *captureState
+	level  slog.Leveler
+	attrs  []slog.Attr // attributes qualified by groups
+	groups []string
+}
+
+type captureState struct {
+	mutex   sync.Mutex
+	entries []Entry
+}
+
+// Create a new [nslogtest.CaptureHandler] object.
+func NewCaptureHandler(options *CaptureHandlerOptions) *CaptureHandler {
+	// set default parameters
+	if options == nil {
+		options = &CaptureHandlerOptions{}
+	}
+	if options.Level == nil {
+		options.Level = slog.LevelDebug
+	}
+
+	return &CaptureHandler{
+		state: &captureState{},
+		level: options.Level,
+	}
+}
+
+func (handler *CaptureHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= handler.level.Level()
+}
+
+func (handler *CaptureHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	new_attrs := slices.Clip(handler.attrs)
+	for _, attr := range attrs {
+		new_attrs = appendAttr(new_attrs, handler.groups, attr)
+	}
+	return &CaptureHandler{
+		state:  handler.state,
+		level:  handler.level,
+		attrs:  new_attrs,
+		groups: handler.groups,
+	}
+}
+
+func (handler *CaptureHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return handler
+	}
+	return &CaptureHandler{
+		state:  handler.state,
+		level:  handler.level,
+		attrs:  handler.attrs,
+		groups: append(slices.Clip(handler.groups), name),
+	}
+}
+
+func (handler *CaptureHandler) Handle(_ context.Context, record slog.Record) error {
+	attrs := slices.Clip(handler.attrs)
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = appendAttr(attrs, handler.groups, attr)
+		return true
+	})
+	entry := Entry{
+		Time:    record.Time,
+		Level:   record.Level,
+		Message: record.Message,
+		Attrs:   map[string]slog.Value{},
+		Groups:  handler.groups,
+	}
+	for _, attr := range attrs {
+		entry.Attrs[attr.Key] = attr.Value
+	}
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		entry.Source = &slog.Source{Function: frame.Function, File: frame.File, Line: frame.Line}
+	}
+
+	handler.state.mutex.Lock()
+	defer handler.state.mutex.Unlock()
+	handler.state.entries = append(handler.state.entries, entry)
+	return nil
+}
+
+// Append the attribute with key qualified by the groups, where groups are flattened and empty attributes are dropped.
+func appendAttr(attrs []slog.Attr, groups []string, attr slog.Attr) []slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return attrs
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			groups = append(slices.Clip(groups), attr.Key)
+		}
+		for _, member := range attr.Value.Group() {
+			attrs = appendAttr(attrs, groups, member)
+		}
+		return attrs
+	}
+	if len(groups) > 0 {
+		attr.Key = strings.Join(groups, ".") + "." + attr.Key
+	}
+	return append(attrs, attr)
+}
+
+// Get entries captured by the handler and derived handlers.
+func (handler *CaptureHandler) Entries() []Entry {
+	handler.state.mutex.Lock()
+	defer handler.state.mutex.Unlock()
+	return slices.Clone(handler.state.entries)
+}
+
+// Remove entries captured by the handler and derived handlers.
+func (handler *CaptureHandler) Reset() {
+	handler.state.mutex.Lock()
+	defer handler.state.mutex.Unlock()
+	handler.state.entries = nil
+}
+
+// An interface implemented by [testing.T] and [testing.B].
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// Find entries of the level, which have message containing the string and the attributes.
+// The attributes are given as pairs of key and value such as slog.Logger.Info, and values are compared by [slog.Value.Equal].
+func (handler *CaptureHandler) Find(level slog.Level, msgContains string, attrs ...any) []Entry {
+	expected := argsToAttrs(attrs)
+	var found []Entry
+	for _, entry := range handler.Entries() {
+		if entry.Level != level || !strings.Contains(entry.Message, msgContains) {
+			continue
+		}
+		matched := true
+		for _, attr := range expected {
+			value, ok := entry.Attrs[attr.Key]
+			if !ok || !value.Equal(attr.Value) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			found = append(found, entry)
+		}
+	}
+	return found
+}
+
+// Assert that an entry of the level is captured, which has message containing the string and the attributes.
+func AssertLogged(t TestingT, handler *CaptureHandler, level slog.Level, msgContains string, attrs ...any) bool {
+	t.Helper()
+	if len(handler.Find(level, msgContains, attrs...)) == 0 {
+		t.Errorf("no %s entry with message containing %q and attributes %v:\n%s", level, msgContains, attrs, handler.dump())
+		return false
+	}
+	return true
+}
+
+// Assert that no entry of the level is captured, which has message containing the string and the attributes.
+func AssertNotLogged(t TestingT, handler *CaptureHandler, level slog.Level, msgContains string, attrs ...any) bool {
+	t.Helper()
+	if len(handler.Find(level, msgContains, attrs...)) > 0 {
+		t.Errorf("unexpected %s entry with message containing %q and attributes %v:\n%s", level, msgContains, attrs, handler.dump())
+		return false
+	}
+	return true
+}
+
+// Get captured entries as string for failure messages.
+func (handler *CaptureHandler) dump() string {
+	var builder strings.Builder
+	for _, entry := range handler.Entries() {
+		keys := make([]string, 0, len(entry.Attrs))
+		for key := range entry.Attrs {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		fmt.Fprintf(&builder, "\t%s %s", entry.Level, entry.Message)
+		for _, key := range keys {
+			fmt.Fprintf(&builder, " %s=%s", key, entry.Attrs[key])
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// Convert pairs of key and value to attributes, same as arguments of slog.Logger.Info.
+func argsToAttrs(args []any) []slog.Attr {
+	record := slog.NewRecord(time.Time{}, 0, "", 0)
+	record.Add(args...)
+	var attrs []slog.Attr
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = appendAttr(attrs, nil, attr)
+		return true
+	})
+	return attrs
+}
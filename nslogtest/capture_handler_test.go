@@ -0,0 +1,81 @@
+package nslogtest
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/slogtest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	errors []string
+}
+
+func (recorder *recorder) Helper() {}
+
+func (recorder *recorder) Errorf(format string, args ...any) {
+	recorder.errors = append(recorder.errors, fmt.Sprintf(format, args...))
+}
+
+func TestCaptureHandler(t *testing.T) {
+	handler := NewCaptureHandler(nil)
+	log := slog.New(handler).With("service", "api").WithGroup("db")
+	log.Debug("query executed", "rows", 3, slog.Group("conn", "id", 7))
+	log.Warn("slow query", "elapsed_ms", 120)
+
+	entries := handler.Entries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, []string{"db"}, entries[0].Groups)
+	assert.Equal(t, int64(3), entries[0].Attrs["db.rows"].Int64())
+	assert.Equal(t, int64(7), entries[0].Attrs["db.conn.id"].Int64())
+	assert.Equal(t, "api", entries[0].Attrs["service"].String())
+	assert.Contains(t, entries[0].Source.File, "capture_handler_test.go")
+
+	AssertLogged(t, handler, slog.LevelDebug, "query", "db.rows", 3, "service", "api")
+	AssertNotLogged(t, handler, slog.LevelError, "query")
+
+	failed := &recorder{}
+	assert.False(t, AssertLogged(failed, handler, slog.LevelWarn, "slow", "db.elapsed_ms", 100))
+	assert.False(t, AssertNotLogged(failed, handler, slog.LevelWarn, "slow"))
+	assert.Len(t, failed.errors, 2)
+	assert.Contains(t, failed.errors[0], "WARN slow query db.elapsed_ms=120 service=api")
+
+	handler.Reset()
+	assert.Empty(t, handler.Entries())
+}
+
+func TestCaptureHandlerSlogtest(t *testing.T) {
+	handler := NewCaptureHandler(nil)
+	err := slogtest.TestHandler(handler, func() []map[string]any {
+		var results []map[string]any
+		for _, entry := range handler.Entries() {
+			result := map[string]any{slog.LevelKey: entry.Level, slog.MessageKey: entry.Message}
+			if !entry.Time.IsZero() {
+				result[slog.TimeKey] = entry.Time
+			}
+			for key, value := range entry.Attrs {
+				// nest attributes by groups
+				current := result
+				for {
+					group, rest, found := strings.Cut(key, ".")
+					if !found {
+						current[key] = value.Any()
+						break
+					}
+					next, ok := current[group].(map[string]any)
+					if !ok {
+						next = map[string]any{}
+						current[group] = next
+					}
+					current, key = next, rest
+				}
+			}
+			results = append(results, result)
+		}
+		return results
+	})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,127 @@
+package nslogtest
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikiepure/nslog"
+)
+
+// A fixed time substituted for time of records by [nslogtest.GoldenHandler].
+var GOLDEN_TIME = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Environment variable to update golden files by [nslogtest.AssertGolden] instead of comparing them.
+const UPDATE_GOLDEN_ENV = "GO_NSLOG_UPDATE_GOLDEN"
+
+// Get options for deterministic output: color, PID, goroutine ID, and source are not added, time is output in UTC,
+// attributes are sorted by key, and environment variables are not used.
+func GoldenOptions() *nslog.LogHandlerOptions {
+	return &nslog.LogHandlerOptions{
+		Level:          slog.LevelDebug,
+		AddSourceLevel: slog.Level(math.MaxInt32),
+		UseUTC:         true,
+		SortAttrs:      true,
+		TraceFormat:    nslog.TraceFormatNone,
+		DisableEnv:     true,
+	}
+}
+
+// A handler to render records for golden-file comparison, where time of records is substituted by [nslogtest.GOLDEN_TIME].
+type GoldenHandler struct {
+	next   slog.Handler
+	output *goldenOutput
+}
+
+type goldenOutput struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+}
+
+func (output *goldenOutput) Write(p []byte) (int, error) {
+	output.mutex.Lock()
+	defer output.mutex.Unlock()
+	return output.buf.Write(p)
+}
+
+// Create a new [nslogtest.GoldenHandler] object. The options are modified for deterministic output if they are nil,
+// which is same as [nslogtest.GoldenOptions].
+func NewGoldenHandler(options *nslog.LogHandlerOptions) *GoldenHandler {
+	// set default parameters
+	if options == nil {
+		options = GoldenOptions()
+	}
+
+	output := &goldenOutput{}
+	return &GoldenHandler{
+		next:   nslog.NewLogHandler(output, options),
+		output: output,
+	}
+}
+
+func (handler *GoldenHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return handler.next.Enabled(ctx, level)
+}
+
+func (handler *GoldenHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &GoldenHandler{
+		next:   handler.next.WithAttrs(attrs),
+		output: handler.output,
+	}
+}
+
+func (handler *GoldenHandler) WithGroup(name string) slog.Handler {
+	return &GoldenHandler{
+		next:   handler.next.WithGroup(name),
+		output: handler.output,
+	}
+}
+
+func (handler *GoldenHandler) Handle(ctx context.Context, record slog.Record) error {
+	if !record.Time.IsZero() {
+		record.Time = GOLDEN_TIME
+	}
+	return handler.next.Handle(ctx, record)
+}
+
+// Get output rendered by the handler and derived handlers.
+func (handler *GoldenHandler) Bytes() []byte {
+	handler.output.mutex.Lock()
+	defer handler.output.mutex.Unlock()
+	return bytes.Clone(handler.output.buf.Bytes())
+}
+
+// Assert that the output is same as content of the golden file. The golden file is written instead of comparing
+// if environment variable GO_NSLOG_UPDATE_GOLDEN is "true" or "1".
+func AssertGolden(t TestingT, path string, output []byte) bool {
+	t.Helper()
+	update := os.Getenv(UPDATE_GOLDEN_ENV)
+	if strings.EqualFold(update, "true") || update == "1" {
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err == nil {
+			err = os.WriteFile(path, output, 0o644)
+		}
+		if err != nil {
+			t.Errorf("failed to update golden file: %v", err)
+			return false
+		}
+		return true
+	}
+
+	golden, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("failed to read golden file (set %s=1 to create it): %v", UPDATE_GOLDEN_ENV, err)
+		return false
+	}
+	if !bytes.Equal(golden, output) {
+		t.Errorf("output differs from golden file %s (set %s=1 to update it):\n--- golden\n%s--- output\n%s", path, UPDATE_GOLDEN_ENV, golden, output)
+		return false
+	}
+	return true
+}
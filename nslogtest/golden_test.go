@@ -0,0 +1,34 @@
+package nslogtest
+
+import (
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoldenHandler(t *testing.T) {
+	t.Setenv("GO_NSLOG_ADD_PID", "true")
+	handler := NewGoldenHandler(nil)
+	log := slog.New(handler).WithGroup("Main")
+	log.Info("log message1", "key2", "val2", "key1", "val1")
+	log.Error("log message2")
+	log.Debug("log message3")
+	AssertGolden(t, filepath.Join("testdata", "golden.log"), handler.Bytes())
+}
+
+func TestAssertGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "golden.log")
+	failed := &recorder{}
+	assert.False(t, AssertGolden(failed, path, []byte("line\n")))
+
+	t.Setenv(UPDATE_GOLDEN_ENV, "1")
+	assert.True(t, AssertGolden(t, path, []byte("line\n")))
+
+	t.Setenv(UPDATE_GOLDEN_ENV, "")
+	assert.True(t, AssertGolden(t, path, []byte("line\n")))
+	assert.False(t, AssertGolden(failed, path, []byte("other\n")))
+	assert.Len(t, failed.errors, 2)
+	assert.Contains(t, failed.errors[1], "--- golden\nline\n--- output\nother\n")
+}
@@ -0,0 +1,108 @@
+package nslog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+)
+
+// Open writer of the output such as "stderr", "stdout", path of file, or syslog URL such as "syslog://" for local syslog,
+// "syslog://host:514" for UDP, "syslog+tcp://host:514" for TCP, or socket URL such as "unix:///run/collector.sock" and
+// "unixgram:///run/collector.sock" (`unix://\\.\pipe\name` for Windows named pipe). Empty output is "stderr".
+// The writer should be closed by the caller if it implements [io.Closer] other than os.Stderr and os.Stdout.
+func OpenOutput(output string) (io.Writer, error) {
+	scheme, target, err := parseOutput(output)
+	if err != nil {
+		return nil, err
+	}
+	switch scheme {
+	case "stderr":
+		return os.Stderr, nil
+	case "stdout":
+		return os.Stdout, nil
+	case "syslog":
+		return openSyslog("udp", target)
+	case "syslog+tcp":
+		return openSyslog("tcp", target)
+	case "unix":
+		return NewSocketWriter(target, nil), nil
+	case "unixgram":
+		return NewSocketWriter(target, &SocketWriterOptions{Network: "unixgram"}), nil
+	default:
+		return NewFileWriter(target, nil)
+	}
+}
+
+// Parse the output into the scheme such as "syslog" and "file" and the target such as the address and the path,
+// so that the output can be validated without opening it. An unknown scheme is an error instead of a path of file.
+func parseOutput(output string) (scheme string, target string, err error) {
+	switch output {
+	case "", "stderr":
+		return "stderr", "", nil
+	case "stdout":
+		return "stdout", "", nil
+	}
+	scheme, target, ok := strings.Cut(output, "://")
+	if !ok {
+		return "file", output, nil
+	}
+	switch scheme {
+	case "syslog":
+		if target == "" {
+			// local syslog
+			return scheme, target, nil
+		}
+	case "syslog+tcp":
+	case "unix", "unixgram":
+		if target == "" {
+			return "", "", fmt.Errorf("nslog: invalid output %q, which has no path of socket", output)
+		}
+		return scheme, target, nil
+	default:
+		return "", "", fmt.Errorf("nslog: invalid output %q, which has unknown scheme %q", output, scheme)
+	}
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		return "", "", fmt.Errorf("nslog: invalid output %q: %w", output, err)
+	}
+	return scheme, target, nil
+}
+
+// Create a new [slog.Logger] object that writes to the output given by environment variable GO_NSLOG_OUTPUT,
+// so that output can be redirected purely through environment configuration. See [nslog.OpenOutput] for values.
+// The prefix follows EnvPrefix option, and os.Stderr is used if DisableEnv option is true.
+// If the output cannot be opened, os.Stderr is used and a warning is logged.
+func NewDefaultLogger(options *LogHandlerOptions) *slog.Logger {
+	if options == nil {
+		options = &LogHandlerOptions{}
+	}
+	output := options.getenv("OUTPUT")
+	writer, err := OpenOutput(output)
+	if err != nil {
+		logger := NewLogger(os.Stderr, options)
+		logger.Warn("nslog: failed to open output, use stderr instead", "output", output, "error", err)
+		return logger
+	}
+	return NewLogger(writer, options)
+}
+
+// Create a new [slog.Logger] object that writes to the output given by environment variable GO_NSLOG_OUTPUT like
+// [nslog.NewDefaultLogger], or return an error instead of falling back to os.Stderr if the output cannot be opened,
+// or the options or environment variables overriding them are invalid. See [nslog.NewLogHandlerE].
+func NewDefaultLoggerE(options *LogHandlerOptions) (*slog.Logger, error) {
+	if options == nil {
+		options = &LogHandlerOptions{}
+	}
+	err := errors.Join(options.Validate(), options.validateEnv())
+	if err != nil {
+		return nil, err
+	}
+	writer, err := OpenOutput(options.getenv("OUTPUT"))
+	if err != nil {
+		return nil, err
+	}
+	return NewLogger(writer, options), nil
+}
@@ -0,0 +1,16 @@
+//go:build !windows && !plan9
+
+package nslog
+
+import (
+	"io"
+	"log/syslog"
+)
+
+// Open syslog writer. Local syslog is used if the address is empty.
+func openSyslog(network string, address string) (io.Writer, error) {
+	if address == "" {
+		return syslog.New(syslog.LOG_INFO|syslog.LOG_USER, "")
+	}
+	return syslog.Dial(network, address, syslog.LOG_INFO|syslog.LOG_USER, "")
+}
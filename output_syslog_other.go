@@ -0,0 +1,12 @@
+//go:build windows || plan9
+
+package nslog
+
+import (
+	"errors"
+	"io"
+)
+
+func openSyslog(network string, address string) (io.Writer, error) {
+	return nil, errors.New("nslog: syslog is not supported on this platform")
+}
@@ -0,0 +1,107 @@
+package nslog
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenOutput(t *testing.T) {
+	writer, err := OpenOutput("")
+	assert.NoError(t, err)
+	assert.Same(t, os.Stderr, writer)
+
+	writer, err = OpenOutput("stdout")
+	assert.NoError(t, err)
+	assert.Same(t, os.Stdout, writer)
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	writer, err = OpenOutput(path)
+	assert.NoError(t, err)
+	assert.IsType(t, &FileWriter{}, writer)
+	assert.NoError(t, writer.(*FileWriter).Close())
+}
+
+func TestParseOutput(t *testing.T) {
+	for _, test := range []struct {
+		output string
+		scheme string
+		target string
+	}{
+		{"", "stderr", ""},
+		{"stdout", "stdout", ""},
+		{"app.log", "file", "app.log"},
+		{`C:\logs\app.log`, "file", `C:\logs\app.log`},
+		{"syslog://", "syslog", ""},
+		{"syslog+tcp://localhost:514", "syslog+tcp", "localhost:514"},
+		{"unixgram:///run/collector.sock", "unixgram", "/run/collector.sock"},
+	} {
+		scheme, target, err := parseOutput(test.output)
+		assert.NoError(t, err)
+		assert.Equal(t, test.scheme, scheme)
+		assert.Equal(t, test.target, target)
+	}
+
+	_, _, err := parseOutput("syslog+tcp://localhost")
+	assert.ErrorContains(t, err, `nslog: invalid output "syslog+tcp://localhost"`)
+	_, _, err = parseOutput("unix://")
+	assert.ErrorContains(t, err, "which has no path of socket")
+	_, err = OpenOutput("file:///var/log/app.log")
+	assert.ErrorContains(t, err, `which has unknown scheme "file"`)
+}
+
+func TestOpenOutputSyslog(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("syslog is not supported on windows")
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	writer, err := OpenOutput("syslog+tcp://" + listener.Addr().String())
+	assert.NoError(t, err)
+	NewLogger(writer, nil).Info("log message")
+
+	conn, err := listener.Accept()
+	assert.NoError(t, err)
+	defer conn.Close()
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	assert.NoError(t, err)
+	assert.Contains(t, line, "INFO. log message")
+}
+
+func TestNewDefaultLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv("GO_NSLOG_OUTPUT", path)
+	logger := NewDefaultLogger(nil)
+	logger.Info("log message")
+	assert.NoError(t, logger.Handler().(*LogHandler).writer.(*FileWriter).Close())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "INFO. log message\n")
+}
+
+func TestNewDefaultLoggerE(t *testing.T) {
+	t.Setenv("GO_NSLOG_OUTPUT", "unix://")
+	logger, err := NewDefaultLoggerE(nil)
+	assert.Nil(t, logger)
+	assert.ErrorContains(t, err, `nslog: invalid environment variable GO_NSLOG_OUTPUT="unix://"`)
+
+	// an error instead of falling back to stderr
+	t.Setenv("GO_NSLOG_OUTPUT", filepath.Join(t.TempDir(), "missing", "app.log"))
+	logger, err = NewDefaultLoggerE(nil)
+	assert.Nil(t, logger)
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv("GO_NSLOG_OUTPUT", path)
+	logger, err = NewDefaultLoggerE(nil)
+	assert.NoError(t, err)
+	assert.NoError(t, logger.Handler().(*LogHandler).writer.(*FileWriter).Close())
+}
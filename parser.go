@@ -0,0 +1,386 @@
+package nslog
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A record parsed from a line of the nslog text format by [nslog.Parse] or [nslog.Scanner].
+// The format is not escaped, so messages and values are split by heuristics: the first word such as "key=value" after
+// the message starts attributes, words without "=" are joined to the previous value,
+// and the first word of the message such as "name:" is parsed as a group.
+type ParsedRecord struct {
+	Sequence      uint64        // sequence number by AddSequence option, which is 0 if it is not output
+	Time          time.Time     // time of the record, which is zero if it is not output
+	Elapsed       time.Duration // elapsed time by AddElapsed option
+	Delta         time.Duration // delta time by AddDelta option
+	PID           int           // PID by AddPID option, which is 0 if it is not output
+	GoroutineID   uint64        // goroutine ID by AddGoroutineID option, which is 0 if it is not output
+	Level         slog.Level    // level of the record, which is slog.LevelInfo for unknown label such as "UNSET"
+	LevelLabel    string        // label of the level as output such as "WARN."
+	Groups        []string      // groups of the handler
+	Message       string        // message of the record
+	Attrs         []slog.Attr   // attributes of the handler and the record with string values, where keys are qualified by groups
+	TraceID       string        // trace ID by TraceFormatSuffix
+	SpanID        string        // span ID by TraceFormatSuffix
+	Source        string        // source without parentheses such as "main.go:12"
+	Continuations []string      // continuation lines such as expanded errors without the leading tab
+}
+
+// An option to customize [nslog.Parse] and [nslog.Scanner].
+type ParserOptions struct {
+	TimeLayout string         // Set layout of time of lines, same as TimeLayout option of the handler. (default: "2006/01/02 15:04:05")
+	Location   *time.Location // Set location to parse time without time zone, such as time.UTC for UseUTC option. (default: time.Local)
+
+	// Set version of the layout of lines such as FORMAT_VERSION.
+	// (default: 0, which uses the version in the file header for [nslog.Scanner], or FORMAT_VERSION)
+	FormatVersion int
+}
+
+// An error returned for lines which are not the nslog text format.
+var ErrNotNslogFormat = errors.New("nslog: line is not nslog format")
+
+// An error returned for lines of the version of the layout which is not supported, such as written by a newer nslog.
+var ErrUnsupportedFormatVersion = errors.New("nslog: format version is not supported")
+
+var parsedLevels = map[string]slog.Level{
+	"FATAL": LevelFatal, "F": LevelFatal, "[FATAL]": LevelFatal,
+	"ERROR": slog.LevelError, "E": slog.LevelError, "[ERROR]": slog.LevelError,
+	"WARN.": slog.LevelWarn, "WARN": slog.LevelWarn, "W": slog.LevelWarn, "[WARN]": slog.LevelWarn,
+	"INFO.": slog.LevelInfo, "INFO": slog.LevelInfo, "I": slog.LevelInfo, "[INFO]": slog.LevelInfo,
+	"DEBUG": slog.LevelDebug, "D": slog.LevelDebug, "[DEBUG]": slog.LevelDebug,
+	"UNSET": slog.LevelInfo,
+}
+
+var (
+	sourceRegexp = regexp.MustCompile(` \(([^()]+:[0-9]+(?: [^ ()]+)?)\)$`)
+	traceRegexp  = regexp.MustCompile(` \[([0-9a-f]{32})/([0-9a-f]{16})\]$`)
+	hexRegexp    = regexp.MustCompile(`^[0-9A-F]{4,}$`)
+	attrRegexp   = regexp.MustCompile(`^[^\s=\[\]]+=`)
+)
+
+func newParserOptions(options *ParserOptions) ParserOptions {
+	// set default parameters
+	if options == nil {
+		options = &ParserOptions{}
+	}
+	opts := *options
+	if opts.TimeLayout == "" {
+		opts.TimeLayout = DEFAULT_TIME_LAYOUT
+	}
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	if opts.FormatVersion == 0 {
+		opts.FormatVersion = FORMAT_VERSION
+	}
+	return opts
+}
+
+// Parse a line of the nslog text format. Color is stripped before parsing.
+func Parse(line string, options *ParserOptions) (*ParsedRecord, error) {
+	return parseLine(line, newParserOptions(options))
+}
+
+// Parse a line by the parser of the version of the layout.
+func parseLine(line string, options ParserOptions) (*ParsedRecord, error) {
+	switch options.FormatVersion {
+	case 1:
+		return parseLineV1(line, options)
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedFormatVersion, options.FormatVersion)
+	}
+}
+
+// Parse a line of the layout of version 1.
+func parseLineV1(line string, options ParserOptions) (*ParsedRecord, error) {
+	rest := strings.TrimSuffix(string(StripColor([]byte(line))), "\r")
+	record := &ParsedRecord{}
+	next := func() string {
+		word, _, _ := strings.Cut(rest, " ")
+		return word
+	}
+	consume := func(n int) {
+		rest = rest[min(n, len(rest)):]
+		rest = strings.TrimPrefix(rest, " ")
+	}
+
+	// sequence
+	if word := next(); strings.HasPrefix(word, "#") {
+		sequence, err := strconv.ParseUint(word[1:], 10, 64)
+		if err == nil {
+			record.Sequence = sequence
+			consume(len(word))
+		}
+	}
+
+	// time, which has as many words as the layout
+	words := strings.Count(options.TimeLayout, " ") + 1
+	if fields := strings.SplitN(rest, " ", words+1); len(fields) > words {
+		text := strings.Join(fields[:words], " ")
+		recordTime, err := time.ParseInLocation(options.TimeLayout, text, options.Location)
+		if err == nil {
+			record.Time = recordTime
+			consume(len(text))
+		}
+	}
+
+	// elapsed and delta
+	for _, duration := range []*time.Duration{&record.Elapsed, &record.Delta} {
+		word := next()
+		if !strings.HasPrefix(word, "+") {
+			break
+		}
+		parsed, err := time.ParseDuration(word[1:])
+		if err != nil {
+			break
+		}
+		*duration = parsed
+		consume(len(word))
+	}
+
+	// pid and goroutine id, which are hex strings before the level
+	for {
+		word := next()
+		if _, ok := parsedLevels[word]; ok || !hexRegexp.MatchString(word) {
+			break
+		}
+		value, err := strconv.ParseUint(word, 16, 64)
+		if err != nil {
+			break
+		}
+		if len(word) == 8 {
+			record.GoroutineID = value
+		} else {
+			record.PID = int(value)
+		}
+		consume(len(word))
+	}
+
+	// level
+	word := next()
+	level, ok := parsedLevels[word]
+	if !ok {
+		return nil, ErrNotNslogFormat
+	}
+	record.Level = level
+	record.LevelLabel = word
+	consume(len(word))
+	rest = strings.TrimLeft(rest, " ")
+
+	// source and trace at the end
+	if match := sourceRegexp.FindStringSubmatchIndex(rest); match != nil {
+		record.Source = rest[match[2]:match[3]]
+		rest = rest[:match[0]]
+	}
+	if match := traceRegexp.FindStringSubmatch(rest); match != nil {
+		record.TraceID = match[1]
+		record.SpanID = match[2]
+		rest = rest[:len(rest)-len(match[0])]
+	}
+
+	// groups and attributes of the handler such as "[key=val]Group1[key=val].Group2: "
+	if prefix, ok := parsePrefix(rest); ok {
+		record.Groups = prefix.groups
+		record.Attrs = prefix.attrs
+		rest = strings.TrimPrefix(rest[prefix.length:], " ")
+	}
+
+	// message and attributes
+	message, attrs := splitAttrs(rest)
+	record.Message = message
+	for _, attr := range attrs {
+		key, value, _ := strings.Cut(attr, "=")
+		record.Attrs = append(record.Attrs, slog.String(key, value))
+	}
+	return record, nil
+}
+
+type parsedPrefix struct {
+	groups []string
+	attrs  []slog.Attr
+	length int
+}
+
+// Parse groups and attributes of the handler such as "[key=val]Group1[key=val].Group2:".
+// It returns false if the text does not start with the prefix.
+func parsePrefix(text string) (parsedPrefix, bool) {
+	prefix := parsedPrefix{}
+	var group strings.Builder
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == ':' && (i+1 == len(text) || text[i+1] == ' '):
+			if i == 0 {
+				return prefix, false
+			}
+			if group.Len() > 0 {
+				prefix.groups = append(prefix.groups, group.String())
+			}
+			prefix.length = i + 1
+			return prefix, true
+		case c == '[':
+			end := strings.IndexByte(text[i:], ']')
+			if end < 0 {
+				return prefix, false
+			}
+			if group.Len() > 0 {
+				prefix.groups = append(prefix.groups, group.String())
+				group.Reset()
+			}
+			qualifier := strings.Join(prefix.groups, ".")
+			_, attrs := splitAttrs(" " + text[i+1:i+end])
+			if len(attrs) == 0 {
+				return prefix, false
+			}
+			for _, attr := range attrs {
+				key, value, _ := strings.Cut(attr, "=")
+				if qualifier != "" {
+					key = qualifier + "." + key
+				}
+				prefix.attrs = append(prefix.attrs, slog.String(key, value))
+			}
+			i += end
+		case c == '.':
+			if group.Len() > 0 {
+				prefix.groups = append(prefix.groups, group.String())
+				group.Reset()
+			}
+		case c == ' ':
+			return prefix, false
+		default:
+			group.WriteByte(c)
+		}
+	}
+	return prefix, false
+}
+
+// Split the text into message and attributes such as "key=value", where words without "=" are joined to the previous value.
+func splitAttrs(text string) (string, []string) {
+	words := strings.Split(text, " ")
+	for i, word := range words {
+		if !attrRegexp.MatchString(word) {
+			continue
+		}
+		var attrs []string
+		for _, word := range words[i:] {
+			if attrRegexp.MatchString(word) || len(attrs) == 0 {
+				attrs = append(attrs, word)
+			} else {
+				attrs[len(attrs)-1] += " " + word
+			}
+		}
+		return strings.Join(words[:i], " "), attrs
+	}
+	return text, nil
+}
+
+// A scanner to read records of the nslog text format, where continuation lines are attached to the previous record.
+type Scanner struct {
+	scanner *bufio.Scanner
+	options ParserOptions
+	line    string
+	record  *ParsedRecord
+	err     error
+	pending *string // line read ahead to find continuation lines
+
+	headerLayout  bool // whether time layout is given by the file header
+	headerVersion bool // whether format version is given by the file header
+}
+
+// Create a new [nslog.Scanner] object.
+func NewScanner(reader io.Reader, options *ParserOptions) *Scanner {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return &Scanner{
+		scanner:       scanner,
+		options:       newParserOptions(options),
+		headerLayout:  options == nil || options.TimeLayout == "",
+		headerVersion: options == nil || options.FormatVersion == 0,
+	}
+}
+
+// Read the next record, which is got by [Scanner.Record]. It returns false at the end of the input or on read error.
+// Lines of the file header written by [LogHandler.FileHeader] are read as lines which are not the nslog text format,
+// and the format version and the time layout in the header are used to parse the following lines unless they are given by the options.
+func (scanner *Scanner) Scan() bool {
+	var line string
+	if scanner.pending != nil {
+		line = *scanner.pending
+		scanner.pending = nil
+	} else if scanner.scanner.Scan() {
+		line = scanner.scanner.Text()
+	} else {
+		return false
+	}
+
+	scanner.line = line
+	if strings.HasPrefix(line, FILE_HEADER_PREFIX) {
+		scanner.readHeader(strings.TrimSuffix(line[len(FILE_HEADER_PREFIX):], "\r"))
+		scanner.record, scanner.err = nil, ErrNotNslogFormat
+	} else {
+		scanner.record, scanner.err = parseLine(line, scanner.options)
+	}
+	for scanner.scanner.Scan() {
+		next := scanner.scanner.Text()
+		if !strings.HasPrefix(next, "\t") {
+			scanner.pending = &next
+			break
+		}
+		if scanner.record != nil {
+			scanner.record.Continuations = append(scanner.record.Continuations, strings.TrimSuffix(next[1:], "\r"))
+		}
+	}
+	return true
+}
+
+// Read a line of the file header such as "format version: 1" and "time layout: 2006/01/02 15:04:05 (local)".
+func (scanner *Scanner) readHeader(line string) {
+	name, value, ok := strings.Cut(line, ": ")
+	if !ok {
+		return
+	}
+	switch name {
+	case "format version":
+		version, err := strconv.Atoi(value)
+		if err == nil && scanner.headerVersion {
+			scanner.options.FormatVersion = version
+		}
+	case "time layout":
+		index := strings.LastIndex(value, " (")
+		if index < 0 || !scanner.headerLayout {
+			return
+		}
+		scanner.options.TimeLayout = value[:index]
+		if value[index:] == " (UTC)" {
+			scanner.options.Location = time.UTC
+		}
+	}
+}
+
+// Get the version of the layout of lines, which is given by the options or the file header.
+func (scanner *Scanner) FormatVersion() int {
+	return scanner.options.FormatVersion
+}
+
+// Get the record read by [Scanner.Scan]. The error is [nslog.ErrNotNslogFormat] if the line is not the nslog text format,
+// or [nslog.ErrUnsupportedFormatVersion] if the format version is not supported, and scanning can be continued.
+func (scanner *Scanner) Record() (*ParsedRecord, error) {
+	return scanner.record, scanner.err
+}
+
+// Get the line read by [Scanner.Scan] without continuation lines, which is useful if the line is not the nslog text format.
+func (scanner *Scanner) Text() string {
+	return scanner.line
+}
+
+// Get the error of the reader.
+func (scanner *Scanner) Err() error {
+	return scanner.scanner.Err()
+}
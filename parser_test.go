@@ -0,0 +1,139 @@
+package nslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	record, err := Parse("2024/10/31 11:22:33 WARN. [id=1]http[method=GET].db: slow query done table=users elapsed=1.5s note=two words (main.go:12)", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 10, 31, 11, 22, 33, 0, time.Local), record.Time)
+	assert.Equal(t, slog.LevelWarn, record.Level)
+	assert.Equal(t, "WARN.", record.LevelLabel)
+	assert.Equal(t, []string{"http", "db"}, record.Groups)
+	assert.Equal(t, "slow query done", record.Message)
+	assert.Equal(t, []slog.Attr{
+		slog.String("id", "1"),
+		slog.String("http.method", "GET"),
+		slog.String("table", "users"),
+		slog.String("elapsed", "1.5s"),
+		slog.String("note", "two words"),
+	}, record.Attrs)
+	assert.Equal(t, "main.go:12", record.Source)
+
+	_, err = Parse("panic: runtime error", nil)
+	assert.ErrorIs(t, err, ErrNotNslogFormat)
+}
+
+func TestParseOptions(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{
+		AddColor:       true,
+		AddSequence:    true,
+		AddElapsed:     true,
+		AddPID:         true,
+		AddGoroutineID: true,
+		LevelStyle:     LevelStyleBracketed,
+		TimeLayout:     TIME_LAYOUT_RFC3339_NANO,
+		SourceFunction: true,
+	})
+	log.Error("log message", "err", errors.New("failed"))
+
+	record, err := Parse(strings.TrimSuffix(buf.String(), "\n"), &ParserOptions{TimeLayout: TIME_LAYOUT_RFC3339_NANO})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), record.Sequence)
+	assert.False(t, record.Time.IsZero())
+	assert.Greater(t, record.Elapsed, time.Duration(0))
+	assert.NotZero(t, record.PID)
+	assert.NotZero(t, record.GoroutineID)
+	assert.Equal(t, slog.LevelError, record.Level)
+	assert.Equal(t, "log message", record.Message)
+	assert.Equal(t, []slog.Attr{slog.String("err", "failed")}, record.Attrs)
+	assert.Regexp(t, "^parser_test\\.go:[0-9]+ nslog\\.TestParseOptions$", record.Source)
+}
+
+func TestScanner(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{ExpandErrors: true})
+	log.Info("log message1")
+	buf.WriteString("not nslog format\n")
+	log.Warn("log message2", "err", fmt.Errorf("outer: %w", errors.New("inner")))
+	log.InfoContext(context.Background(), "log message3")
+
+	scanner := NewScanner(buf, nil)
+	var messages []string
+	var continuations [][]string
+	var errs []error
+	var texts []string
+	for scanner.Scan() {
+		record, err := scanner.Record()
+		if err != nil {
+			errs = append(errs, err)
+			texts = append(texts, scanner.Text())
+			continue
+		}
+		messages = append(messages, record.Message)
+		continuations = append(continuations, record.Continuations)
+	}
+	assert.NoError(t, scanner.Err())
+	assert.Equal(t, []string{"log message1", "log message2", "log message3"}, messages)
+	assert.Equal(t, []string{"err: caused by: inner"}, continuations[1])
+	assert.Equal(t, []error{ErrNotNslogFormat}, errs)
+	assert.Equal(t, []string{"not nslog format"}, texts)
+}
+
+func TestScannerFileHeader(t *testing.T) {
+	buf := new(bytes.Buffer)
+	handler := NewLogHandler(buf, &LogHandlerOptions{TimeLayout: time.RFC3339, UseUTC: true})
+	buf.WriteString(handler.FileHeader())
+	slog.New(handler).Info("log message")
+
+	scanner := NewScanner(buf, nil)
+	var records []*ParsedRecord
+	for scanner.Scan() {
+		record, err := scanner.Record()
+		if err == nil {
+			records = append(records, record)
+		}
+	}
+	assert.Equal(t, FORMAT_VERSION, scanner.FormatVersion())
+	assert.Len(t, records, 1)
+	assert.Equal(t, "log message", records[0].Message)
+	assert.Equal(t, time.UTC, records[0].Time.Location())
+	assert.WithinDuration(t, time.Now(), records[0].Time, time.Minute)
+}
+
+func TestScannerFormatVersion(t *testing.T) {
+	input := "# format version: 2\n2024/10/31 11:22:33 INFO. log message\n"
+	scanner := NewScanner(strings.NewReader(input), nil)
+	assert.True(t, scanner.Scan())
+	assert.True(t, scanner.Scan())
+	_, err := scanner.Record()
+	assert.ErrorIs(t, err, ErrUnsupportedFormatVersion)
+	assert.Equal(t, 2, scanner.FormatVersion())
+
+	// the version of the options is used instead of the file header
+	scanner = NewScanner(strings.NewReader(input), &ParserOptions{FormatVersion: 1})
+	assert.True(t, scanner.Scan())
+	assert.True(t, scanner.Scan())
+	record, err := scanner.Record()
+	assert.NoError(t, err)
+	assert.Equal(t, "log message", record.Message)
+
+	_, err = Parse("2024/10/31 11:22:33 INFO. log message", &ParserOptions{FormatVersion: 2})
+	assert.ErrorIs(t, err, ErrUnsupportedFormatVersion)
+}
@@ -0,0 +1,80 @@
+package nslog
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// Default maximum size of payloads output by [nslog.Payload] in bytes.
+const DEFAULT_PAYLOAD_MAX_SIZE = 4096
+
+// A large payload output on continuation lines.
+type payload struct {
+	data string
+}
+
+func (p payload) String() string {
+	return p.data
+}
+
+// Marshal the payload as text, so other handlers such as [slog.JSONHandler] output the data as string.
+func (p payload) MarshalText() ([]byte, error) {
+	return []byte(p.data), nil
+}
+
+// Get the attribute of a large payload such as JSON blob, HTTP body, and diff, which is output on continuation lines
+// attached to the record instead of the line, so the payload dump remains associated with its header line:
+//
+//	logger.Info("response received", "status", 200, nslog.Payload("body", body))
+//	// => 2024/10/31 11:22:33 INFO. response received status=200 body=(payload 27 bytes)
+//	// => 	body: {"id": 1,
+//	// => 	body:  "name": "nslog"}
+//
+// The payload exceeding PayloadMaxSize option is truncated.
+func Payload(key string, data []byte) slog.Attr {
+	return slog.Any(key, payload{data: string(data)})
+}
+
+// Get the attribute of a large payload of the string, same as [nslog.Payload].
+func PayloadString(key string, data string) slog.Attr {
+	return slog.Any(key, payload{data: data})
+}
+
+// Get the payload of the attribute cut at the maximum size, and size of the cut data in bytes.
+func payloadData(attribute slog.Attr, maxSize int) (string, int, bool) {
+	if attribute.Value.Kind() != slog.KindAny {
+		return "", 0, false
+	}
+	p, ok := attribute.Value.Any().(payload)
+	if !ok {
+		return "", 0, false
+	}
+	if maxSize <= 0 || len(p.data) <= maxSize {
+		return p.data, 0, true
+	}
+	keep := runeBoundary(p.data, maxSize)
+	return p.data[:keep], len(p.data) - keep, true
+}
+
+// Get summary of the payload attribute for the line such as "(payload 27 bytes)",
+// and continuation lines of the payload prefixed by the key.
+func payloadLines(attribute slog.Attr, maxSize int) (string, []string, bool) {
+	data, cut, ok := payloadData(attribute, maxSize)
+	if !ok {
+		return "", nil, false
+	}
+	summary := "(payload " + strconv.Itoa(len(data)+cut) + " bytes)"
+	if data == "" {
+		return summary, nil, true
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSuffix(data, "\n"), "\n") {
+		lines = append(lines, "\t"+attribute.Key+": "+strings.TrimSuffix(line, "\r"))
+	}
+	if cut > 0 {
+		lines = append(lines, "\t"+attribute.Key+": "+truncatedMarker(cut))
+	}
+	return summary, lines, true
+}
@@ -0,0 +1,52 @@
+package nslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPayload(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true})
+	log.Info("response received", "status", 200, Payload("body", []byte("{\"id\": 1,\r\n \"name\": \"nslog\"}\n")))
+	log.Info("next message")
+	assert.Equal(t, "INFO. response received status=200 body=(payload 29 bytes)\n"+
+		"\tbody: {\"id\": 1,\n"+
+		"\tbody:  \"name\": \"nslog\"}\n"+
+		"INFO. next message\n", buf.String())
+}
+
+func TestPayloadMaxSize(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true, PayloadMaxSize: 10})
+	log.Info("diff", PayloadString("diff", "-old line\n+new line\n"))
+	assert.Equal(t, "INFO. diff diff=(payload 20 bytes)\n"+
+		"\tdiff: -old line\n"+
+		"\tdiff: …(truncated 10 bytes)\n", buf.String())
+
+	buf.Reset()
+	log = NewLogger(buf, &LogHandlerOptions{OmitTime: true, PayloadMaxSize: -1})
+	log.Info("dump", PayloadString("data", strings.Repeat("x", DEFAULT_PAYLOAD_MAX_SIZE+1)))
+	assert.Contains(t, buf.String(), "\tdata: "+strings.Repeat("x", DEFAULT_PAYLOAD_MAX_SIZE+1)+"\n")
+}
+
+func TestPayloadLTSV(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true, Format: FormatLTSV, PayloadMaxSize: 8})
+	log.Info("dump", PayloadString("data", "line1\nline2\n"))
+	assert.Equal(t, "level:INFO\tmsg:dump\tdata:line1\\nli…(truncated 4 bytes)\n", buf.String())
+}
+
+func TestPayloadJSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := slog.New(slog.NewJSONHandler(buf, nil))
+	log.Info("dump", PayloadString("data", "line1\nline2"))
+	var entry map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "line1\nline2", entry["data"])
+}
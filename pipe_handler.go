@@ -0,0 +1,303 @@
+package nslog
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const PIPE_FD_ENV = "GO_NSLOG_PIPE_FD"
+const PIPE_MAX_ENTRY_SIZE = 16 * 1024 * 1024
+
+// A handler to forward records to the parent process over a pipe.
+// Each record is written as an entry, which is a 4 bytes big-endian length followed by JSON.
+type PipeHandler struct {
+	level  slog.Leveler
+	steps  []pipeStep
+	mutex  *sync.Mutex
+	writer io.Writer
+}
+
+// A step of WithAttrs or WithGroup, which are replayed in the called order.
+type pipeStep struct {
+	Group string     `json:"group,omitempty"`
+	Attrs []pipeAttr `json:"attrs,omitempty"`
+}
+
+// An attribute with the kind of the value, which is restored as the same kind by [nslog.CollectPipe].
+// Values of KindAny are sent as the string.
+type pipeAttr struct {
+	Key   string     `json:"key"`
+	Kind  string     `json:"kind,omitempty"`
+	Value string     `json:"value,omitempty"`
+	Attrs []pipeAttr `json:"attrs,omitempty"`
+}
+
+type pipeEntry struct {
+	Time    time.Time  `json:"time"`
+	Level   slog.Level `json:"level"`
+	Message string     `json:"msg"`
+	With    []pipeStep `json:"with,omitempty"`
+	Attrs   []pipeAttr `json:"attrs,omitempty"`
+	Source  string     `json:"source,omitempty"`
+}
+
+// Create a new [nslog.PipeHandler] object to write entries to the writer.
+// If level is nil, all records are forwarded and the parent decides which records are output.
+func NewPipeHandler(writer io.Writer, level slog.Leveler) *PipeHandler {
+	if level == nil {
+		level = slog.LevelDebug
+	}
+	return &PipeHandler{
+		level:  level,
+		mutex:  &sync.Mutex{},
+		writer: writer,
+	}
+}
+
+// Create a new [nslog.PipeHandler] object to write entries to the file descriptor inherited from the parent process.
+// The file descriptor is given by environment variable GO_NSLOG_PIPE_FD, which is set by [nslog.StartWithPipe].
+func NewPipeHandlerFromEnv(level slog.Leveler) (*PipeHandler, error) {
+	fd, err := strconv.Atoi(os.Getenv(PIPE_FD_ENV))
+	if err != nil {
+		return nil, fmt.Errorf("nslog: %s is not available: %w", PIPE_FD_ENV, err)
+	}
+	return NewPipeHandler(os.NewFile(uintptr(fd), "nslog-pipe"), level), nil
+}
+
+func (handler *PipeHandler) clone() *PipeHandler {
+	return &PipeHandler{
+		level:  handler.level,
+		steps:  slices.Clip(handler.steps),
+		mutex:  handler.mutex,
+		writer: handler.writer,
+	}
+}
+
+func (handler *PipeHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= handler.level.Level()
+}
+
+func (handler *PipeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return handler
+	}
+	new_handler := handler.clone()
+	new_handler.steps = append(new_handler.steps, pipeStep{Attrs: toPipeAttrs(attrs)})
+	return new_handler
+}
+
+func (handler *PipeHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return handler
+	}
+	new_handler := handler.clone()
+	new_handler.steps = append(new_handler.steps, pipeStep{Group: name})
+	return new_handler
+}
+
+func (handler *PipeHandler) Handle(_ context.Context, record slog.Record) error {
+	entry := pipeEntry{
+		Time:    record.Time,
+		Level:   record.Level,
+		Message: record.Message,
+		With:    handler.steps,
+	}
+	record.Attrs(func(attribute slog.Attr) bool {
+		entry.Attrs = append(entry.Attrs, toPipeAttr(attribute))
+		return true
+	})
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		entry.Source = filepath.Base(frame.File) + ":" + strconv.Itoa(frame.Line)
+	}
+
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	log_bytes := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(body)), uint32(len(body)))
+	log_bytes = append(log_bytes, body...)
+
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+	_, err = handler.writer.Write(log_bytes)
+	return err
+}
+
+// Read entries written by [nslog.PipeHandler] from the reader and output them by the handler until EOF.
+// The source of the child process is added as "source" attribute.
+func CollectPipe(reader io.Reader, handler slog.Handler) error {
+	buffered := bufio.NewReader(reader)
+	header := make([]byte, 4)
+	for {
+		_, err := io.ReadFull(buffered, header)
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		size := binary.BigEndian.Uint32(header)
+		if size > PIPE_MAX_ENTRY_SIZE {
+			return fmt.Errorf("nslog: pipe entry is too large: %d bytes", size)
+		}
+		body := make([]byte, size)
+		_, err = io.ReadFull(buffered, body)
+		if err != nil {
+			return err
+		}
+
+		var entry pipeEntry
+		err = json.Unmarshal(body, &entry)
+		if err != nil {
+			return err
+		}
+
+		entry_handler := handler
+		for _, step := range entry.With {
+			if step.Group != "" {
+				entry_handler = entry_handler.WithGroup(step.Group)
+			} else {
+				entry_handler = entry_handler.WithAttrs(toSlogAttrs(step.Attrs))
+			}
+		}
+		if !entry_handler.Enabled(context.Background(), entry.Level) {
+			continue
+		}
+		record := slog.NewRecord(entry.Time, entry.Level, entry.Message, 0)
+		record.AddAttrs(toSlogAttrs(entry.Attrs)...)
+		ctx := context.Background()
+		if entry.Source != "" {
+			// the source is not qualified by groups
+			ctx = AddContextAttrs(ctx, slog.String("source", entry.Source))
+		}
+		err = entry_handler.Handle(ctx, record)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func toPipeAttrs(attrs []slog.Attr) []pipeAttr {
+	pipe_attrs := make([]pipeAttr, 0, len(attrs))
+	for _, attribute := range attrs {
+		pipe_attrs = append(pipe_attrs, toPipeAttr(attribute))
+	}
+	return pipe_attrs
+}
+
+func toPipeAttr(attribute slog.Attr) pipeAttr {
+	value := attribute.Value.Resolve()
+	pipe_attr := pipeAttr{Key: attribute.Key, Kind: value.Kind().String()}
+	switch value.Kind() {
+	case slog.KindGroup:
+		pipe_attr.Attrs = toPipeAttrs(value.Group())
+	case slog.KindTime:
+		pipe_attr.Value = value.Time().Format(time.RFC3339Nano)
+	case slog.KindDuration:
+		pipe_attr.Value = strconv.FormatInt(int64(value.Duration()), 10)
+	case slog.KindAny:
+		// the original type is not known by the parent process
+		pipe_attr.Kind = ""
+		pipe_attr.Value = value.String()
+	default:
+		pipe_attr.Value = value.String()
+	}
+	return pipe_attr
+}
+
+func toSlogAttrs(attrs []pipeAttr) []slog.Attr {
+	slog_attrs := make([]slog.Attr, 0, len(attrs))
+	for _, attribute := range attrs {
+		slog_attrs = append(slog_attrs, toSlogAttr(attribute))
+	}
+	return slog_attrs
+}
+
+func toSlogAttr(attribute pipeAttr) slog.Attr {
+	switch attribute.Kind {
+	case slog.KindGroup.String():
+		return slog.Attr{Key: attribute.Key, Value: slog.GroupValue(toSlogAttrs(attribute.Attrs)...)}
+	case slog.KindBool.String():
+		if value, err := strconv.ParseBool(attribute.Value); err == nil {
+			return slog.Bool(attribute.Key, value)
+		}
+	case slog.KindDuration.String():
+		if value, err := strconv.ParseInt(attribute.Value, 10, 64); err == nil {
+			return slog.Duration(attribute.Key, time.Duration(value))
+		}
+	case slog.KindFloat64.String():
+		if value, err := strconv.ParseFloat(attribute.Value, 64); err == nil {
+			return slog.Float64(attribute.Key, value)
+		}
+	case slog.KindInt64.String():
+		if value, err := strconv.ParseInt(attribute.Value, 10, 64); err == nil {
+			return slog.Int64(attribute.Key, value)
+		}
+	case slog.KindUint64.String():
+		if value, err := strconv.ParseUint(attribute.Value, 10, 64); err == nil {
+			return slog.Uint64(attribute.Key, value)
+		}
+	case slog.KindTime.String():
+		if value, err := time.Parse(time.RFC3339Nano, attribute.Value); err == nil {
+			return slog.Time(attribute.Key, value)
+		}
+	}
+	return slog.String(attribute.Key, attribute.Value)
+}
+
+// A collector of entries written by the child process.
+type PipeCollector struct {
+	done chan struct{}
+	err  error
+}
+
+// Start the command with a pipe inherited as GO_NSLOG_PIPE_FD, and collect entries written by
+// [nslog.PipeHandler] in the child process. The entries are output by the handler.
+func StartWithPipe(cmd *exec.Cmd, handler slog.Handler) (*PipeCollector, error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	// file descriptors 0, 1, and 2 are stdin, stdout, and stderr
+	cmd.ExtraFiles = append(cmd.ExtraFiles, writer)
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, PIPE_FD_ENV+"="+strconv.Itoa(2+len(cmd.ExtraFiles)))
+
+	err = cmd.Start()
+	writer.Close()
+	if err != nil {
+		reader.Close()
+		return nil, err
+	}
+
+	collector := &PipeCollector{done: make(chan struct{})}
+	go func() {
+		defer close(collector.done)
+		defer reader.Close()
+		collector.err = CollectPipe(reader, handler)
+	}()
+	return collector, nil
+}
+
+// Wait until all entries are collected, which is when the child process closes the pipe.
+func (collector *PipeCollector) Wait() error {
+	<-collector.done
+	return collector.err
+}
@@ -0,0 +1,88 @@
+package nslog
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipeHandler(t *testing.T) {
+	pipe := new(bytes.Buffer)
+	log := slog.New(NewPipeHandler(pipe, nil))
+	log.Debug("message1")
+	log.WithGroup("Group1").With("id", 1).Info("message2", "key1", "val1")
+	log.Warn("message3")
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, CollectPipe(pipe, NewLogHandler(buf, nil)))
+	assert.NotContains(t, buf.String(), "message1")
+	assert.Regexp(t, DEFAULT_TIME_REGEXP+" INFO\\. Group1\\[id=1\\]: message2 Group1\\.key1=val1 source=pipe_handler_test\\.go:\\d+\n", buf.String())
+	assert.Regexp(t, DEFAULT_TIME_REGEXP+" WARN\\. message3 source=pipe_handler_test\\.go:\\d+\n", buf.String())
+}
+
+func TestPipeHandlerRoundTrip(t *testing.T) {
+	removeTime := func(groups []string, attribute slog.Attr) slog.Attr {
+		if len(groups) == 0 && attribute.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+		return attribute
+	}
+	attrs := []any{
+		"int", 1,
+		"uint", uint64(2),
+		"float", 1.5,
+		"bool", true,
+		"duration", time.Second,
+		"time", time.Date(2024, 10, 31, 11, 22, 33, 123456789, time.UTC),
+		"string", "str",
+		slog.Group("nested", "id", 3, slog.Group("inner", "ok", false)),
+	}
+	for _, test := range []struct {
+		name   string
+		logger func(log *slog.Logger) *slog.Logger
+	}{
+		{"attrs then group", func(log *slog.Logger) *slog.Logger { return log.With(attrs...).WithGroup("G") }},
+		{"group then attrs", func(log *slog.Logger) *slog.Logger { return log.WithGroup("G").With(attrs...) }},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			expected := new(bytes.Buffer)
+			test.logger(slog.New(slog.NewJSONHandler(expected, &slog.HandlerOptions{ReplaceAttr: removeTime}))).Info("message", attrs...)
+
+			pipe := new(bytes.Buffer)
+			test.logger(slog.New(NewPipeHandler(pipe, nil))).Info("message", attrs...)
+			actual := new(bytes.Buffer)
+			assert.NoError(t, CollectPipe(pipe, slog.NewJSONHandler(actual, &slog.HandlerOptions{ReplaceAttr: removeTime})))
+			assert.Equal(t, expected.String(), actual.String())
+		})
+	}
+}
+
+func TestPipeHandlerBrokenEntry(t *testing.T) {
+	pipe := bytes.NewBuffer([]byte{0, 0, 0, 10, '{'})
+	assert.Error(t, CollectPipe(pipe, NewLogHandler(new(bytes.Buffer), nil)))
+}
+
+func TestStartWithPipe(t *testing.T) {
+	if os.Getenv("GO_NSLOG_TEST_PIPE_CHILD") == "1" {
+		handler, err := NewPipeHandlerFromEnv(nil)
+		if err != nil {
+			os.Exit(1)
+		}
+		slog.New(handler).Info("child message", "key1", "val1")
+		os.Exit(0)
+	}
+
+	buf := new(bytes.Buffer)
+	cmd := exec.Command(os.Args[0], "-test.run=^TestStartWithPipe$")
+	cmd.Env = append(os.Environ(), "GO_NSLOG_TEST_PIPE_CHILD=1")
+	collector, err := StartWithPipe(cmd, NewLogHandler(buf, nil))
+	assert.NoError(t, err)
+	assert.NoError(t, collector.Wait())
+	assert.NoError(t, cmd.Wait())
+	assert.Contains(t, buf.String(), "INFO. child message key1=val1")
+}
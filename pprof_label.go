@@ -0,0 +1,35 @@
+package nslog
+
+import (
+	"context"
+	"log/slog"
+	"runtime/pprof"
+	"slices"
+	"strings"
+)
+
+// Get labels of pprof in the context as attributes in order of the keys, where "*" means all labels sorted by key.
+func pprofLabels(ctx context.Context, keys []string) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	var attrs []slog.Attr
+	for _, key := range keys {
+		if key == "*" {
+			var all []slog.Attr
+			pprof.ForLabels(ctx, func(key, value string) bool {
+				all = append(all, slog.String(key, value))
+				return true
+			})
+			slices.SortFunc(all, func(a, b slog.Attr) int {
+				return strings.Compare(a.Key, b.Key)
+			})
+			attrs = append(attrs, all...)
+			continue
+		}
+		if value, ok := pprof.Label(ctx, key); ok {
+			attrs = append(attrs, slog.String(key, value))
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,38 @@
+package nslog
+
+import (
+	"bytes"
+	"context"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPprofLabels(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{PprofLabels: []string{"worker", "missing"}})
+	pprof.Do(context.Background(), pprof.Labels("worker", "3", "job", "import"), func(ctx context.Context) {
+		log.InfoContext(ctx, "log message", "key1", "val1")
+	})
+	log.Info("log message without context")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. log message key1=val1 worker=3\n"+
+		DEFAULT_TIME_REGEXP+" INFO\\. log message without context\n$", buf.String())
+}
+
+func TestPprofLabelsAll(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{PprofLabels: []string{"*"}})
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("worker", "3", "job", "import"))
+	log.InfoContext(ctx, "log message")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. log message job=import worker=3\n$", buf.String())
+}
+
+func TestPprofLabelsEnv(t *testing.T) {
+	t.Setenv("GO_NSLOG_PPROF_LABELS", "job,worker")
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, nil)
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("worker", "3", "job", "import"))
+	log.InfoContext(ctx, "log message")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. log message job=import worker=3\n$", buf.String())
+}
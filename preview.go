@@ -0,0 +1,39 @@
+package nslog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Render sample log lines with the new options without applying them to the handler,
+// so operators can see what a format or level change will look like before committing it.
+// A canned record is rendered for each of Error, Warn, Info, and Debug level, and
+// records which are not enabled by the new options are omitted. Attributes and groups of the handler are kept.
+func (handler *LogHandler) PreviewConfig(newOptions *LogHandlerOptions) []string {
+	var options LogHandlerOptions
+	if newOptions != nil {
+		options = *newOptions
+	}
+	preview := NewLogHandler(nil, &options)
+	preview.attrs = handler.attrs
+	preview.groups = handler.groups
+	preview.level = groupLevel(preview.options.GroupLevels, handler.groups)
+
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:])
+	now := time.Now()
+
+	var lines []string
+	for _, level := range []slog.Level{slog.LevelError, slog.LevelWarn, slog.LevelInfo, slog.LevelDebug} {
+		if !preview.Enabled(context.Background(), level) {
+			continue
+		}
+		record := slog.NewRecord(now, level, "sample message", pcs[0])
+		record.AddAttrs(slog.String("key", "value"))
+		lines = append(lines, strings.TrimSuffix(string(preview.format(context.Background(), record)), preview.options.LineEnding))
+	}
+	return lines
+}
@@ -0,0 +1,32 @@
+package nslog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreviewConfig(t *testing.T) {
+	buf := new(bytes.Buffer)
+	handler := NewLogHandler(buf, nil).WithGroup("Group1").(*LogHandler)
+	lines := handler.PreviewConfig(&LogHandlerOptions{Level: slog.LevelDebug, AddSourceLevel: slog.LevelError})
+	assert.Len(t, lines, 4)
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" ERROR Group1: sample message Group1\\.key=value \\(preview_test\\.go:\\d+\\)$", lines[0])
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" WARN\\. Group1: sample message Group1\\.key=value$", lines[1])
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. Group1: sample message Group1\\.key=value$", lines[2])
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" DEBUG Group1: sample message Group1\\.key=value$", lines[3])
+
+	// not applied
+	slog.New(handler).Debug("log message")
+	assert.Empty(t, buf.String())
+}
+
+func TestPreviewConfigLevel(t *testing.T) {
+	handler := NewLogHandler(nil, nil)
+	lines := handler.PreviewConfig(&LogHandlerOptions{Level: slog.LevelWarn, TimeLayout: "15:04"})
+	assert.Len(t, lines, 2)
+	assert.Regexp(t, "^\\d{2}:\\d{2} ERROR sample message", lines[0])
+	assert.Regexp(t, "^\\d{2}:\\d{2} WARN\\. sample message", lines[1])
+}
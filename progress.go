@@ -0,0 +1,60 @@
+package nslog
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// A key of the attribute to mark records as progress, which is not output.
+const PROGRESS_KEY = "nslog.progress"
+
+// Erase the current line of the terminal and move the cursor to the start of the line.
+const progressErase = "\r\x1b[2K"
+
+// Get the attribute to mark the record as progress, which rewrites the current line by LiveProgress option:
+//
+//	logger.Info("downloading", "done", n, "total", total, nslog.Progress())
+func Progress() slog.Attr {
+	return slog.Bool(PROGRESS_KEY, true)
+}
+
+// Check whether the record is marked as progress by [nslog.Progress].
+func isProgress(record slog.Record) bool {
+	progress := false
+	record.Attrs(func(attribute slog.Attr) bool {
+		if attribute.Key == PROGRESS_KEY && attribute.Value.Kind() == slog.KindBool && attribute.Value.Bool() {
+			progress = true
+			return false
+		}
+		return true
+	})
+	return progress
+}
+
+// A state of the progress line on the terminal, which is shared with derived handlers writing to the same writer.
+type progressState struct {
+	active bool // whether the current line is a progress line without newline
+}
+
+// Write the line with the line ending. A progress line rewrites the current line without the line ending,
+// and other lines are written after the progress line is ended with the line ending to keep it.
+func (state *progressState) write(writer io.Writer, mutex *sync.Mutex, line []byte, progress bool, ending string) error {
+	var buffer []byte
+	mutex.Lock()
+	defer mutex.Unlock()
+	if progress {
+		buffer = append(buffer, progressErase...)
+		buffer = append(buffer, bytes.TrimSuffix(line, []byte(ending))...)
+		state.active = true
+	} else {
+		if state.active {
+			buffer = append(buffer, ending...)
+			state.active = false
+		}
+		buffer = append(buffer, line...)
+	}
+	_, err := writer.Write(buffer)
+	return err
+}
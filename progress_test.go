@@ -0,0 +1,45 @@
+package nslog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiveProgress(t *testing.T) {
+	buf := new(bytes.Buffer)
+	handler := NewLogHandler(buf, &LogHandlerOptions{LiveProgress: true, OmitTime: true})
+	// the writer is not a terminal, so enable progress line for test
+	handler.live = &progressState{}
+	log := slog.New(handler)
+	log.Info("downloading", "done", 1, Progress())
+	log.Info("downloading", "done", 2, Progress())
+	log.Info("log message")
+	log.With("key1", "val1").Info("downloading", "done", 3, Progress())
+	log.Info("completed")
+	assert.Equal(t, "\r\x1b[2KINFO. downloading done=1"+
+		"\r\x1b[2KINFO. downloading done=2"+
+		"\nINFO. log message\n"+
+		"\r\x1b[2KINFO. [key1=val1]: downloading done=3"+
+		"\nINFO. completed\n", buf.String())
+}
+
+func TestLiveProgressNotTerminal(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{LiveProgress: true, OmitTime: true})
+	log.Info("downloading", "done", 1, Progress())
+	log.Info("downloading", "done", 2, Progress())
+	assert.Equal(t, "INFO. downloading done=1\nINFO. downloading done=2\n", buf.String())
+}
+
+func TestLiveProgressLineEnding(t *testing.T) {
+	buf := new(bytes.Buffer)
+	handler := NewLogHandler(buf, &LogHandlerOptions{LiveProgress: true, OmitTime: true, LineEnding: "\r\n"})
+	handler.live = &progressState{}
+	log := slog.New(handler)
+	log.Info("downloading", "done", 1, Progress())
+	log.Info("completed")
+	assert.Equal(t, "\r\x1b[2KINFO. downloading done=1\r\nINFO. completed\r\n", buf.String())
+}
@@ -0,0 +1,102 @@
+// The promexporter package provides a Prometheus collector for statistics of the nslog package.
+package promexporter
+
+import (
+	"github.com/mikiepure/nslog"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const DEFAULT_NAMESPACE = "nslog"
+
+// A source of statistics such as [nslog.LogHandler] with CollectStats option.
+type StatsSource interface {
+	Stats() nslog.Stats
+}
+
+// A queue of async handler such as [nslog.AlertHandler] and [nslog.SentryHandler].
+type Queue interface {
+	QueueDepth() int
+}
+
+// An option to customize [promexporter.Collector].
+type Options struct {
+	Namespace   string            // Set namespace of metrics. (default: "nslog")
+	ConstLabels prometheus.Labels // Set labels added to all metrics such as {"logger": "app"}. (default: nil)
+	Queues      map[string]Queue  // Set queues of async handlers by name, which are exported with "queue" label. (default: nil)
+}
+
+// A collector implementing [prometheus.Collector] over statistics of the handler.
+type Collector struct {
+	source  StatsSource
+	queues  map[string]Queue
+	records *prometheus.Desc
+	dropped *prometheus.Desc
+	errors  *prometheus.Desc
+	bytes   *prometheus.Desc
+	latency *prometheus.Desc
+	depth   *prometheus.Desc
+}
+
+// Create a new [promexporter.Collector] object. Register it by [prometheus.MustRegister].
+func NewCollector(source StatsSource, options *Options) *Collector {
+	// set default parameters
+	if options == nil {
+		options = &Options{}
+	}
+	namespace := options.Namespace
+	if namespace == "" {
+		namespace = DEFAULT_NAMESPACE
+	}
+
+	newDesc := func(name string, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, labels, options.ConstLabels)
+	}
+	return &Collector{
+		source:  source,
+		queues:  options.Queues,
+		records: newDesc("records_total", "Number of records written by level.", "level"),
+		dropped: newDesc("dropped_records_total", "Number of records dropped by hooks."),
+		errors:  newDesc("write_errors_total", "Number of records failed to write."),
+		bytes:   newDesc("written_bytes_total", "Number of bytes written."),
+		latency: newDesc("write_latency_seconds", "Latency to write records."),
+		depth:   newDesc("queue_depth", "Number of entries waiting in the queue of async handler.", "queue"),
+	}
+}
+
+func (collector *Collector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- collector.records
+	descs <- collector.dropped
+	descs <- collector.errors
+	descs <- collector.bytes
+	descs <- collector.latency
+	if len(collector.queues) > 0 {
+		descs <- collector.depth
+	}
+}
+
+func (collector *Collector) Collect(metrics chan<- prometheus.Metric) {
+	stats := collector.source.Stats()
+	metrics <- prometheus.MustNewConstMetric(collector.records, prometheus.CounterValue, float64(stats.Error), "ERROR")
+	metrics <- prometheus.MustNewConstMetric(collector.records, prometheus.CounterValue, float64(stats.Warn), "WARN")
+	metrics <- prometheus.MustNewConstMetric(collector.records, prometheus.CounterValue, float64(stats.Info), "INFO")
+	metrics <- prometheus.MustNewConstMetric(collector.records, prometheus.CounterValue, float64(stats.Debug), "DEBUG")
+	metrics <- prometheus.MustNewConstMetric(collector.dropped, prometheus.CounterValue, float64(stats.Dropped))
+	metrics <- prometheus.MustNewConstMetric(collector.errors, prometheus.CounterValue, float64(stats.WriteErrors))
+	metrics <- prometheus.MustNewConstMetric(collector.bytes, prometheus.CounterValue, float64(stats.Bytes))
+
+	// histogram with cumulative counts of buckets
+	buckets := map[float64]uint64{}
+	var count uint64
+	for i, bound := range stats.WriteLatency.Bounds {
+		count += stats.WriteLatency.Counts[i]
+		buckets[bound.Seconds()] = count
+	}
+	for _, bucket := range stats.WriteLatency.Counts[len(stats.WriteLatency.Bounds):] {
+		count += bucket
+	}
+	metrics <- prometheus.MustNewConstHistogram(collector.latency, count, stats.WriteLatency.Sum.Seconds(), buckets)
+
+	for name, queue := range collector.queues {
+		metrics <- prometheus.MustNewConstMetric(collector.depth, prometheus.GaugeValue, float64(queue.QueueDepth()), name)
+	}
+}
@@ -0,0 +1,58 @@
+package promexporter
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/mikiepure/nslog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type queue int
+
+func (q queue) QueueDepth() int {
+	return int(q)
+}
+
+func TestCollector(t *testing.T) {
+	dropHook := nslog.Hook{BeforeFormat: func(_ context.Context, record *slog.Record) bool {
+		return record.Level != slog.LevelWarn
+	}}
+	handler := nslog.NewLogHandler(io.Discard, &nslog.LogHandlerOptions{CollectStats: true, Hooks: []nslog.Hook{dropHook}})
+	log := slog.New(handler)
+	log.Error("log message")
+	log.Info("log message")
+	log.Info("log message")
+	log.Debug("log message")
+	log.Warn("log message")
+
+	collector := NewCollector(handler, &Options{Queues: map[string]Queue{"alert": queue(3)}})
+	registry := prometheus.NewPedanticRegistry()
+	assert.NoError(t, registry.Register(collector))
+
+	expected := `
+# HELP nslog_dropped_records_total Number of records dropped by hooks.
+# TYPE nslog_dropped_records_total counter
+nslog_dropped_records_total 1
+# HELP nslog_queue_depth Number of entries waiting in the queue of async handler.
+# TYPE nslog_queue_depth gauge
+nslog_queue_depth{queue="alert"} 3
+# HELP nslog_records_total Number of records written by level.
+# TYPE nslog_records_total counter
+nslog_records_total{level="DEBUG"} 0
+nslog_records_total{level="ERROR"} 1
+nslog_records_total{level="INFO"} 2
+nslog_records_total{level="WARN"} 0
+`
+	err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "nslog_dropped_records_total", "nslog_queue_depth", "nslog_records_total")
+	assert.NoError(t, err)
+
+	count, err := testutil.GatherAndCount(registry, "nslog_write_latency_seconds", "nslog_written_bytes_total", "nslog_write_errors_total")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
@@ -0,0 +1,113 @@
+package nslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+const DEFAULT_PANIC_MESSAGE = "panic recovered"
+
+// An option to customize [nslog.Recover].
+type RecoverOptions struct {
+	Message string // Set message of log of panic. (default: "panic recovered")
+	Repanic bool   // Panic again with the recovered value after logging, such as to crash the process. (default: false)
+}
+
+// An error of recovered panic with the stack, which is logged as "panic" attribute by [nslog.Recover].
+// The stack is output as continuation lines by [nslog.LogHandler] regardless of ExpandErrors option, since "%+v" of it has the stack.
+type PanicError struct {
+	Value any    // value passed to panic
+	Stack []byte // stack formatted by [runtime/debug.Stack]
+}
+
+func (err *PanicError) Error() string {
+	return fmt.Sprint(err.Value)
+}
+
+func (err *PanicError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		fmt.Fprint(s, err.Error()+"\n"+strings.TrimRight(string(err.Stack), "\n"))
+		return
+	}
+	fmt.Fprint(s, err.Error())
+}
+
+// Get the value passed to panic if it is an error.
+func (err *PanicError) Unwrap() error {
+	e, _ := err.Value.(error)
+	return e
+}
+
+// Recover panic and log the value and the stack at Error level, which must be called by defer directly:
+//
+//	go func() {
+//		defer nslog.Recover(logger, nil)
+//		...
+//	}()
+//
+// The source of the log is where panic is called.
+func Recover(logger *slog.Logger, options *RecoverOptions) {
+	value := recover()
+	if value == nil {
+		return
+	}
+	LogPanic(context.Background(), logger, value, options)
+}
+
+// Log the value recovered from panic and the stack at Error level, which is used by recover handlers such as middlewares.
+// It must be called in the deferred function which recovered panic to take the stack.
+func LogPanic(ctx context.Context, logger *slog.Logger, value any, options *RecoverOptions) {
+	// set default parameters
+	if options == nil {
+		options = &RecoverOptions{}
+	}
+	message := options.Message
+	if message == "" {
+		message = DEFAULT_PANIC_MESSAGE
+	}
+
+	handler := logger.Handler()
+	if handler.Enabled(ctx, slog.LevelError) {
+		record := slog.NewRecord(time.Now(), slog.LevelError, message, panicPC())
+		record.AddAttrs(slog.Any("panic", &PanicError{Value: value, Stack: trimPanicStack(debug.Stack())}))
+		_ = handler.Handle(ctx, record)
+	}
+
+	if options.Repanic {
+		panic(value)
+	}
+}
+
+// Get pc of the function which called panic, or 0 if it is not found on the current stack.
+func panicPC() uintptr {
+	pcs := make([]uintptr, 64)
+	pcs = pcs[:runtime.Callers(2, pcs)]
+	panicking := false
+	for _, pc := range pcs {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		if frame.Function == "runtime.gopanic" {
+			panicking = true
+			continue
+		}
+		if panicking && !strings.HasPrefix(frame.Function, "runtime.") {
+			return pc
+		}
+	}
+	return 0
+}
+
+// Remove frames of recovering from the stack, which are above the frame of panic.
+func trimPanicStack(stack []byte) []byte {
+	lines := strings.Split(string(stack), "\n")
+	for i := 1; i+1 < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "panic(") {
+			return []byte(strings.Join(append(lines[:1], lines[i+2:]...), "\n"))
+		}
+	}
+	return stack
+}
@@ -0,0 +1,70 @@
+package nslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecover(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{ExpandErrors: true})
+	func() {
+		defer Recover(log, nil)
+		panic("boom")
+	}()
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" ERROR panic recovered panic=boom \\(recover_test\\.go:[0-9]+\\)\n"+
+		"\tpanic: boom\n"+
+		"\tpanic: goroutine [0-9]+ \\[running\\]:\n"+
+		"\tpanic: github.com/mikiepure/nslog\\.TestRecover\\.func1\\(.*\\)\n", buf.String())
+}
+
+func TestRecoverDefaultOptions(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, nil)
+	func() {
+		defer Recover(log, nil)
+		panic("boom")
+	}()
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" ERROR panic recovered panic=boom \\(recover_test\\.go:[0-9]+\\)\n"+
+		"\tpanic: boom\n"+
+		"\tpanic: goroutine [0-9]+ \\[running\\]:\n"+
+		"\tpanic: github.com/mikiepure/nslog\\.TestRecoverDefaultOptions\\.func1\\(.*\\)\n"+
+		"\tpanic: \t.+/recover_test\\.go:[0-9]+", buf.String())
+
+	// other errors are not expanded
+	buf.Reset()
+	log.Error("error message", "err", errors.New("boom"))
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" ERROR error message err=boom \\(recover_test\\.go:[0-9]+\\)\n$", buf.String())
+}
+
+func TestRecoverRepanic(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, nil)
+	err := errors.New("boom")
+	assert.PanicsWithValue(t, err, func() {
+		defer Recover(log, &RecoverOptions{Message: "worker crashed", Repanic: true})
+		panic(err)
+	})
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" ERROR worker crashed panic=boom \\(recover_test\\.go:[0-9]+\\)\n\tpanic: boom\n\tpanic: goroutine ", buf.String())
+}
+
+func TestRecoverNoPanic(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, nil)
+	func() {
+		defer Recover(log, nil)
+	}()
+	assert.Equal(t, "", buf.String())
+}
+
+func TestPanicError(t *testing.T) {
+	inner := errors.New("inner")
+	err := &PanicError{Value: inner, Stack: []byte("stack\n")}
+	assert.Equal(t, "inner", err.Error())
+	assert.ErrorIs(t, err, inner)
+	assert.Equal(t, "inner\nstack", fmt.Sprintf("%+v", err))
+}
@@ -0,0 +1,177 @@
+package nslog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// A registry of named loggers, whose options can be updated at runtime for all of them at once.
+// The name of logger is added as groups split by ".", so levels of names can be set hierarchically.
+type Registry struct {
+	mutex   sync.Mutex
+	writer  io.Writer
+	options LogHandlerOptions
+	base    atomic.Pointer[LogHandler]
+	loggers map[string]*slog.Logger
+
+	configWriter *configWriter // writer opened by LoadConfig
+}
+
+// A handler of named logger, which derives its handler from the current handler of the registry.
+type registryHandler struct {
+	registry *Registry
+	derive   []func(slog.Handler) slog.Handler
+	cache    atomic.Pointer[registryCache]
+}
+
+type registryCache struct {
+	base    *LogHandler
+	handler slog.Handler
+}
+
+var defaultRegistry = sync.OnceValue(func() *Registry {
+	return NewRegistry(os.Stderr, nil)
+})
+
+// Create a new [nslog.Registry] object to create named loggers which write to the writer.
+func NewRegistry(writer io.Writer, options *LogHandlerOptions) *Registry {
+	registry := &Registry{
+		writer:  writer,
+		loggers: map[string]*slog.Logger{},
+	}
+	registry.SetOptions(options)
+	return registry
+}
+
+// Get the default registry, which writes to os.Stderr.
+func DefaultRegistry() *Registry {
+	return defaultRegistry()
+}
+
+// Get the named logger of the default registry.
+func Named(name string) *slog.Logger {
+	return defaultRegistry().Named(name)
+}
+
+// Get the named logger, which is created on first call for the name.
+// The name such as "db.migrations" is added as groups, and empty name is for the root logger.
+func (registry *Registry) Named(name string) *slog.Logger {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	if logger, ok := registry.loggers[name]; ok {
+		return logger
+	}
+	handler := &registryHandler{registry: registry}
+	if name != "" {
+		for _, group := range strings.Split(name, ".") {
+			group := group
+			handler = handler.with(func(h slog.Handler) slog.Handler { return h.WithGroup(group) })
+		}
+	}
+	logger := slog.New(handler)
+	registry.loggers[name] = logger
+	return logger
+}
+
+// Update options of all loggers of the registry. Records being handled are output with the previous options.
+func (registry *Registry) SetOptions(options *LogHandlerOptions) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	if options == nil {
+		options = &LogHandlerOptions{}
+	}
+	registry.options = *options
+	registry.update()
+}
+
+// Update level of loggers of the name and its descendants such as "db" for "db.migrations".
+// Empty name is for the root logger, which updates Level option.
+func (registry *Registry) SetLevel(name string, level slog.Leveler) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	if name == "" {
+		registry.options.Level = level
+	} else {
+		levels := maps.Clone(registry.options.GroupLevels)
+		if levels == nil {
+			levels = map[string]slog.Leveler{}
+		}
+		levels[name] = level
+		registry.options.GroupLevels = levels
+	}
+	registry.update()
+}
+
+// Get copy of current options of the registry.
+func (registry *Registry) Options() LogHandlerOptions {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	return registry.options
+}
+
+// Update the current handler by the options and the writer. The state such as the sequence and stats is shared
+// with the previous handler, and so is the mutex unless the writer is replaced,
+// so records in flight are not written at the same time and the banner is written only once.
+func (registry *Registry) update() {
+	options := registry.options
+	base := registry.base.Load()
+	if base == nil {
+		registry.base.Store(NewLogHandler(registry.writer, &options))
+		return
+	}
+	// resolve default parameters and environment variables without writing the banner and the header
+	resolved := NewLogHandler(nil, &options).options
+	if base.writer != registry.writer {
+		base = base.WithWriter(registry.writer)
+	}
+	registry.base.Store(base.WithOptions(func(options *LogHandlerOptions) {
+		*options = resolved
+	}))
+}
+
+func (handler *registryHandler) with(derive func(slog.Handler) slog.Handler) *registryHandler {
+	return &registryHandler{
+		registry: handler.registry,
+		derive:   append(handler.derive[:len(handler.derive):len(handler.derive)], derive),
+	}
+}
+
+// Get the handler derived from the current handler of the registry, which is cached until options are updated.
+func (handler *registryHandler) current() slog.Handler {
+	base := handler.registry.base.Load()
+	cache := handler.cache.Load()
+	if cache != nil && cache.base == base {
+		return cache.handler
+	}
+	var derived slog.Handler = base
+	for _, derive := range handler.derive {
+		derived = derive(derived)
+	}
+	handler.cache.Store(&registryCache{base: base, handler: derived})
+	return derived
+}
+
+func (handler *registryHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return handler.current().Enabled(ctx, level)
+}
+
+func (handler *registryHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return handler.with(func(h slog.Handler) slog.Handler { return h.WithAttrs(attrs) })
+}
+
+func (handler *registryHandler) WithGroup(name string) slog.Handler {
+	return handler.with(func(h slog.Handler) slog.Handler { return h.WithGroup(name) })
+}
+
+func (handler *registryHandler) Handle(ctx context.Context, record slog.Record) error {
+	return handler.current().Handle(ctx, record)
+}
@@ -0,0 +1,80 @@
+package nslog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryNamed(t *testing.T) {
+	buf := new(bytes.Buffer)
+	registry := NewRegistry(buf, nil)
+	assert.Same(t, registry.Named("http"), registry.Named("http"))
+
+	registry.Named("").Info("message1")
+	registry.Named("db.migrations").With("id", 1).Info("message2", "key1", "val1")
+	assert.Contains(t, buf.String(), "INFO. message1\n")
+	assert.Contains(t, buf.String(), "INFO. db.migrations[id=1]: message2 db.migrations.key1=val1\n")
+}
+
+func TestRegistrySetLevel(t *testing.T) {
+	buf := new(bytes.Buffer)
+	registry := NewRegistry(buf, nil)
+	http := registry.Named("http").With("id", 1)
+	db := registry.Named("db.migrations")
+
+	http.Debug("message1")
+	db.Debug("message2")
+	registry.SetLevel("db", slog.LevelDebug)
+	http.Debug("message3")
+	db.Debug("message4")
+	registry.SetLevel("", slog.LevelDebug)
+	http.Debug("message5")
+	assert.NotContains(t, buf.String(), "message1")
+	assert.NotContains(t, buf.String(), "message2")
+	assert.NotContains(t, buf.String(), "message3")
+	assert.Contains(t, buf.String(), "DEBUG db.migrations: message4\n")
+	assert.Contains(t, buf.String(), "DEBUG http[id=1]: message5\n")
+}
+
+func TestRegistrySetLevelConcurrently(t *testing.T) {
+	buf := new(bytes.Buffer)
+	registry := NewRegistry(buf, &LogHandlerOptions{AddSequence: true, CollectStats: true})
+	logger := registry.Named("app")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.Info("message")
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		registry.SetLevel("app", slog.LevelDebug)
+	}
+	wg.Wait()
+
+	// the sequence and stats are not reset by updates
+	assert.Equal(t, 400, strings.Count(buf.String(), "INFO. app: message\n"))
+	assert.Contains(t, buf.String(), "#400 ")
+	assert.Equal(t, uint64(400), registry.base.Load().Stats().Info)
+}
+
+func TestRegistrySetOptions(t *testing.T) {
+	buf := new(bytes.Buffer)
+	registry := NewRegistry(buf, nil)
+	logger := registry.Named("http")
+	logger.Info("message1")
+	registry.SetOptions(&LogHandlerOptions{LevelStyle: LevelStyleShort, OmitTime: true})
+	logger.Info("message2")
+	assert.Contains(t, buf.String(), "INFO. http: message1\n")
+	assert.Contains(t, buf.String(), "\nI http: message2\n")
+	assert.Equal(t, LevelStyleShort, registry.Options().LevelStyle)
+}
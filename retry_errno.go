@@ -0,0 +1,8 @@
+//go:build !plan9
+
+package nslog
+
+import "syscall"
+
+// Errors of system calls which may succeed by retry.
+var transientErrnos = []error{syscall.EAGAIN, syscall.EINTR, syscall.EPIPE, syscall.ECONNRESET, syscall.ECONNREFUSED, syscall.ECONNABORTED}
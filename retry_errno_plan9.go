@@ -0,0 +1,6 @@
+//go:build plan9
+
+package nslog
+
+// Errors of system calls on Plan 9 are strings without errno, so only timeouts and short writes are transient.
+var transientErrnos []error
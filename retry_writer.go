@@ -0,0 +1,133 @@
+package nslog
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const DEFAULT_RETRY_ATTEMPTS = 3
+const DEFAULT_RETRY_BACKOFF = 10 * time.Millisecond
+const DEFAULT_RETRY_MAX_BACKOFF = time.Second
+
+// An option to customize [nslog.RetryWriter].
+type RetryWriterOptions struct {
+	Attempts   int           // Set maximum number of attempts to write including the first one. (default: 3)
+	Backoff    time.Duration // Set time to wait before the first retry, which is doubled per retry. (default: 10 milliseconds)
+	MaxBackoff time.Duration // Set maximum time to wait before retry. (default: 1 second)
+
+	// Set function to check whether the error is transient to retry.
+	// (default: nil, which retries EAGAIN, EINTR, broken pipe, connection errors, and timeouts)
+	Retryable func(err error) bool
+
+	// Set function to open a new writer before retry, such as dialing again for broken pipe.
+	// The old writer is closed if it implements [io.Closer]. (default: nil, which retries with the same writer)
+	Reconnect func() (io.Writer, error)
+}
+
+// A writer to retry writes to a flaky destination with exponential backoff for transient errors.
+// Partially written data is not written again.
+type RetryWriter struct {
+	mutex   sync.Mutex
+	writer  io.Writer
+	options RetryWriterOptions
+}
+
+// Create a new [nslog.RetryWriter] object.
+func NewRetryWriter(writer io.Writer, options *RetryWriterOptions) *RetryWriter {
+	// set default parameters
+	if options == nil {
+		options = &RetryWriterOptions{}
+	}
+	if options.Attempts <= 0 {
+		options.Attempts = DEFAULT_RETRY_ATTEMPTS
+	}
+	if options.Backoff <= 0 {
+		options.Backoff = DEFAULT_RETRY_BACKOFF
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = DEFAULT_RETRY_MAX_BACKOFF
+	}
+	if options.Retryable == nil {
+		options.Retryable = isTransient
+	}
+
+	return &RetryWriter{
+		writer:  writer,
+		options: *options,
+	}
+}
+
+func (writer *RetryWriter) Write(p []byte) (int, error) {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	written := 0
+	backoff := writer.options.Backoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		if writer.writer != nil {
+			var n int
+			n, err = writer.writer.Write(p[written:])
+			written += n
+			if err == nil {
+				return written, nil
+			}
+			if !writer.options.Retryable(err) {
+				return written, err
+			}
+		}
+		if attempt >= writer.options.Attempts {
+			if err == nil {
+				// no writer is connected
+				err = io.ErrShortWrite
+			}
+			return written, err
+		}
+
+		time.Sleep(backoff)
+		backoff = min(backoff*2, writer.options.MaxBackoff)
+		if writer.options.Reconnect != nil {
+			err = writer.reconnect()
+		}
+	}
+}
+
+// Close the writer and open a new one by Reconnect option. The writer is nil if it fails.
+func (writer *RetryWriter) reconnect() error {
+	if closer, ok := writer.writer.(io.Closer); ok {
+		_ = closer.Close()
+	}
+	writer.writer = nil
+	newWriter, err := writer.options.Reconnect()
+	if err != nil {
+		return err
+	}
+	writer.writer = newWriter
+	return nil
+}
+
+// Close the underlying writer if it implements [io.Closer].
+func (writer *RetryWriter) Close() error {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	closer, ok := writer.writer.(io.Closer)
+	if !ok {
+		return nil
+	}
+	return closer.Close()
+}
+
+// Check whether the error is transient, such as EAGAIN, EINTR, broken pipe, connection errors, and timeouts.
+func isTransient(err error) bool {
+	for _, errno := range transientErrnos {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	var netErr net.Error
+	return errors.Is(err, io.ErrShortWrite) || errors.Is(err, os.ErrDeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
@@ -0,0 +1,89 @@
+package nslog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type flakyWriter struct {
+	bytes.Buffer
+	errs   []error
+	closed bool
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	if len(w.errs) > 0 {
+		err := w.errs[0]
+		w.errs = w.errs[1:]
+		// write a half of data to test partial writes
+		n, _ := w.Buffer.Write(p[:len(p)/2])
+		return n, err
+	}
+	return w.Buffer.Write(p)
+}
+
+func (w *flakyWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestRetryWriter(t *testing.T) {
+	flaky := &flakyWriter{errs: []error{syscall.EAGAIN, syscall.EINTR}}
+	writer := NewRetryWriter(flaky, &RetryWriterOptions{Backoff: 1})
+	n, err := writer.Write([]byte("log message\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 12, n)
+	assert.Equal(t, "log message\n", flaky.String())
+}
+
+func TestRetryWriterAttempts(t *testing.T) {
+	flaky := &flakyWriter{errs: []error{syscall.EAGAIN, syscall.EAGAIN, syscall.EAGAIN}}
+	writer := NewRetryWriter(flaky, &RetryWriterOptions{Attempts: 2, Backoff: 1})
+	n, err := writer.Write([]byte("log message\n"))
+	assert.ErrorIs(t, err, syscall.EAGAIN)
+	assert.Equal(t, 9, n)
+	assert.Len(t, flaky.errs, 1)
+}
+
+func TestRetryWriterNilWriter(t *testing.T) {
+	writer := NewRetryWriter(nil, &RetryWriterOptions{Attempts: 1, Backoff: 1})
+	n, err := writer.Write([]byte("log message\n"))
+	assert.ErrorIs(t, err, io.ErrShortWrite)
+	assert.Equal(t, 0, n)
+}
+
+func TestRetryWriterNotRetryable(t *testing.T) {
+	flaky := &flakyWriter{errs: []error{errors.New("fatal")}}
+	writer := NewRetryWriter(flaky, &RetryWriterOptions{Backoff: 1})
+	_, err := writer.Write([]byte("log message\n"))
+	assert.EqualError(t, err, "fatal")
+	assert.Equal(t, "log me", flaky.String())
+}
+
+func TestRetryWriterReconnect(t *testing.T) {
+	broken := &flakyWriter{errs: []error{syscall.EPIPE}}
+	renewed := &flakyWriter{}
+	reconnects := 0
+	writer := NewRetryWriter(broken, &RetryWriterOptions{Backoff: 1, Reconnect: func() (io.Writer, error) {
+		reconnects++
+		if reconnects == 1 {
+			return nil, syscall.ECONNREFUSED
+		}
+		return renewed, nil
+	}})
+	n, err := writer.Write([]byte("log message\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 12, n)
+	assert.True(t, broken.closed)
+	assert.Equal(t, 2, reconnects)
+	assert.Equal(t, "log me", broken.String())
+	assert.Equal(t, "ssage\n", renewed.String())
+
+	assert.NoError(t, writer.Close())
+	assert.True(t, renewed.closed)
+}
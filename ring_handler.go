@@ -0,0 +1,198 @@
+package nslog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+const DEFAULT_RING_SIZE = 1000
+const DEFAULT_RING_FOLLOW_BUFFER = 100
+
+// A handler to keep the last records in memory while passing records to the next handler.
+// The kept records can be output by [RingHandler.Dump] as "flight recorder".
+type RingHandler struct {
+	next      slog.Handler
+	formatter *LogHandler
+	ring      *ring
+}
+
+type ring struct {
+	mutex       sync.Mutex
+	entries     []ringEntry
+	next        int
+	full        bool
+	subscribers map[chan ringEntry]struct{} // channels of Follow to receive new records
+}
+
+// A kept record, which does not keep the context not to retain request-scoped values, cancel functions, and deadlines.
+// Attributes of the context are resolved into the line on Handle.
+type ringEntry struct {
+	record slog.Record
+	line   []byte // line formatted once on Handle, so sequence, delta, and lazy values are not evaluated again on read
+}
+
+// Create a new [nslog.RingHandler] object, which keeps the last size records.
+// The next handler can be nil if records are only kept in memory.
+// The options is used to format records when they are kept, and the lines are output by [RingHandler.Dump]. By default, Debug logs are also kept.
+func NewRingHandler(next slog.Handler, size int, options *LogHandlerOptions) *RingHandler {
+	// set default parameters
+	if size <= 0 {
+		size = DEFAULT_RING_SIZE
+	}
+	var formatOptions LogHandlerOptions
+	if options != nil {
+		formatOptions = *options
+	}
+	formatter := NewLogHandler(nil, &formatOptions)
+	if options == nil || options.Level == nil {
+		// keep all records regardless of environment variable
+		formatter.options.Level = slog.LevelDebug
+	}
+
+	return &RingHandler{
+		next:      next,
+		formatter: formatter,
+		ring:      &ring{entries: make([]ringEntry, size)},
+	}
+}
+
+func (handler *RingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return handler.formatter.Enabled(ctx, level) || (handler.next != nil && handler.next.Enabled(ctx, level))
+}
+
+func (handler *RingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	new_handler := &RingHandler{
+		formatter: handler.formatter.WithAttrs(attrs).(*LogHandler),
+		ring:      handler.ring,
+	}
+	if handler.next != nil {
+		new_handler.next = handler.next.WithAttrs(attrs)
+	}
+	return new_handler
+}
+
+func (handler *RingHandler) WithGroup(name string) slog.Handler {
+	new_handler := &RingHandler{
+		formatter: handler.formatter.WithGroup(name).(*LogHandler),
+		ring:      handler.ring,
+	}
+	if handler.next != nil {
+		new_handler.next = handler.next.WithGroup(name)
+	}
+	return new_handler
+}
+
+func (handler *RingHandler) Handle(ctx context.Context, record slog.Record) error {
+	if handler.formatter.Enabled(ctx, record.Level) {
+		handler.ring.add(ringEntry{record: record.Clone(), line: handler.formatter.format(ctx, record)})
+	}
+	if handler.next != nil && handler.next.Enabled(ctx, record.Level) {
+		return handler.next.Handle(ctx, record)
+	}
+	return nil
+}
+
+// Get number of kept records.
+func (handler *RingHandler) Len() int {
+	handler.ring.mutex.Lock()
+	defer handler.ring.mutex.Unlock()
+	if handler.ring.full {
+		return len(handler.ring.entries)
+	}
+	return handler.ring.next
+}
+
+// Write kept records to the writer from the oldest one.
+func (handler *RingHandler) Dump(writer io.Writer) error {
+	for _, entry := range handler.ring.snapshot() {
+		_, err := writer.Write(entry.line)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Call the function with kept records from the oldest one and their formatted lines, and then with new records
+// until the context is done or the function returns an error. New records are dropped if the function is slower than logging.
+func (handler *RingHandler) Follow(ctx context.Context, f func(record slog.Record, line []byte) error) error {
+	entries, channel := handler.ring.subscribe()
+	defer handler.ring.unsubscribe(channel)
+
+	for _, entry := range entries {
+		err := f(entry.record, entry.line)
+		if err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case entry := <-channel:
+			err := f(entry.record, entry.line)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Discard all kept records.
+func (handler *RingHandler) Reset() {
+	handler.ring.mutex.Lock()
+	defer handler.ring.mutex.Unlock()
+	clear(handler.ring.entries)
+	handler.ring.next = 0
+	handler.ring.full = false
+}
+
+func (ring *ring) add(entry ringEntry) {
+	ring.mutex.Lock()
+	defer ring.mutex.Unlock()
+	ring.entries[ring.next] = entry
+	ring.next++
+	if ring.next == len(ring.entries) {
+		ring.next = 0
+		ring.full = true
+	}
+	for channel := range ring.subscribers {
+		select {
+		case channel <- entry:
+		default:
+		}
+	}
+}
+
+func (ring *ring) snapshot() []ringEntry {
+	ring.mutex.Lock()
+	defer ring.mutex.Unlock()
+	return ring.entriesLocked()
+}
+
+// Get kept entries and a channel to receive new entries, so no entry is missed or duplicated between them.
+func (ring *ring) subscribe() ([]ringEntry, chan ringEntry) {
+	ring.mutex.Lock()
+	defer ring.mutex.Unlock()
+	if ring.subscribers == nil {
+		ring.subscribers = map[chan ringEntry]struct{}{}
+	}
+	channel := make(chan ringEntry, DEFAULT_RING_FOLLOW_BUFFER)
+	ring.subscribers[channel] = struct{}{}
+	return ring.entriesLocked(), channel
+}
+
+func (ring *ring) unsubscribe(channel chan ringEntry) {
+	ring.mutex.Lock()
+	defer ring.mutex.Unlock()
+	delete(ring.subscribers, channel)
+}
+
+func (ring *ring) entriesLocked() []ringEntry {
+	if !ring.full {
+		return append([]ringEntry{}, ring.entries[:ring.next]...)
+	}
+	return append(append([]ringEntry{}, ring.entries[ring.next:]...), ring.entries[:ring.next]...)
+}
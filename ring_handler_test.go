@@ -0,0 +1,118 @@
+package nslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRingHandler(t *testing.T) {
+	buf := new(bytes.Buffer)
+	handler := NewRingHandler(NewLogHandler(buf, nil), 3, nil)
+	log := slog.New(handler)
+	log.Debug("message1")
+	log.Info("message2")
+	log.WithGroup("Group1").Debug("message3")
+	log.Warn("message4", "key1", "val1")
+	assert.NotContains(t, buf.String(), "DEBUG")
+	assert.Contains(t, buf.String(), "INFO. message2")
+	assert.Contains(t, buf.String(), "WARN. message4 key1=val1")
+
+	dump := new(bytes.Buffer)
+	assert.NoError(t, handler.Dump(dump))
+	lines := strings.Split(strings.TrimSuffix(dump.String(), "\n"), "\n")
+	assert.Equal(t, 3, handler.Len())
+	assert.Len(t, lines, 3)
+	assert.Regexp(t, DEFAULT_TIME_REGEXP+" INFO\\. message2$", lines[0])
+	assert.Regexp(t, DEFAULT_TIME_REGEXP+" DEBUG Group1: message3$", lines[1])
+	assert.Regexp(t, DEFAULT_TIME_REGEXP+" WARN\\. message4 key1=val1 \\(.+\\)$", lines[2])
+}
+
+func TestRingHandlerWithoutNext(t *testing.T) {
+	handler := NewRingHandler(nil, 0, &LogHandlerOptions{Level: slog.LevelInfo})
+	log := slog.New(handler)
+	log.Debug("message1")
+	log.Info("message2")
+	assert.Equal(t, 1, handler.Len())
+
+	handler.Reset()
+	assert.Equal(t, 0, handler.Len())
+	dump := new(bytes.Buffer)
+	assert.NoError(t, handler.Dump(dump))
+	assert.Empty(t, dump.String())
+}
+
+func TestRingHandlerFormatOnce(t *testing.T) {
+	handler := NewRingHandler(nil, 10, &LogHandlerOptions{OmitTime: true, AddSequence: true, AddDelta: true})
+	log := slog.New(handler)
+	evaluated := 0
+	log.Info("message1", "lazy", Lazy(func() any {
+		evaluated++
+		return evaluated
+	}))
+	log.Info("message2")
+
+	first, second := new(bytes.Buffer), new(bytes.Buffer)
+	assert.NoError(t, handler.Dump(first))
+	assert.NoError(t, handler.Dump(second))
+	assert.Equal(t, first.String(), second.String())
+	assert.Regexp(t, "^#1 \\+0s INFO\\. message1 lazy=1\n#2 \\+[0-9.]+[µn]?s INFO\\. message2\n$", first.String())
+	assert.Equal(t, 1, evaluated)
+}
+
+type ringContextKey struct{}
+
+func TestRingHandlerContext(t *testing.T) {
+	handler := NewRingHandler(nil, 10, &LogHandlerOptions{OmitTime: true})
+	collected := make(chan struct{})
+	func() {
+		value := new([1024]byte)
+		runtime.SetFinalizer(value, func(*[1024]byte) { close(collected) })
+		ctx := AddContextAttrs(context.WithValue(context.Background(), ringContextKey{}, value), slog.String("request", "42"))
+		slog.New(handler).InfoContext(ctx, "log message")
+	}()
+
+	for i := 0; i < 10; i++ {
+		runtime.GC()
+		select {
+		case <-collected:
+			dump := new(bytes.Buffer)
+			assert.NoError(t, handler.Dump(dump))
+			assert.Equal(t, "INFO. log message request=42\n", dump.String())
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	assert.Fail(t, "value of the context is retained by the ring")
+}
+
+var errStop = errors.New("stop")
+
+func TestRingHandlerFollow(t *testing.T) {
+	ring := NewRingHandler(nil, 10, &LogHandlerOptions{OmitTime: true})
+	log := slog.New(ring)
+	log.Info("message 1")
+
+	lines := make(chan string, 10)
+	done := make(chan error)
+	go func() {
+		done <- ring.Follow(context.Background(), func(record slog.Record, line []byte) error {
+			lines <- string(line)
+			if record.Message == "message 2" {
+				return errStop
+			}
+			return nil
+		})
+	}()
+	assert.Equal(t, "INFO. message 1\n", <-lines)
+	log.Info("message 2")
+	assert.Equal(t, "INFO. message 2\n", <-lines)
+	assert.ErrorIs(t, <-done, errStop)
+}
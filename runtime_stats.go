@@ -0,0 +1,41 @@
+package nslog
+
+import (
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+const DEFAULT_RUNTIME_STATS_INTERVAL = 10 * time.Second
+
+// Get a snapshot of runtime stats as "runtime" group by RuntimeStatsLevel option such as
+// "runtime.heap_inuse=1234567 runtime.goroutines=42 runtime.gc=12 runtime.gc_pause=120µs runtime.gc_pause_total=3ms".
+// It returns false if the level is lower than the option or the previous snapshot is within RuntimeStatsInterval option.
+func (handler *LogHandler) runtimeStats(level slog.Level) (slog.Attr, bool) {
+	if handler.options.RuntimeStatsLevel == nil || level < handler.options.RuntimeStatsLevel.Level() {
+		return slog.Attr{}, false
+	}
+	now := time.Now().UnixNano()
+	last := handler.state.runtime.Load()
+	if last != 0 && now-last < int64(handler.options.RuntimeStatsInterval) {
+		return slog.Attr{}, false
+	}
+	if !handler.state.runtime.CompareAndSwap(last, now) {
+		// another record takes the snapshot
+		return slog.Attr{}, false
+	}
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	var lastPause time.Duration
+	if stats.NumGC > 0 {
+		lastPause = time.Duration(stats.PauseNs[(stats.NumGC+255)%256])
+	}
+	return slog.Group("runtime",
+		slog.Uint64("heap_inuse", stats.HeapInuse),
+		slog.Int("goroutines", runtime.NumGoroutine()),
+		slog.Uint64("gc", uint64(stats.NumGC)),
+		slog.Duration("gc_pause", lastPause),
+		slog.Duration("gc_pause_total", time.Duration(stats.PauseTotalNs)),
+	), true
+}
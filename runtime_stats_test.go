@@ -0,0 +1,35 @@
+package nslog
+
+import (
+	"bytes"
+	"log/slog"
+	"math"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuntimeStats(t *testing.T) {
+	runtime.GC()
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true, AddSourceLevel: slog.Level(math.MaxInt32), RuntimeStatsLevel: slog.LevelError, RuntimeStatsInterval: time.Hour})
+	log.Warn("warn message")
+	log.Error("error message1")
+	log.With("key1", "val1").Error("error message2")
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, "WARN. warn message", lines[0])
+	assert.Regexp(t, "^ERROR error message1 runtime\\.heap_inuse=[1-9][0-9]* runtime\\.goroutines=[1-9][0-9]* runtime\\.gc=[1-9][0-9]* runtime\\.gc_pause=[^ ]+ runtime\\.gc_pause_total=[^ ]+$", lines[1])
+	assert.Equal(t, "ERROR [key1=val1]: error message2", lines[2])
+}
+
+func TestRuntimeStatsEnv(t *testing.T) {
+	t.Setenv("GO_NSLOG_RUNTIME_STATS_LEVEL", "WARN")
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true, AddSourceLevel: slog.Level(math.MaxInt32)})
+	log.Warn("warn message")
+	assert.Contains(t, buf.String(), " runtime.goroutines=")
+}
@@ -0,0 +1,94 @@
+package nslog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Counters of calls per call site of [nslog.SampledLogger].
+var sampledSites sync.Map // map[uintptr]*atomic.Uint64
+
+// A logger to suppress repetitive records per call site, such as warnings inside loops.
+// Calls are counted per call site in the process, so it can be created on each call:
+//
+//	for _, item := range items {
+//		nslog.Every(logger, 100).Warn("invalid item", "id", item.ID)
+//	}
+type SampledLogger struct {
+	logger *slog.Logger
+	every  uint64 // 0 means once
+}
+
+// Create a new [nslog.SampledLogger] object to output only the first record per call site.
+func Once(logger *slog.Logger) SampledLogger {
+	return SampledLogger{logger: logger}
+}
+
+// Create a new [nslog.SampledLogger] object to output the first record and every n-th record per call site,
+// where number of suppressed records is added as "suppressed" attribute.
+func Every(logger *slog.Logger, n int) SampledLogger {
+	if n < 1 {
+		n = 1
+	}
+	return SampledLogger{logger: logger, every: uint64(n)}
+}
+
+func (l SampledLogger) Debug(msg string, args ...any) {
+	l.log(context.Background(), slog.LevelDebug, msg, args)
+}
+
+func (l SampledLogger) Info(msg string, args ...any) {
+	l.log(context.Background(), slog.LevelInfo, msg, args)
+}
+
+func (l SampledLogger) Warn(msg string, args ...any) {
+	l.log(context.Background(), slog.LevelWarn, msg, args)
+}
+
+func (l SampledLogger) Error(msg string, args ...any) {
+	l.log(context.Background(), slog.LevelError, msg, args)
+}
+
+func (l SampledLogger) Log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	l.log(ctx, level, msg, args)
+}
+
+func (l SampledLogger) log(ctx context.Context, level slog.Level, msg string, args []any) {
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	// skip [runtime.Callers], this function, and the exported method
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	counter, ok := sampledSites.Load(pcs[0])
+	if !ok {
+		counter, _ = sampledSites.LoadOrStore(pcs[0], new(atomic.Uint64))
+	}
+	count := counter.(*atomic.Uint64).Add(1)
+
+	var suppressed uint64
+	if l.every == 0 {
+		if count != 1 {
+			return
+		}
+	} else {
+		if (count-1)%l.every != 0 {
+			return
+		}
+		if count > 1 {
+			suppressed = l.every - 1
+		}
+	}
+
+	record := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	record.Add(args...)
+	if suppressed > 0 {
+		record.AddAttrs(slog.Uint64("suppressed", suppressed))
+	}
+	_ = l.logger.Handler().Handle(ctx, record)
+}
@@ -0,0 +1,42 @@
+package nslog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnce(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, nil)
+	for i := 0; i < 3; i++ {
+		Once(log).Warn("log message1", "i", i)
+		Once(log).Info("log message2", "i", i)
+	}
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" WARN\\. log message1 i=0 \\(sampled_test\\.go:[0-9]+\\)\n"+
+		DEFAULT_TIME_REGEXP+" INFO\\. log message2 i=0\n$", buf.String())
+}
+
+func TestEvery(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, nil)
+	for i := 0; i < 7; i++ {
+		Every(log, 3).Info("log message", "i", i)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 3)
+	assert.Regexp(t, "INFO\\. log message i=0$", lines[0])
+	assert.Regexp(t, "INFO\\. log message i=3 suppressed=2$", lines[1])
+	assert.Regexp(t, "INFO\\. log message i=6 suppressed=2$", lines[2])
+}
+
+func TestEveryDisabled(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, nil)
+	for i := 0; i < 2; i++ {
+		Every(log, 2).Debug("log message", "i", i)
+	}
+	assert.Equal(t, "", buf.String())
+}
@@ -0,0 +1,384 @@
+package nslog
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const DEFAULT_SENTRY_LEVEL = slog.LevelError
+const DEFAULT_SENTRY_QUEUE_SIZE = 100
+const DEFAULT_SENTRY_TIMEOUT = 10 * time.Second
+
+// An option to customize [nslog.SentryHandler].
+type SentryHandlerOptions struct {
+	Level       slog.Leveler // Set level to forward records to Sentry. (default: slog.LevelError)
+	Environment string       // Set environment of events. (default: "")
+	Release     string       // Set release of events. (default: "")
+	ServerName  string       // Set server name of events. (default: "")
+	QueueSize   int          // Set size of queue for async delivery. Events are dropped if queue is full. (default: 100)
+	Client      *http.Client // Set HTTP client to send events. (default: client with 10 seconds timeout)
+	OnError     func(error)  // Set function called when sending event is failed. (default: nil)
+}
+
+// A handler to forward high-severity records to Sentry while passing all records to the next handler.
+type SentryHandler struct {
+	next   slog.Handler
+	attrs  []sentryAttrs
+	groups []string
+	sender *sentrySender
+}
+
+// Attributes added by WithAttrs with the qualifier of groups of the handler at that time such as "group.".
+type sentryAttrs struct {
+	qualifier string
+	attrs     []slog.Attr
+}
+
+type sentrySender struct {
+	storeURL string
+	auth     string
+	options  SentryHandlerOptions
+	queue    chan []byte
+	done     chan struct{}
+	once     sync.Once
+	stopped  atomic.Bool
+	mutex    sync.RWMutex // guard of closed, which is locked for reading to send and for writing to close the queue
+	closed   bool
+}
+
+type sentryFrame struct {
+	Filename string `json:"filename"`
+	AbsPath  string `json:"abs_path"`
+	Function string `json:"function"`
+	Lineno   int    `json:"lineno"`
+}
+
+type sentryStacktrace struct {
+	Frames []sentryFrame `json:"frames"`
+}
+
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Level       string            `json:"level"`
+	Logger      string            `json:"logger,omitempty"`
+	Platform    string            `json:"platform"`
+	Message     string            `json:"message"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Extra       map[string]any    `json:"extra,omitempty"`
+	Stacktrace  *sentryStacktrace `json:"stacktrace,omitempty"`
+}
+
+// Create a new [nslog.SentryHandler] object, which starts goroutine to send events.
+// The dsn is a Sentry DSN such as "https://<key>@<host>/<project>".
+// Call [SentryHandler.Close] to stop the goroutine.
+func NewSentryHandler(next slog.Handler, dsn string, options *SentryHandlerOptions) (*SentryHandler, error) {
+	// set default parameters
+	if options == nil {
+		options = &SentryHandlerOptions{}
+	}
+	if options.Level == nil {
+		options.Level = DEFAULT_SENTRY_LEVEL
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = DEFAULT_SENTRY_QUEUE_SIZE
+	}
+	if options.Client == nil {
+		options.Client = &http.Client{Timeout: DEFAULT_SENTRY_TIMEOUT}
+	}
+
+	// parse dsn
+	dsnURL, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("nslog: invalid sentry dsn: %w", err)
+	}
+	if dsnURL.User == nil || dsnURL.User.Username() == "" {
+		return nil, errors.New("nslog: invalid sentry dsn: public key is missing")
+	}
+	projectIndex := strings.LastIndex(dsnURL.Path, "/")
+	projectID := dsnURL.Path[projectIndex+1:]
+	if projectID == "" {
+		return nil, errors.New("nslog: invalid sentry dsn: project id is missing")
+	}
+	storeURL := dsnURL.Scheme + "://" + dsnURL.Host + dsnURL.Path[:projectIndex] + "/api/" + projectID + "/store/"
+	auth := "Sentry sentry_version=7, sentry_client=nslog/1.0, sentry_key=" + dsnURL.User.Username()
+	if secret, ok := dsnURL.User.Password(); ok {
+		auth += ", sentry_secret=" + secret
+	}
+
+	sender := &sentrySender{
+		storeURL: storeURL,
+		auth:     auth,
+		options:  *options,
+		queue:    make(chan []byte, options.QueueSize),
+		done:     make(chan struct{}),
+	}
+	go sender.run()
+
+	return &SentryHandler{
+		next:   next,
+		sender: sender,
+	}, nil
+}
+
+func (handler *SentryHandler) clone() *SentryHandler {
+	return &SentryHandler{
+		next:   handler.next,
+		attrs:  slices.Clip(handler.attrs),
+		groups: slices.Clip(handler.groups),
+		sender: handler.sender,
+	}
+}
+
+func (handler *SentryHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= handler.sender.options.Level.Level() || handler.next.Enabled(ctx, level)
+}
+
+func (handler *SentryHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	new_handler := handler.clone()
+	new_handler.next = handler.next.WithAttrs(attrs)
+	new_handler.attrs = append(new_handler.attrs, sentryAttrs{qualifier: handler.qualifier(), attrs: attrs})
+	return new_handler
+}
+
+func (handler *SentryHandler) WithGroup(name string) slog.Handler {
+	new_handler := handler.clone()
+	new_handler.next = handler.next.WithGroup(name)
+	new_handler.groups = append(new_handler.groups, name)
+	return new_handler
+}
+
+// Get the qualifier of keys by groups of the handler such as "group.", which is empty without groups.
+func (handler *SentryHandler) qualifier() string {
+	if len(handler.groups) == 0 {
+		return ""
+	}
+	return strings.Join(handler.groups, ".") + "."
+}
+
+func (handler *SentryHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= handler.sender.options.Level.Level() {
+		handler.sender.enqueue(handler.event(record))
+	}
+	if handler.next.Enabled(ctx, record.Level) {
+		return handler.next.Handle(ctx, record)
+	}
+	return nil
+}
+
+// Stop goroutine after sending queued events.
+func (handler *SentryHandler) Close() error {
+	handler.StopIntake()
+	return handler.Drain(context.Background())
+}
+
+// Get number of events waiting in the queue.
+func (handler *SentryHandler) QueueDepth() int {
+	return len(handler.sender.queue)
+}
+
+// Stop accepting new records. Records handled after this are dropped.
+func (handler *SentryHandler) StopIntake() {
+	handler.sender.stopped.Store(true)
+}
+
+// Wait until queued events are sent and stop goroutine, or the context is done.
+func (handler *SentryHandler) Drain(ctx context.Context) error {
+	handler.sender.once.Do(func() {
+		handler.sender.mutex.Lock()
+		handler.sender.closed = true
+		close(handler.sender.queue)
+		handler.sender.mutex.Unlock()
+	})
+	select {
+	case <-handler.sender.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (handler *SentryHandler) event(record slog.Record) *sentryEvent {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	event := &sentryEvent{
+		EventID:     hex.EncodeToString(id),
+		Timestamp:   record.Time.UTC().Format(time.RFC3339Nano),
+		Level:       sentryLevel(record.Level),
+		Logger:      strings.Join(handler.groups, "."),
+		Platform:    "go",
+		Message:     record.Message,
+		Environment: handler.sender.options.Environment,
+		Release:     handler.sender.options.Release,
+		ServerName:  handler.sender.options.ServerName,
+		Extra:       map[string]any{},
+	}
+
+	// attributes with keys qualified by groups such as "group.key", so keys in different groups do not collide
+	for _, handlerAttrs := range handler.attrs {
+		for _, attribute := range handlerAttrs.attrs {
+			addSentryExtra(event.Extra, handlerAttrs.qualifier, attribute)
+		}
+	}
+	qualifier := handler.qualifier()
+	record.Attrs(func(attribute slog.Attr) bool {
+		addSentryExtra(event.Extra, qualifier, attribute)
+		return true
+	})
+
+	// stack (Sentry expects the oldest frame first)
+	if record.PC != 0 {
+		var frames []sentryFrame
+		callers := runtime.CallersFrames(callerStack(record.PC))
+		for {
+			frame, more := callers.Next()
+			frames = append([]sentryFrame{{
+				Filename: filepath.Base(frame.File),
+				AbsPath:  frame.File,
+				Function: frame.Function,
+				Lineno:   frame.Line,
+			}}, frames...)
+			if !more {
+				break
+			}
+		}
+		event.Stacktrace = &sentryStacktrace{Frames: frames}
+	}
+
+	return event
+}
+
+// Add the attribute to extra with the qualified key after resolving its value. Attributes of a group are flattened
+// with keys such as "group.key", and empty attributes are ignored same as [nslog.LogHandler].
+func addSentryExtra(extra map[string]any, qualifier string, attribute slog.Attr) {
+	attribute.Value = attribute.Value.Resolve()
+	if attribute.Equal(slog.Attr{}) {
+		return
+	}
+	if attribute.Value.Kind() == slog.KindGroup {
+		if attribute.Key != "" {
+			qualifier += attribute.Key + "."
+		}
+		for _, member := range attribute.Value.Group() {
+			addSentryExtra(extra, qualifier, member)
+		}
+		return
+	}
+	extra[qualifier+attribute.Key] = sentryValue(attribute.Value)
+}
+
+// Convert the value to a JSON value of extra, which keeps numbers and booleans and formats others as a string.
+func sentryValue(value slog.Value) any {
+	switch value.Kind() {
+	case slog.KindInt64, slog.KindUint64, slog.KindFloat64, slog.KindBool:
+		return value.Any()
+	case slog.KindTime:
+		return value.Time().UTC().Format(time.RFC3339Nano)
+	}
+	if err, ok := value.Any().(error); ok {
+		return err.Error()
+	}
+	return value.String()
+}
+
+func sentryLevel(level slog.Level) string {
+	switch {
+	case level > slog.LevelError:
+		return "fatal"
+	case level >= slog.LevelError:
+		return "error"
+	case level >= slog.LevelWarn:
+		return "warning"
+	case level >= slog.LevelInfo:
+		return "info"
+	default:
+		return "debug"
+	}
+}
+
+// Get stack of callers starting at the given pc if the pc is on the current stack.
+// Otherwise only the given pc is returned.
+func callerStack(pc uintptr) []uintptr {
+	pcs := make([]uintptr, 64)
+	pcs = pcs[:runtime.Callers(1, pcs)]
+	for i, caller := range pcs {
+		if caller == pc {
+			return pcs[i:]
+		}
+	}
+	return []uintptr{pc}
+}
+
+func (sender *sentrySender) enqueue(event *sentryEvent) {
+	if sender.stopped.Load() {
+		return
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		if sender.options.OnError != nil {
+			sender.options.OnError(err)
+		}
+		return
+	}
+
+	sender.mutex.RLock()
+	defer sender.mutex.RUnlock()
+	if sender.closed {
+		return
+	}
+	select {
+	case sender.queue <- body:
+	default:
+		if sender.options.OnError != nil {
+			sender.options.OnError(errors.New("nslog: sentry queue is full"))
+		}
+	}
+}
+
+func (sender *sentrySender) run() {
+	defer close(sender.done)
+	for body := range sender.queue {
+		err := sender.send(body)
+		if err != nil && sender.options.OnError != nil {
+			sender.options.OnError(err)
+		}
+	}
+}
+
+func (sender *sentrySender) send(body []byte) error {
+	request, err := http.NewRequest(http.MethodPost, sender.storeURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Sentry-Auth", sender.auth)
+
+	response, err := sender.options.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("nslog: sentry returned status %s", response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package nslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSentryHandler(t *testing.T) {
+	var auth string
+	var events []sentryEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/42/store/", r.URL.Path)
+		auth = r.Header.Get("X-Sentry-Auth")
+		event := sentryEvent{}
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		events = append(events, event)
+	}))
+	defer server.Close()
+
+	buf := new(bytes.Buffer)
+	dsn := strings.Replace(server.URL, "http://", "http://public@", 1) + "/42"
+	handler, err := NewSentryHandler(NewLogHandler(buf, nil), dsn, &SentryHandlerOptions{Environment: "test"})
+	assert.NoError(t, err)
+	log := slog.New(handler).With("id", 1)
+	log.Info("info message")
+	log.Error("error message", "key1", "val1")
+	log.WithGroup("g").With("id", 2).Error("group message", "user", lazyUser{"alice"}, slog.Group("req", "ok", true))
+	handler.Close()
+
+	assert.Contains(t, buf.String(), "INFO. [id=1]: info message")
+	assert.Contains(t, buf.String(), "ERROR [id=1]: error message key1=val1")
+	assert.Contains(t, auth, "sentry_key=public")
+	assert.Len(t, events, 2)
+	assert.Equal(t, "error", events[0].Level)
+	assert.Equal(t, "error message", events[0].Message)
+	assert.Equal(t, "test", events[0].Environment)
+	assert.Equal(t, map[string]any{"id": float64(1), "key1": "val1"}, events[0].Extra)
+	frames := events[0].Stacktrace.Frames
+	assert.Equal(t, "sentry_handler_test.go", frames[len(frames)-1].Filename)
+
+	// keys are qualified by groups, and values of LogValuer are resolved
+	assert.Equal(t, map[string]any{"id": float64(1), "g.id": float64(2), "g.user.name": "alice", "g.req.ok": true}, events[1].Extra)
+}
+
+type lazyUser struct {
+	name string
+}
+
+func (user lazyUser) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("name", user.name))
+}
+
+func TestSentryHandlerInvalidDSN(t *testing.T) {
+	_, err := NewSentryHandler(NewLogHandler(nil, nil), "https://sentry.example.com/42", nil)
+	assert.Error(t, err)
+	_, err = NewSentryHandler(NewLogHandler(nil, nil), "https://public@sentry.example.com/", nil)
+	assert.Error(t, err)
+}
+
+func TestSentryHandlerClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	dsn := strings.Replace(server.URL, "http://", "http://public@", 1) + "/42"
+	handler, err := NewSentryHandler(NewLogHandler(io.Discard, nil), dsn, nil)
+	assert.NoError(t, err)
+	log := slog.New(handler)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Error("error message")
+		}()
+	}
+	assert.NoError(t, handler.Close())
+	wg.Wait()
+	assert.NotPanics(t, func() { handler.sender.enqueue(&sentryEvent{Message: "error message after close"}) })
+}
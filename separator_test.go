@@ -0,0 +1,38 @@
+package nslog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeparators(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true, FieldSeparator: "\t", KeyValueSeparator: ":", AttrsOpen: "{", AttrsClose: "}", GroupSeparator: "/"})
+	log.With("key1", "val1", "key2", "val2").WithGroup("Main").WithGroup("Sub").Info("log message", "key3", "val3", "key4", "val4")
+	assert.Equal(t, "INFO.\t{key1:val1\tkey2:val2}Main/Sub:\tlog message\tMain/Sub/key3:val3\tMain/Sub/key4:val4\n", buf.String())
+}
+
+func TestSeparatorsDefault(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true})
+	log.With("key1", "val1").WithGroup("Main").Info("log message", "key2", "val2")
+	assert.Equal(t, "INFO. [key1=val1]Main: log message Main.key2=val2\n", buf.String())
+}
+
+func TestSeparatorsLTSV(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true, Format: FormatLTSV, FieldSeparator: "|", GroupSeparator: "_"})
+	log.WithGroup("Main").Info("log message", "key", "val")
+	assert.Equal(t, "level:INFO\tmsg:log message\tMain_key:val\n", buf.String())
+}
+
+func TestSeparatorsEnv(t *testing.T) {
+	t.Setenv("GO_NSLOG_FIELD_SEPARATOR", " | ")
+	t.Setenv("GO_NSLOG_KEY_VALUE_SEPARATOR", ": ")
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true})
+	log.Info("log message", "key", "val")
+	assert.Equal(t, "INFO. | log message | key: val\n", buf.String())
+}
@@ -0,0 +1,75 @@
+package nslog
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// A component which can stop accepting new records, such as an async handler.
+type IntakeStopper interface {
+	StopIntake()
+}
+
+// A component which can wait until its queue is empty, such as an async handler.
+type Drainer interface {
+	Drain(ctx context.Context) error
+}
+
+// A component which can write out its buffer, such as a buffered writer.
+type Flusher interface {
+	Flush() error
+}
+
+// A component which holds open files. It is closed after other components.
+type fileHolder interface {
+	holdsFiles()
+}
+
+func (writer *FileWriter) holdsFiles() {}
+func (cache *FileCache) holdsFiles()   {}
+
+// Shut down composed components (handlers and writers) in the deterministic order, and return aggregated errors:
+//
+//  1. Stop intake of all components implementing [nslog.IntakeStopper].
+//  2. Drain queues of all components implementing [nslog.Drainer].
+//  3. Flush buffers of all components implementing [nslog.Flusher].
+//  4. Close sinks of all components implementing [io.Closer] except for files.
+//  5. Release files, such as [nslog.FileWriter] and [nslog.FileCache].
+//
+// In each step, components are processed in the given order. Draining is stopped when the context is done.
+func Shutdown(ctx context.Context, components ...any) error {
+	var errs []error
+	for _, component := range components {
+		if stopper, ok := component.(IntakeStopper); ok {
+			stopper.StopIntake()
+		}
+	}
+	for _, component := range components {
+		if drainer, ok := component.(Drainer); ok {
+			errs = append(errs, drainer.Drain(ctx))
+		}
+	}
+	for _, component := range components {
+		if flusher, ok := component.(Flusher); ok {
+			errs = append(errs, flusher.Flush())
+		}
+	}
+	for _, component := range components {
+		if _, ok := component.(fileHolder); ok {
+			continue
+		}
+		if closer, ok := component.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	for _, component := range components {
+		if _, ok := component.(fileHolder); !ok {
+			continue
+		}
+		if closer, ok := component.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
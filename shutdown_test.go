@@ -0,0 +1,79 @@
+package nslog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type shutdownRecorder struct {
+	name  string
+	steps *[]string
+	err   error
+}
+
+func (recorder *shutdownRecorder) StopIntake() {
+	*recorder.steps = append(*recorder.steps, recorder.name+".StopIntake")
+}
+
+func (recorder *shutdownRecorder) Drain(_ context.Context) error {
+	*recorder.steps = append(*recorder.steps, recorder.name+".Drain")
+	return nil
+}
+
+func (recorder *shutdownRecorder) Flush() error {
+	*recorder.steps = append(*recorder.steps, recorder.name+".Flush")
+	return nil
+}
+
+func (recorder *shutdownRecorder) Close() error {
+	*recorder.steps = append(*recorder.steps, recorder.name+".Close")
+	return recorder.err
+}
+
+func TestShutdownOrder(t *testing.T) {
+	var steps []string
+	err1 := errors.New("error1")
+	err2 := errors.New("error2")
+	component1 := &shutdownRecorder{name: "1", steps: &steps, err: err1}
+	component2 := &shutdownRecorder{name: "2", steps: &steps, err: err2}
+	err := Shutdown(context.Background(), component1, component2)
+	assert.ErrorIs(t, err, err1)
+	assert.ErrorIs(t, err, err2)
+	assert.Equal(t, []string{
+		"1.StopIntake", "2.StopIntake",
+		"1.Drain", "2.Drain",
+		"1.Flush", "2.Flush",
+		"1.Close", "2.Close",
+	}, steps)
+}
+
+func TestShutdownPipeline(t *testing.T) {
+	var posted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posted = append(posted, r.URL.Path)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "test.log")
+	file, err := NewFileWriter(path, nil)
+	assert.NoError(t, err)
+	alert := NewAlertHandler(server.URL+"/alert", nil)
+	log := slog.New(NewLogHandler(file, nil))
+	alertLog := slog.New(alert)
+	log.Error("message1")
+	alertLog.Error("message1")
+
+	assert.NoError(t, Shutdown(context.Background(), file, alert))
+	alertLog.Error("message2")
+	assert.Len(t, posted, 1)
+	_, err = file.Write([]byte("message2\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
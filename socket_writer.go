@@ -0,0 +1,113 @@
+package nslog
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const DEFAULT_SOCKET_NETWORK = "unix"
+const DEFAULT_SOCKET_DIAL_TIMEOUT = time.Second
+const DEFAULT_SOCKET_RECONNECT_INTERVAL = time.Second
+
+// An option to customize [nslog.SocketWriter].
+type SocketWriterOptions struct {
+	Network           string        // Set network of the socket such as "unix" and "unixgram". (default: "unix")
+	DialTimeout       time.Duration // Set timeout to connect to the socket. (default: 1 second)
+	WriteTimeout      time.Duration // Set timeout to write to the socket. (default: 0, which waits forever)
+	ReconnectInterval time.Duration // Set minimum interval to connect again after connecting is failed. (default: 1 second)
+}
+
+// A writer to a unix domain socket or a Windows named pipe such as `\\.\pipe\name`, so logs can be consumed by a sidecar collector
+// without touching disk. The socket is connected at the first write, and connected again if write is failed such as broken pipe.
+// Data is written again to the new connection, so a line is not split across connections.
+type SocketWriter struct {
+	address string
+	options SocketWriterOptions
+	mutex   sync.Mutex
+	conn    io.WriteCloser
+	retryAt time.Time // time to allow connecting again after failure
+	closed  bool
+}
+
+// Create a new [nslog.SocketWriter] object to write to the socket at the address, which is connected at the first write.
+func NewSocketWriter(address string, options *SocketWriterOptions) *SocketWriter {
+	// set default parameters
+	if options == nil {
+		options = &SocketWriterOptions{}
+	}
+	if options.Network == "" {
+		options.Network = DEFAULT_SOCKET_NETWORK
+	}
+	if options.DialTimeout <= 0 {
+		options.DialTimeout = DEFAULT_SOCKET_DIAL_TIMEOUT
+	}
+	if options.ReconnectInterval <= 0 {
+		options.ReconnectInterval = DEFAULT_SOCKET_RECONNECT_INTERVAL
+	}
+
+	return &SocketWriter{
+		address: address,
+		options: *options,
+	}
+}
+
+// Write the data to the socket, which is connected again and written again once if write is failed.
+// The error is returned without connecting if connecting is failed in the last ReconnectInterval.
+func (writer *SocketWriter) Write(p []byte) (int, error) {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	if writer.closed {
+		return 0, os.ErrClosed
+	}
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		err = writer.connect()
+		if err != nil {
+			return 0, err
+		}
+		if conn, ok := writer.conn.(net.Conn); ok && writer.options.WriteTimeout > 0 {
+			_ = conn.SetWriteDeadline(time.Now().Add(writer.options.WriteTimeout))
+		}
+		_, err = writer.conn.Write(p)
+		if err == nil {
+			return len(p), nil
+		}
+		_ = writer.conn.Close()
+		writer.conn = nil
+	}
+	return 0, fmt.Errorf("nslog: failed to write to socket %s: %w", writer.address, err)
+}
+
+func (writer *SocketWriter) connect() error {
+	if writer.conn != nil {
+		return nil
+	}
+	if time.Now().Before(writer.retryAt) {
+		return fmt.Errorf("nslog: socket %s is disconnected", writer.address)
+	}
+	conn, err := dialSocket(writer.options.Network, writer.address, writer.options.DialTimeout)
+	if err != nil {
+		writer.retryAt = time.Now().Add(writer.options.ReconnectInterval)
+		return fmt.Errorf("nslog: failed to connect to socket %s: %w", writer.address, err)
+	}
+	writer.conn = conn
+	return nil
+}
+
+// Close the connection of the socket.
+func (writer *SocketWriter) Close() error {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	writer.closed = true
+	if writer.conn == nil {
+		return nil
+	}
+	err := writer.conn.Close()
+	writer.conn = nil
+	return err
+}
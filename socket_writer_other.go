@@ -0,0 +1,13 @@
+//go:build !windows
+
+package nslog
+
+import (
+	"io"
+	"net"
+	"time"
+)
+
+func dialSocket(network string, address string, timeout time.Duration) (io.WriteCloser, error) {
+	return net.DialTimeout(network, address, timeout)
+}
@@ -0,0 +1,93 @@
+package nslog
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newSocketPath(t *testing.T) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix domain socket is not tested on windows")
+	}
+	// path of unix domain socket should be short
+	dir, err := os.MkdirTemp("", "nslog")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "app.sock")
+}
+
+func TestSocketWriter(t *testing.T) {
+	path := newSocketPath(t)
+	listener, err := net.Listen("unix", path)
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	writer := NewSocketWriter(path, nil)
+	log := NewLogger(writer, &LogHandlerOptions{OmitTime: true})
+	log.Info("message 1")
+
+	// connect again after the collector restarts
+	conn, err := listener.Accept()
+	assert.NoError(t, err)
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "INFO. message 1\n", line)
+	conn.Close()
+	time.Sleep(10 * time.Millisecond)
+	log.Info("message 2")
+	log.Info("message 3")
+
+	conn, err = listener.Accept()
+	assert.NoError(t, err)
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	line, err = reader.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Contains(t, []string{"INFO. message 2\n", "INFO. message 3\n"}, line)
+	assert.NoError(t, writer.Close())
+	_, err = writer.Write([]byte("message 4\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestSocketWriterReconnectInterval(t *testing.T) {
+	path := newSocketPath(t)
+	writer := NewSocketWriter(path, &SocketWriterOptions{ReconnectInterval: time.Hour})
+	defer writer.Close()
+	_, err := writer.Write([]byte("message 1\n"))
+	assert.ErrorContains(t, err, "nslog: failed to connect to socket")
+
+	// connecting is not tried until the interval is over
+	listener, err := net.Listen("unix", path)
+	assert.NoError(t, err)
+	defer listener.Close()
+	_, err = writer.Write([]byte("message 2\n"))
+	assert.ErrorContains(t, err, "is disconnected")
+
+	writer.retryAt = time.Time{}
+	_, err = writer.Write([]byte("message 3\n"))
+	assert.NoError(t, err)
+}
+
+func TestSocketWriterDatagram(t *testing.T) {
+	path := newSocketPath(t)
+	conn, err := net.ListenPacket("unixgram", path)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	writer, err := OpenOutput("unixgram://" + path)
+	assert.NoError(t, err)
+	defer writer.(*SocketWriter).Close()
+	NewLogger(writer, &LogHandlerOptions{OmitTime: true}).Info("log message")
+
+	buf := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "INFO. log message\n", string(buf[:n]))
+}
@@ -0,0 +1,21 @@
+//go:build windows
+
+package nslog
+
+import (
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// Prefix of path of named pipes, which is opened as a file instead of dialing.
+const NAMED_PIPE_PREFIX = `\\.\pipe\`
+
+func dialSocket(network string, address string, timeout time.Duration) (io.WriteCloser, error) {
+	if strings.HasPrefix(address, NAMED_PIPE_PREFIX) {
+		return os.OpenFile(address, os.O_WRONLY, 0)
+	}
+	return net.DialTimeout(network, address, timeout)
+}
@@ -0,0 +1,105 @@
+package nslog
+
+import (
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Presets of SourceLink option. "{path}" is replaced with absolute filepath and "{line}" is replaced with line number.
+const (
+	SOURCE_LINK_FILE   = "file://{path}"
+	SOURCE_LINK_VSCODE = "vscode://file{path}:{line}"
+)
+
+// Get path of the main module, which is empty if build information is not available.
+var mainModulePath = sync.OnceValue(func() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	return info.Main.Path
+})
+
+// Get pc of the caller which is skip frames above the given pc on the current stack.
+// The given pc is returned if it is not on the current stack.
+func skipCallers(pc uintptr, skip int) uintptr {
+	stack := callerStack(pc)
+	if skip < len(stack) {
+		return stack[skip]
+	}
+	return pc
+}
+
+// Format source of the record from the frame according to options.
+func (handler *LogHandler) sourceString(frame runtime.Frame) string {
+	if handler.options.SourceFormatter != nil {
+		return handler.options.SourceFormatter(frame)
+	}
+
+	var file string
+	switch {
+	case handler.options.SourceModule:
+		file = modulePath(frame)
+	case handler.options.SourceFilePath:
+		file = frame.File
+	default:
+		file = filepath.Base(frame.File)
+	}
+	source := file + ":" + strconv.Itoa(frame.Line)
+	if handler.options.SourceFunction {
+		source += " " + functionName(frame.Function)
+	}
+	return "(" + source + ")"
+}
+
+// Get filepath of the frame relative to the main module such as "pkg/http/server.go".
+// The import path is used for packages out of the main module, and the filename is used for main package.
+func modulePath(frame runtime.Frame) string {
+	name := filepath.Base(frame.File)
+	packagePath := packagePath(frame.Function)
+	if packagePath == "" || packagePath == "main" {
+		return name
+	}
+	module := mainModulePath()
+	if packagePath == module {
+		return name
+	}
+	if module != "" && strings.HasPrefix(packagePath, module+"/") {
+		return packagePath[len(module)+1:] + "/" + name
+	}
+	return packagePath + "/" + name
+}
+
+// Get import path of the package from the fully-qualified function name such as "example.com/pkg/http.(*Server).Serve".
+func packagePath(function string) string {
+	slash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[slash+1:], ".")
+	if dot < 0 {
+		return ""
+	}
+	return function[:slash+1+dot]
+}
+
+// Get function name without the directories of the import path such as "http.(*Server).Serve".
+func functionName(function string) string {
+	return function[strings.LastIndex(function, "/")+1:]
+}
+
+// Make URL of the source from the template.
+func sourceLink(template string, frame runtime.Frame) string {
+	path := filepath.ToSlash(frame.File)
+	if !strings.HasPrefix(path, "/") {
+		// drive letter on Windows such as "C:/src/main.go"
+		path = "/" + path
+	}
+	return strings.NewReplacer("{path}", path, "{line}", strconv.Itoa(frame.Line)).Replace(template)
+}
+
+// Wrap the text with OSC 8 escape sequence to make a terminal hyperlink.
+func hyperlink(url string, text string) string {
+	return "\x1b]8;;" + url + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
@@ -0,0 +1,78 @@
+package nslog
+
+import (
+	"bytes"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSourceModule(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{SourceModule: true})
+	log.Warn("log message")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" WARN\\. log message \\(source_test\\.go:\\d+\\)\n$", buf.String())
+}
+
+func TestSourceFunction(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{SourceFunction: true})
+	log.Warn("log message")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" WARN\\. log message \\(source_test\\.go:\\d+ nslog\\.TestSourceFunction\\)\n$", buf.String())
+}
+
+func TestSourceFormatter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{SourceFormatter: func(frame runtime.Frame) string {
+		return "@" + strconv.Itoa(frame.Line)
+	}})
+	log.Warn("log message")
+	log.Error("log message")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" WARN\\. log message @\\d+\n"+DEFAULT_TIME_REGEXP+" ERROR log message @\\d+\n$", buf.String())
+}
+
+func TestModulePath(t *testing.T) {
+	assert.Equal(t, "main.go", modulePath(runtime.Frame{File: "/src/main.go", Function: "main.main"}))
+	assert.Equal(t, "server.go", modulePath(runtime.Frame{File: "/src/server.go", Function: "github.com/mikiepure/nslog.(*LogHandler).Handle"}))
+	assert.Equal(t, "pkg/http/server.go", modulePath(runtime.Frame{File: "/src/pkg/http/server.go", Function: "github.com/mikiepure/nslog/pkg/http.(*Server).Serve"}))
+	assert.Equal(t, "example.com/other/server.go", modulePath(runtime.Frame{File: "/mod/server.go", Function: "example.com/other.Serve"}))
+}
+
+func TestSourceLink(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{ColorMode: ColorModeAlways, SourceLink: SOURCE_LINK_VSCODE})
+	log.Warn("log message")
+	assert.Regexp(t, " \x1b\\]8;;vscode://file/\\S+/source_test\\.go:\\d+\x1b\\\\\\(source_test\\.go:\\d+\\)\x1b\\]8;;\x1b\\\\\n$", buf.String())
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" \\S+ log message \\(source_test\\.go:\\d+\\)\n$", string(StripColor(buf.Bytes())))
+}
+
+func TestSourceLinkWithoutColor(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{SourceLink: SOURCE_LINK_FILE})
+	log.Warn("log message")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" WARN\\. log message \\(source_test\\.go:\\d+\\)\n$", buf.String())
+}
+
+func TestSourceLinkWindows(t *testing.T) {
+	assert.Equal(t, "file:///C:/src/main.go", sourceLink(SOURCE_LINK_FILE, runtime.Frame{File: "C:/src/main.go", Line: 1}))
+}
+
+func warnWrapper(log *slog.Logger, msg string) {
+	log.Warn(msg)
+}
+
+func TestWithCallerSkip(t *testing.T) {
+	buf := new(bytes.Buffer)
+	handler := NewLogHandler(buf, nil)
+	_, _, line, _ := runtime.Caller(0)
+	warnWrapper(slog.New(handler), "log message1")
+	warnWrapper(slog.New(handler.WithCallerSkip(1)), "log message2")
+	warnWrapper(slog.New(handler.WithCallerSkip(1).WithGroup("Group1")), "log message3")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" WARN\\. log message1 \\(source_test\\.go:\\d+\\)\n", buf.String())
+	assert.Contains(t, buf.String(), "log message2 (source_test.go:"+strconv.Itoa(line+2)+")\n")
+	assert.Contains(t, buf.String(), "log message3 (source_test.go:"+strconv.Itoa(line+3)+")\n")
+	assert.NotContains(t, buf.String(), "log message1 (source_test.go:"+strconv.Itoa(line+1)+")\n")
+}
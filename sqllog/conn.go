@@ -0,0 +1,169 @@
+package sqllog
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+)
+
+// A connection to log queries. Optional interfaces which the connection does not implement return [driver.ErrSkip],
+// so database/sql falls back to the other ways.
+type loggingConn struct {
+	conn   driver.Conn
+	driver *loggingDriver
+}
+
+func (conn *loggingConn) Prepare(query string) (driver.Stmt, error) {
+	return conn.PrepareContext(context.Background(), query)
+}
+
+func (conn *loggingConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := conn.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = conn.conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &loggingStmt{stmt: stmt, query: query, driver: conn.driver}, nil
+}
+
+func (conn *loggingConn) Close() error {
+	return conn.conn.Close()
+}
+
+func (conn *loggingConn) Begin() (driver.Tx, error) {
+	return conn.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (conn *loggingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := conn.conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return conn.conn.Begin()
+}
+
+func (conn *loggingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := conn.conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	conn.driver.log(ctx, query, args, result, err, time.Since(start))
+	return result, err
+}
+
+func (conn *loggingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := conn.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	conn.driver.log(ctx, query, args, nil, err, time.Since(start))
+	return rows, err
+}
+
+func (conn *loggingConn) Ping(ctx context.Context) error {
+	if pinger, ok := conn.conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (conn *loggingConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := conn.conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (conn *loggingConn) IsValid() bool {
+	if validator, ok := conn.conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (conn *loggingConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := conn.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+// A prepared statement to log queries.
+type loggingStmt struct {
+	stmt   driver.Stmt
+	query  string
+	driver *loggingDriver
+}
+
+func (stmt *loggingStmt) Close() error {
+	return stmt.stmt.Close()
+}
+
+func (stmt *loggingStmt) NumInput() int {
+	return stmt.stmt.NumInput()
+}
+
+func (stmt *loggingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return stmt.ExecContext(context.Background(), toNamedValues(args))
+}
+
+func (stmt *loggingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return stmt.QueryContext(context.Background(), toNamedValues(args))
+}
+
+func (stmt *loggingStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var result driver.Result
+	var err error
+	if execer, ok := stmt.stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		result, err = stmt.stmt.Exec(toValues(args))
+	}
+	stmt.driver.log(ctx, stmt.query, args, result, err, time.Since(start))
+	return result, err
+}
+
+func (stmt *loggingStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if queryer, ok := stmt.stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = stmt.stmt.Query(toValues(args))
+	}
+	stmt.driver.log(ctx, stmt.query, args, nil, err, time.Since(start))
+	return rows, err
+}
+
+func (stmt *loggingStmt) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := stmt.stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+func toNamedValues(values []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(values))
+	for i, value := range values {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: value}
+	}
+	return named
+}
+
+func toValues(named []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(named))
+	for i, value := range named {
+		values[i] = value.Value
+	}
+	return values
+}
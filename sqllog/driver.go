@@ -0,0 +1,171 @@
+// The sqllog package provides a database/sql driver wrapper to log queries for the nslog package,
+// and a handler to insert records into a SQLite database.
+package sqllog
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const DEFAULT_MESSAGE = "sql query"
+
+// An option to customize logging of queries.
+type Options struct {
+	Level         slog.Level                      // Set level of queries. Failed queries are logged at Error level. (default: slog.LevelInfo)
+	SlowThreshold time.Duration                   // Log queries at Warn level if they take longer than it. Disabled if it is 0. (default: 0)
+	LogArgs       bool                            // Log args of queries if it is true. (default: false)
+	Redact        func(arg driver.NamedValue) any // Set function to replace args before logging, such as to hide passwords. (default: nil)
+	Message       string                          // Set message of query log. (default: "sql query")
+}
+
+func newOptions(options *Options) Options {
+	// set default parameters
+	if options == nil {
+		options = &Options{}
+	}
+	opts := *options
+	if opts.Message == "" {
+		opts.Message = DEFAULT_MESSAGE
+	}
+	return opts
+}
+
+// Create a driver to log queries of connections opened by the driver, which is registered by [sql.Register].
+func WrapDriver(d driver.Driver, logger *slog.Logger, options *Options) driver.Driver {
+	return &loggingDriver{driver: d, logger: logger, options: newOptions(options)}
+}
+
+// Create a connector to log queries of connections opened by the connector.
+func WrapConnector(connector driver.Connector, logger *slog.Logger, options *Options) driver.Connector {
+	opts := newOptions(options)
+	return &loggingConnector{
+		connector: connector,
+		driver:    &loggingDriver{driver: connector.Driver(), logger: logger, options: opts},
+	}
+}
+
+// Open a database to log queries by the connector, same as [sql.OpenDB].
+func OpenDB(connector driver.Connector, logger *slog.Logger, options *Options) *sql.DB {
+	return sql.OpenDB(WrapConnector(connector, logger, options))
+}
+
+type loggingDriver struct {
+	driver  driver.Driver
+	logger  *slog.Logger
+	options Options
+}
+
+func (d *loggingDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &loggingConn{conn: conn, driver: d}, nil
+}
+
+func (d *loggingDriver) OpenConnector(name string) (driver.Connector, error) {
+	if driverContext, ok := d.driver.(driver.DriverContext); ok {
+		connector, err := driverContext.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return &loggingConnector{connector: connector, driver: d}, nil
+	}
+	return &loggingConnector{connector: &dsnConnector{name: name, driver: d.driver}, driver: d}, nil
+}
+
+// A connector for drivers which do not implement [driver.DriverContext].
+type dsnConnector struct {
+	name   string
+	driver driver.Driver
+}
+
+func (connector *dsnConnector) Connect(_ context.Context) (driver.Conn, error) {
+	return connector.driver.Open(connector.name)
+}
+
+func (connector *dsnConnector) Driver() driver.Driver {
+	return connector.driver
+}
+
+type loggingConnector struct {
+	connector driver.Connector
+	driver    *loggingDriver
+}
+
+func (connector *loggingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := connector.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &loggingConn{conn: conn, driver: connector.driver}, nil
+}
+
+func (connector *loggingConnector) Driver() driver.Driver {
+	return connector.driver
+}
+
+// Log the query. Queries skipped by [driver.ErrSkip] are not logged since they are executed again.
+func (d *loggingDriver) log(ctx context.Context, query string, args []driver.NamedValue, result driver.Result, err error, duration time.Duration) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+
+	slow := d.options.SlowThreshold > 0 && duration > d.options.SlowThreshold
+	level := d.options.Level
+	if err != nil {
+		level = slog.LevelError
+	} else if slow && level < slog.LevelWarn {
+		level = slog.LevelWarn
+	}
+	if !d.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{slog.String("query", query)}
+	if d.options.LogArgs && len(args) > 0 {
+		values := make([]any, len(args))
+		for i, arg := range args {
+			values[i] = arg.Value
+			if d.options.Redact != nil {
+				values[i] = d.options.Redact(arg)
+			}
+		}
+		attrs = append(attrs, slog.Any("args", values))
+	}
+	if result != nil && err == nil {
+		if rows, rowsErr := result.RowsAffected(); rowsErr == nil {
+			attrs = append(attrs, slog.Int64("rows_affected", rows))
+		}
+	}
+	attrs = append(attrs, slog.Duration("duration", duration))
+	if slow {
+		attrs = append(attrs, slog.Bool("slow", true))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("err", err))
+	}
+	record := slog.NewRecord(time.Now(), level, d.options.Message, callerPC())
+	record.AddAttrs(attrs...)
+	_ = d.logger.Handler().Handle(ctx, record)
+}
+
+// Get pc of the caller of database/sql, which is the source of the query log.
+func callerPC() uintptr {
+	pcs := make([]uintptr, 64)
+	pcs = pcs[:runtime.Callers(3, pcs)]
+	for _, pc := range pcs {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		internal := strings.HasPrefix(frame.Function, "github.com/mikiepure/nslog/sqllog.") && !strings.HasSuffix(frame.File, "_test.go")
+		if !internal && !strings.HasPrefix(frame.Function, "database/sql.") {
+			return pc
+		}
+	}
+	return 0
+}
@@ -0,0 +1,144 @@
+package sqllog
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikiepure/nslog"
+	"github.com/stretchr/testify/assert"
+)
+
+// A driver which returns one row for queries, and fails for queries with "fail".
+type fakeDriver struct {
+	delay time.Duration
+}
+
+func (d *fakeDriver) Open(_ string) (driver.Conn, error) {
+	return &fakeConn{delay: d.delay}, nil
+}
+
+type fakeConn struct {
+	delay time.Duration
+}
+
+func (conn *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: conn, query: query}, nil
+}
+
+func (conn *fakeConn) Close() error {
+	return nil
+}
+
+func (conn *fakeConn) Begin() (driver.Tx, error) {
+	return &fakeTx{}, nil
+}
+
+func (conn *fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	time.Sleep(conn.delay)
+	if strings.Contains(query, "fail") {
+		return nil, errors.New("syntax error")
+	}
+	return driver.RowsAffected(2), nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (stmt *fakeStmt) Close() error {
+	return nil
+}
+
+func (stmt *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (stmt *fakeStmt) Exec(_ []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (stmt *fakeStmt) Query(_ []driver.Value) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+type fakeTx struct{}
+
+func (tx *fakeTx) Commit() error   { return nil }
+func (tx *fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	done bool
+}
+
+func (rows *fakeRows) Columns() []string {
+	return []string{"name"}
+}
+
+func (rows *fakeRows) Close() error {
+	return nil
+}
+
+func (rows *fakeRows) Next(dest []driver.Value) error {
+	if rows.done {
+		return io.EOF
+	}
+	rows.done = true
+	dest[0] = "alice"
+	return nil
+}
+
+func TestWrapDriver(t *testing.T) {
+	buf := new(bytes.Buffer)
+	sql.Register("sqllog-test", WrapDriver(&fakeDriver{}, nslog.NewLogger(buf, nil), &Options{LogArgs: true}))
+	db, err := sql.Open("sqllog-test", "")
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// exec by ExecerContext of the connection
+	result, err := db.Exec("UPDATE users SET name = ? WHERE id = ?", "bob", 1)
+	assert.NoError(t, err)
+	rows, _ := result.RowsAffected()
+	assert.Equal(t, int64(2), rows)
+	assert.Regexp(t, "INFO\\. sql query query=UPDATE users SET name = \\? WHERE id = \\? args=\\[bob 1\\] rows_affected=2 duration=.+\n$", buf.String())
+
+	// query by prepared statement since the connection does not implement QueryerContext
+	buf.Reset()
+	var name string
+	err = db.QueryRow("SELECT name FROM users WHERE id = ?", 1).Scan(&name)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", name)
+	assert.Regexp(t, "INFO\\. sql query query=SELECT name FROM users WHERE id = \\? args=\\[1\\] duration=.+\n$", buf.String())
+
+	// error
+	buf.Reset()
+	_, err = db.Exec("fail")
+	assert.Error(t, err)
+	assert.Regexp(t, "ERROR sql query query=fail duration=.+ err=syntax error \\(driver_test\\.go:[0-9]+\\)\n$", buf.String())
+}
+
+func TestOpenDBRedactAndSlow(t *testing.T) {
+	buf := new(bytes.Buffer)
+	db := OpenDB(&dsnConnector{driver: &fakeDriver{delay: 5 * time.Millisecond}}, nslog.NewLogger(buf, nil), &Options{
+		SlowThreshold: time.Millisecond,
+		LogArgs:       true,
+		Redact: func(arg driver.NamedValue) any {
+			if arg.Ordinal == 1 {
+				return "***"
+			}
+			return arg.Value
+		},
+	})
+	defer db.Close()
+
+	_, err := db.ExecContext(context.Background(), "UPDATE users SET password = ? WHERE id = ?", "secret", 1)
+	assert.NoError(t, err)
+	assert.Regexp(t, "WARN\\. sql query query=UPDATE users SET password = \\? WHERE id = \\? args=\\[\\*\\*\\* 1\\] rows_affected=2 duration=.+ slow=true \\(driver_test\\.go:[0-9]+\\)\n$", buf.String())
+}
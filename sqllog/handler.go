@@ -0,0 +1,326 @@
+package sqllog
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"math"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const DEFAULT_TABLE = "logs"
+const DEFAULT_BATCH_SIZE = 100
+const DEFAULT_FLUSH_INTERVAL = time.Second
+const DEFAULT_QUEUE_SIZE = 10000
+const DEFAULT_PRUNE_INTERVAL = time.Minute
+
+// Layout of time column, which has fixed width so that the text is sorted in order of time.
+const TIME_LAYOUT = "2006-01-02T15:04:05.000000000Z"
+
+var tableRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// An option to customize [sqllog.Handler].
+type HandlerOptions struct {
+	Level         slog.Leveler  // Set minimum level to insert records. (default: slog.LevelInfo)
+	Table         string        // Set name of the table, which is created if it does not exist. (default: "logs")
+	BatchSize     int           // Set maximum number of records inserted in a transaction. (default: 100)
+	FlushInterval time.Duration // Set interval to insert queued records. (default: 1 second)
+	QueueSize     int           // Set size of queue of records waiting to be inserted. Records are dropped if the queue is full. (default: 10000)
+	MaxAge        time.Duration // Set maximum age of records, and older records are deleted. Disabled if it is 0. (default: 0)
+	MaxRows       int           // Set maximum number of rows, and older rows are deleted. Disabled if it is 0. (default: 0)
+	PruneInterval time.Duration // Set interval to delete records by MaxAge and MaxRows. (default: 1 minute)
+	OnError       func(error)   // Set function called when inserting or deleting records is failed. (default: nil)
+}
+
+// A handler to insert records into a SQLite database such as:
+//
+//	CREATE TABLE logs (id INTEGER PRIMARY KEY AUTOINCREMENT, time TEXT, level TEXT, "group" TEXT, message TEXT, attrs TEXT, source TEXT)
+//
+// so operators can run SQL over recent logs on a device. Attributes are stored as a JSON object, and groups of the handler are
+// stored as a dot-separated name. Records are inserted in batches by a goroutine, and old records are deleted by MaxAge and MaxRows.
+// The database should not be wrapped by [sqllog.WrapDriver] with the same logger, or inserts are logged recursively.
+type Handler struct {
+	attrs  slog.Handler // handler to encode attributes as JSON
+	groups []string
+	writer *handlerWriter
+}
+
+type handlerRow struct {
+	time    string
+	level   string
+	group   string
+	message string
+	attrs   string
+	source  string
+}
+
+type handlerWriter struct {
+	db      *sql.DB
+	options HandlerOptions
+	insert  string
+	queue   chan handlerRow
+	done    chan struct{}
+	once    sync.Once
+	stopped atomic.Bool
+	dropped atomic.Uint64
+	closing sync.RWMutex // guard of closed, which is locked for reading to send and for writing to close the queue
+	closed  bool
+
+	mutex  sync.Mutex // mutex of buffer to encode attributes
+	buffer bytes.Buffer
+}
+
+// Create a new [sqllog.Handler] object, which creates the table and starts goroutine to insert records.
+// Call [Handler.Close] to insert queued records and stop the goroutine. The database is not closed.
+func NewHandler(db *sql.DB, options *HandlerOptions) (*Handler, error) {
+	// set default parameters
+	if options == nil {
+		options = &HandlerOptions{}
+	}
+	if options.Level == nil {
+		options.Level = slog.LevelInfo
+	}
+	if options.Table == "" {
+		options.Table = DEFAULT_TABLE
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = DEFAULT_BATCH_SIZE
+	}
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = DEFAULT_FLUSH_INTERVAL
+	}
+	if options.QueueSize <= 0 {
+		options.QueueSize = DEFAULT_QUEUE_SIZE
+	}
+	if options.PruneInterval <= 0 {
+		options.PruneInterval = DEFAULT_PRUNE_INTERVAL
+	}
+
+	if !tableRegexp.MatchString(options.Table) {
+		return nil, fmt.Errorf("nslog: invalid table name %q", options.Table)
+	}
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS ` + options.Table + ` (id INTEGER PRIMARY KEY AUTOINCREMENT, time TEXT NOT NULL, level TEXT NOT NULL, "group" TEXT NOT NULL, message TEXT NOT NULL, attrs TEXT NOT NULL, source TEXT NOT NULL)`)
+	if err != nil {
+		return nil, fmt.Errorf("nslog: failed to create table: %w", err)
+	}
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS ` + options.Table + `_time ON ` + options.Table + ` (time)`)
+	if err != nil {
+		return nil, fmt.Errorf("nslog: failed to create index: %w", err)
+	}
+
+	writer := &handlerWriter{
+		db:      db,
+		options: *options,
+		insert:  `INSERT INTO ` + options.Table + ` (time, level, "group", message, attrs, source) VALUES (?, ?, ?, ?, ?, ?)`,
+		queue:   make(chan handlerRow, options.QueueSize),
+		done:    make(chan struct{}),
+	}
+	go writer.run()
+
+	return &Handler{
+		attrs: slog.NewJSONHandler(&writer.buffer, &slog.HandlerOptions{
+			Level: slog.Level(math.MinInt),
+			ReplaceAttr: func(groups []string, attribute slog.Attr) slog.Attr {
+				if len(groups) == 0 && (attribute.Key == slog.TimeKey || attribute.Key == slog.LevelKey || attribute.Key == slog.MessageKey) {
+					return slog.Attr{}
+				}
+				return attribute
+			},
+		}),
+		writer: writer,
+	}, nil
+}
+
+func (handler *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= handler.writer.options.Level.Level()
+}
+
+func (handler *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{
+		attrs:  handler.attrs.WithAttrs(attrs),
+		groups: handler.groups,
+		writer: handler.writer,
+	}
+}
+
+func (handler *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return handler
+	}
+	return &Handler{
+		attrs:  handler.attrs.WithGroup(name),
+		groups: append(handler.groups[:len(handler.groups):len(handler.groups)], name),
+		writer: handler.writer,
+	}
+}
+
+func (handler *Handler) Handle(ctx context.Context, record slog.Record) error {
+	if handler.writer.stopped.Load() {
+		return nil
+	}
+
+	row := handlerRow{
+		time:    record.Time.UTC().Format(TIME_LAYOUT),
+		level:   record.Level.String(),
+		group:   strings.Join(handler.groups, "."),
+		message: record.Message,
+	}
+	if record.Time.IsZero() {
+		row.time = time.Now().UTC().Format(TIME_LAYOUT)
+	}
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		row.source = filepath.Base(frame.File) + ":" + strconv.Itoa(frame.Line)
+	}
+
+	// encode attributes without time, level, and message
+	writer := handler.writer
+	writer.mutex.Lock()
+	writer.buffer.Reset()
+	attrsRecord := slog.NewRecord(time.Time{}, record.Level, "", 0)
+	record.Attrs(func(attribute slog.Attr) bool {
+		attrsRecord.AddAttrs(attribute)
+		return true
+	})
+	err := handler.attrs.Handle(ctx, attrsRecord)
+	row.attrs = strings.TrimSuffix(writer.buffer.String(), "\n")
+	writer.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	writer.closing.RLock()
+	defer writer.closing.RUnlock()
+	if writer.closed {
+		writer.dropped.Add(1)
+		return nil
+	}
+	select {
+	case writer.queue <- row:
+	default:
+		writer.dropped.Add(1)
+	}
+	return nil
+}
+
+// Insert queued records and stop goroutine.
+func (handler *Handler) Close() error {
+	handler.writer.stopped.Store(true)
+	handler.writer.once.Do(func() {
+		handler.writer.closing.Lock()
+		handler.writer.closed = true
+		close(handler.writer.queue)
+		handler.writer.closing.Unlock()
+	})
+	<-handler.writer.done
+	return nil
+}
+
+// Get number of records waiting in the queue.
+func (handler *Handler) QueueDepth() int {
+	return len(handler.writer.queue)
+}
+
+// Get number of records dropped because the queue is full or inserting is failed, which is shared with derived handlers.
+func (handler *Handler) Dropped() uint64 {
+	return handler.writer.dropped.Load()
+}
+
+func (writer *handlerWriter) run() {
+	defer close(writer.done)
+	flushTicker := time.NewTicker(writer.options.FlushInterval)
+	defer flushTicker.Stop()
+	pruneTicker := time.NewTicker(writer.options.PruneInterval)
+	defer pruneTicker.Stop()
+
+	var rows []handlerRow
+	for {
+		select {
+		case row, ok := <-writer.queue:
+			if !ok {
+				writer.insertRows(rows)
+				writer.prune()
+				return
+			}
+			rows = append(rows, row)
+			if len(rows) >= writer.options.BatchSize {
+				writer.insertRows(rows)
+				rows = nil
+			}
+		case <-flushTicker.C:
+			writer.insertRows(rows)
+			rows = nil
+		case <-pruneTicker.C:
+			writer.prune()
+		}
+	}
+}
+
+// Insert the rows in a transaction.
+func (writer *handlerWriter) insertRows(rows []handlerRow) {
+	if len(rows) == 0 {
+		return
+	}
+	err := writer.transact(func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(writer.insert)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for _, row := range rows {
+			_, err = stmt.Exec(row.time, row.level, row.group, row.message, row.attrs, row.source)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		writer.dropped.Add(uint64(len(rows)))
+		writer.onError(fmt.Errorf("nslog: failed to insert records: %w", err))
+	}
+}
+
+// Delete old records by MaxAge and MaxRows.
+func (writer *handlerWriter) prune() {
+	if writer.options.MaxAge > 0 {
+		_, err := writer.db.Exec(`DELETE FROM `+writer.options.Table+` WHERE time < ?`, time.Now().Add(-writer.options.MaxAge).UTC().Format(TIME_LAYOUT))
+		if err != nil {
+			writer.onError(fmt.Errorf("nslog: failed to delete old records: %w", err))
+		}
+	}
+	if writer.options.MaxRows > 0 {
+		_, err := writer.db.Exec(`DELETE FROM `+writer.options.Table+` WHERE id <= (SELECT MAX(id) FROM `+writer.options.Table+`) - ?`, writer.options.MaxRows)
+		if err != nil {
+			writer.onError(fmt.Errorf("nslog: failed to delete old records: %w", err))
+		}
+	}
+}
+
+func (writer *handlerWriter) transact(f func(tx *sql.Tx) error) error {
+	tx, err := writer.db.Begin()
+	if err != nil {
+		return err
+	}
+	err = f(tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (writer *handlerWriter) onError(err error) {
+	if writer.options.OnError != nil {
+		writer.options.OnError(err)
+	}
+}
@@ -0,0 +1,143 @@
+package sqllog
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A connector which records executed statements and args, and fails for inserts if fail is true.
+type recordConnector struct {
+	mutex      sync.Mutex
+	statements []string
+	rows       [][]driver.Value
+	fail       bool
+}
+
+func (connector *recordConnector) Connect(_ context.Context) (driver.Conn, error) {
+	return &recordConn{connector: connector}, nil
+}
+
+func (connector *recordConnector) Driver() driver.Driver {
+	return nil
+}
+
+func (connector *recordConnector) executed() ([]string, [][]driver.Value) {
+	connector.mutex.Lock()
+	defer connector.mutex.Unlock()
+	return append([]string{}, connector.statements...), append([][]driver.Value{}, connector.rows...)
+}
+
+type recordConn struct {
+	connector *recordConnector
+}
+
+func (conn *recordConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordStmt{conn: conn, query: query}, nil
+}
+
+func (conn *recordConn) Close() error {
+	return nil
+}
+
+func (conn *recordConn) Begin() (driver.Tx, error) {
+	return &fakeTx{}, nil
+}
+
+type recordStmt struct {
+	conn  *recordConn
+	query string
+}
+
+func (stmt *recordStmt) Close() error {
+	return nil
+}
+
+func (stmt *recordStmt) NumInput() int {
+	return -1
+}
+
+func (stmt *recordStmt) Exec(args []driver.Value) (driver.Result, error) {
+	connector := stmt.conn.connector
+	connector.mutex.Lock()
+	defer connector.mutex.Unlock()
+	if connector.fail && strings.HasPrefix(stmt.query, "INSERT") {
+		return nil, errors.New("disk full")
+	}
+	connector.statements = append(connector.statements, stmt.query)
+	if strings.HasPrefix(stmt.query, "INSERT") {
+		connector.rows = append(connector.rows, args)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (stmt *recordStmt) Query(_ []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func TestHandler(t *testing.T) {
+	connector := &recordConnector{}
+	db := sql.OpenDB(connector)
+	handler, err := NewHandler(db, &HandlerOptions{MaxRows: 1000, MaxAge: time.Hour})
+	assert.NoError(t, err)
+	log := slog.New(handler)
+	log.Debug("debug message")
+	log.With("id", 1).WithGroup("Main").Info("info message", "key1", "val1")
+	log.Warn("warn message")
+	assert.NoError(t, handler.Close())
+	log.Error("error message")
+
+	statements, rows := connector.executed()
+	assert.Equal(t, `CREATE TABLE IF NOT EXISTS logs (id INTEGER PRIMARY KEY AUTOINCREMENT, time TEXT NOT NULL, level TEXT NOT NULL, "group" TEXT NOT NULL, message TEXT NOT NULL, attrs TEXT NOT NULL, source TEXT NOT NULL)`, statements[0])
+	assert.Equal(t, `CREATE INDEX IF NOT EXISTS logs_time ON logs (time)`, statements[1])
+	assert.Equal(t, `DELETE FROM logs WHERE time < ?`, statements[len(statements)-2])
+	assert.Equal(t, `DELETE FROM logs WHERE id <= (SELECT MAX(id) FROM logs) - ?`, statements[len(statements)-1])
+	assert.Len(t, rows, 2)
+	assert.Regexp(t, "^[0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}:[0-9]{2}\\.[0-9]{9}Z$", rows[0][0])
+	assert.Equal(t, []driver.Value{"INFO", "Main", "info message", `{"id":1,"Main":{"key1":"val1"}}`}, rows[0][1:5])
+	assert.Regexp(t, "^handler_test\\.go:[0-9]+$", rows[0][5])
+	assert.Equal(t, []driver.Value{"WARN", "", "warn message", `{}`}, rows[1][1:5])
+	assert.Equal(t, uint64(0), handler.Dropped())
+}
+
+func TestHandlerClosed(t *testing.T) {
+	handler, err := NewHandler(sql.OpenDB(&recordConnector{}), nil)
+	assert.NoError(t, err)
+	log := slog.New(handler)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Info("log message")
+		}()
+	}
+	assert.NoError(t, handler.Close())
+	wg.Wait()
+	assert.NotPanics(t, func() { log.Info("log message after close") })
+}
+
+func TestHandlerError(t *testing.T) {
+	connector := &recordConnector{fail: true}
+	var errs []error
+	handler, err := NewHandler(sql.OpenDB(connector), &HandlerOptions{Table: "app_logs", OnError: func(err error) {
+		errs = append(errs, err)
+	}})
+	assert.NoError(t, err)
+	slog.New(handler).Info("log message")
+	assert.NoError(t, handler.Close())
+	assert.Equal(t, uint64(1), handler.Dropped())
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "nslog: failed to insert records: disk full")
+
+	_, err = NewHandler(sql.OpenDB(connector), &HandlerOptions{Table: "logs; DROP TABLE logs"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,119 @@
+package nslog
+
+import (
+	"expvar"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// Upper bounds of buckets of write latency histogram.
+var writeLatencyBounds = []time.Duration{
+	10 * time.Microsecond,
+	50 * time.Microsecond,
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+}
+
+// Counters of records by [nslog.LogHandler] with CollectStats option.
+// Levels between the standard levels are counted as the lower one, such as "DEBUG+2" as Debug.
+// Records disabled by level are not counted as dropped, since Enabled may be called several times for a record.
+type Stats struct {
+	Error       uint64 `json:"error"`        // number of Error records written
+	Warn        uint64 `json:"warn"`         // number of Warn records written
+	Info        uint64 `json:"info"`         // number of Info records written
+	Debug       uint64 `json:"debug"`        // number of Debug or lower records written
+	Emitted     uint64 `json:"emitted"`      // number of records written
+	Dropped     uint64 `json:"dropped"`      // number of records dropped by hooks in Handle
+	WriteErrors uint64 `json:"write_errors"` // number of records failed to write
+	Bytes       uint64 `json:"bytes"`        // number of bytes written
+
+	WriteLatency LatencyHistogram `json:"write_latency"` // histogram of latency to write records
+}
+
+// A histogram of latency.
+type LatencyHistogram struct {
+	Bounds []time.Duration `json:"bounds"` // upper bounds of buckets
+	Counts []uint64        `json:"counts"` // counts per bucket, where the last one is for latency over all bounds
+	Sum    time.Duration   `json:"sum"`    // sum of latency
+}
+
+type handlerStats struct {
+	levels      [4]atomic.Uint64 // indexed by levelIndex
+	dropped     atomic.Uint64
+	writeErrors atomic.Uint64
+	bytes       atomic.Uint64
+	latency     [12]atomic.Uint64 // indexed by bucket of writeLatencyBounds, and the last one is for latency over all bounds
+	latencySum  atomic.Int64
+}
+
+// Count the record written with the latency.
+func (stats *handlerStats) written(level slog.Level, bytes int, latency time.Duration) {
+	stats.levels[levelIndex(level)].Add(1)
+	stats.bytes.Add(uint64(bytes))
+	bucket := len(writeLatencyBounds)
+	for i, bound := range writeLatencyBounds {
+		if latency <= bound {
+			bucket = i
+			break
+		}
+	}
+	stats.latency[bucket].Add(1)
+	stats.latencySum.Add(int64(latency))
+}
+
+// Get index of counters of the level in order of Debug, Info, Warn, and Error.
+func levelIndex(level slog.Level) int {
+	switch {
+	case level >= slog.LevelError:
+		return 3
+	case level >= slog.LevelWarn:
+		return 2
+	case level >= slog.LevelInfo:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (stats *handlerStats) snapshot() Stats {
+	snapshot := Stats{
+		Debug:       stats.levels[0].Load(),
+		Info:        stats.levels[1].Load(),
+		Warn:        stats.levels[2].Load(),
+		Error:       stats.levels[3].Load(),
+		Dropped:     stats.dropped.Load(),
+		WriteErrors: stats.writeErrors.Load(),
+		Bytes:       stats.bytes.Load(),
+		WriteLatency: LatencyHistogram{
+			Bounds: writeLatencyBounds,
+			Counts: make([]uint64, len(stats.latency)),
+			Sum:    time.Duration(stats.latencySum.Load()),
+		},
+	}
+	for i := range stats.latency {
+		snapshot.WriteLatency.Counts[i] = stats.latency[i].Load()
+	}
+	snapshot.Emitted = snapshot.Debug + snapshot.Info + snapshot.Warn + snapshot.Error
+	return snapshot
+}
+
+// Get counters of records, which are shared with derived handlers. All counters are zero without CollectStats option.
+func (handler *LogHandler) Stats() Stats {
+	return handler.state.stats.snapshot()
+}
+
+// Publish counters of records to [expvar] with the name, so dashboards can alert on error rate from the logging layer.
+// It panics if the name is already published, same as [expvar.Publish].
+func (handler *LogHandler) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return handler.Stats()
+	}))
+}
@@ -0,0 +1,64 @@
+package nslog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type errorWriter struct{}
+
+func (errorWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write error")
+}
+
+func TestStats(t *testing.T) {
+	dropHook := Hook{BeforeFormat: func(_ context.Context, record *slog.Record) bool {
+		return record.Message != "drop message"
+	}}
+	handler := NewLogHandler(io.Discard, &LogHandlerOptions{CollectStats: true, Hooks: []Hook{dropHook}})
+	log := slog.New(handler).WithGroup("Main")
+	log.Error("log message")
+	log.Warn("log message")
+	log.Info("log message")
+	log.Info("log message")
+	log.Log(nil, slog.LevelInfo+2, "log message")
+	log.Debug("log message")
+	log.Info("drop message")
+	// probes of disabled level are not counted as dropped
+	handler.Enabled(context.Background(), slog.LevelDebug)
+	stats := handler.Stats()
+	assert.Equal(t, Stats{Error: 1, Warn: 1, Info: 3, Emitted: 5, Dropped: 1}, Stats{
+		Error: stats.Error, Warn: stats.Warn, Info: stats.Info, Debug: stats.Debug, Emitted: stats.Emitted, Dropped: stats.Dropped,
+	})
+	assert.Greater(t, stats.Bytes, uint64(5*len("2006/01/02 15:04:05 INFO. Main: log message\n")))
+	assert.Len(t, stats.WriteLatency.Counts, len(stats.WriteLatency.Bounds)+1)
+	var count uint64
+	for _, bucket := range stats.WriteLatency.Counts {
+		count += bucket
+	}
+	assert.Equal(t, uint64(5), count)
+
+	slog.New(handler.WithWriter(errorWriter{})).Info("log message")
+	assert.Equal(t, uint64(1), handler.Stats().WriteErrors)
+
+	handler = NewLogHandler(io.Discard, nil)
+	slog.New(handler).Info("log message")
+	assert.Equal(t, uint64(0), handler.Stats().Emitted)
+}
+
+func TestPublishExpvar(t *testing.T) {
+	handler := NewLogHandler(io.Discard, &LogHandlerOptions{CollectStats: true})
+	handler.PublishExpvar("nslog_test")
+	slog.New(handler).Error("log message")
+
+	var stats Stats
+	assert.NoError(t, json.Unmarshal([]byte(expvar.Get("nslog_test").String()), &stats))
+	assert.Equal(t, uint64(1), stats.Error)
+}
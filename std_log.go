@@ -0,0 +1,79 @@
+package nslog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type stdLogWriter struct {
+	logger *slog.Logger
+	level  slog.Level
+	mutex  sync.Mutex
+	buffer []byte
+}
+
+// Create a new [io.Writer] which splits written data into lines and logs each line at the level.
+// An incomplete line is kept until the rest of the line is written.
+func NewStdLogWriter(logger *slog.Logger, level slog.Level) io.Writer {
+	return &stdLogWriter{logger: logger, level: level}
+}
+
+func (writer *stdLogWriter) Write(p []byte) (int, error) {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+
+	writer.buffer = append(writer.buffer, p...)
+	for {
+		index := bytes.IndexByte(writer.buffer, '\n')
+		if index < 0 {
+			break
+		}
+		line := bytes.TrimSuffix(writer.buffer[:index], []byte("\r"))
+		writer.buffer = writer.buffer[index+1:]
+		err := writer.log(string(line))
+		if err != nil {
+			return len(p), err
+		}
+	}
+	if len(writer.buffer) == 0 {
+		writer.buffer = nil
+	}
+	return len(p), nil
+}
+
+func (writer *stdLogWriter) log(message string) error {
+	ctx := context.Background()
+	if !writer.logger.Enabled(ctx, writer.level) {
+		return nil
+	}
+	// source of the line is unknown
+	record := slog.NewRecord(time.Now(), writer.level, message, 0)
+	return writer.logger.Handler().Handle(ctx, record)
+}
+
+// Create a new [log.Logger] which logs each line at the level, such as for [http.Server.ErrorLog].
+func NewStdLogger(logger *slog.Logger, level slog.Level) *log.Logger {
+	return log.New(NewStdLogWriter(logger, level), "", 0)
+}
+
+// Redirect output of the standard library "log" package to the logger at the level.
+// The flags and prefix of the standard logger are cleared because time is added by the logger.
+// It returns a function to restore the original output, flags, and prefix.
+func RedirectStdLog(logger *slog.Logger, level slog.Level) func() {
+	writer := log.Writer()
+	flags := log.Flags()
+	prefix := log.Prefix()
+	log.SetOutput(NewStdLogWriter(logger, level))
+	log.SetFlags(0)
+	log.SetPrefix("")
+	return func() {
+		log.SetOutput(writer)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+	}
+}
@@ -0,0 +1,41 @@
+package nslog
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStdLogWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	writer := NewStdLogWriter(NewLogger(buf, nil), slog.LevelWarn)
+	writer.Write([]byte("line1\nli"))
+	writer.Write([]byte("ne2\r\n"))
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" WARN\\. line1\n"+DEFAULT_TIME_REGEXP+" WARN\\. line2\n$", buf.String())
+}
+
+func TestStdLogWriterLevel(t *testing.T) {
+	buf := new(bytes.Buffer)
+	writer := NewStdLogWriter(NewLogger(buf, nil), slog.LevelDebug)
+	writer.Write([]byte("line1\n"))
+	assert.Empty(t, buf.String())
+}
+
+func TestNewStdLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := NewStdLogger(NewLogger(buf, nil).WithGroup("http"), slog.LevelError)
+	logger.Printf("http: TLS handshake error from %s", "192.0.2.1")
+	assert.Contains(t, buf.String(), "ERROR http: http: TLS handshake error from 192.0.2.1\n")
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	buf := new(bytes.Buffer)
+	restore := RedirectStdLog(NewLogger(buf, nil), slog.LevelInfo)
+	log.Print("message")
+	restore()
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. message\n$", buf.String())
+	assert.Equal(t, log.LstdFlags, log.Flags())
+}
@@ -0,0 +1,57 @@
+package nslog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// A stopwatch to log elapsed time of an operation, which is created by [nslog.Start].
+type Stopwatch struct {
+	logger    *slog.Logger
+	operation string
+	level     slog.Level
+	start     time.Time
+}
+
+// Create a new [nslog.Stopwatch] object which starts now. Call [Stopwatch.Done] to log the elapsed time:
+//
+//	defer nslog.Start(logger, "load config").Done("path", path)
+func Start(logger *slog.Logger, operation string) *Stopwatch {
+	return &Stopwatch{
+		logger:    logger,
+		operation: operation,
+		level:     slog.LevelInfo,
+		start:     time.Now(),
+	}
+}
+
+// Set level to log by [Stopwatch.Done]. (default: slog.LevelInfo)
+func (stopwatch *Stopwatch) Level(level slog.Level) *Stopwatch {
+	stopwatch.level = level
+	return stopwatch
+}
+
+// Get the elapsed time since the start.
+func (stopwatch *Stopwatch) Elapsed() time.Duration {
+	return time.Since(stopwatch.start)
+}
+
+// Log the operation as message with the arguments and the elapsed time as "elapsed" attribute, and return the elapsed time.
+func (stopwatch *Stopwatch) Done(args ...any) time.Duration {
+	elapsed := stopwatch.Elapsed()
+	ctx := context.Background()
+	if !stopwatch.logger.Enabled(ctx, stopwatch.level) {
+		return elapsed
+	}
+
+	// skip [runtime.Callers] and this function
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:])
+	record := slog.NewRecord(time.Now(), stopwatch.level, stopwatch.operation, pcs[0])
+	record.Add(args...)
+	record.AddAttrs(slog.Duration("elapsed", elapsed))
+	_ = stopwatch.logger.Handler().Handle(ctx, record)
+	return elapsed
+}
@@ -0,0 +1,33 @@
+package nslog
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStopwatch(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, nil)
+	stopwatch := Start(log, "load config")
+	time.Sleep(2 * time.Millisecond)
+	elapsed := stopwatch.Done("path", "config.json")
+	assert.GreaterOrEqual(t, elapsed, 2*time.Millisecond)
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. load config path=config\\.json elapsed=[0-9.]+ms\n$", buf.String())
+}
+
+func TestStopwatchLevel(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, nil)
+	func() {
+		defer Start(log, "slow operation").Level(slog.LevelWarn).Done()
+	}()
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" WARN\\. slow operation elapsed=.+ \\(stopwatch_test\\.go:[0-9]+\\)\n$", buf.String())
+
+	buf.Reset()
+	Start(log, "debug operation").Level(slog.LevelDebug).Done()
+	assert.Equal(t, "", buf.String())
+}
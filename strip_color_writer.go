@@ -0,0 +1,39 @@
+package nslog
+
+import (
+	"io"
+)
+
+// A writer to strip ANSI escape sequences (such as color) from written data,
+// so files stay clean when a colored handler fans out to both console and file.
+type StripColorWriter struct {
+	writer io.Writer
+}
+
+// Create a new [nslog.StripColorWriter] object, which writes stripped data to the writer.
+func NewStripColorWriter(writer io.Writer) *StripColorWriter {
+	return &StripColorWriter{writer: writer}
+}
+
+func (writer *StripColorWriter) Write(p []byte) (int, error) {
+	_, err := writer.writer.Write(StripColor(p))
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Strip ANSI escape sequences, which are CSI sequences such as color ("\x1b[31m")
+// and OSC sequences such as hyperlink ("\x1b]8;;url\x1b\\"), parsed same as the width on the terminal.
+func StripColor(p []byte) []byte {
+	s := string(p)
+	stripped := make([]byte, 0, len(p))
+	for i := 0; i < len(s); i++ {
+		if n := escapeLength(s[i:]); n > 0 {
+			i += n - 1
+			continue
+		}
+		stripped = append(stripped, s[i])
+	}
+	return stripped
+}
@@ -0,0 +1,24 @@
+package nslog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripColor(t *testing.T) {
+	assert.Equal(t, "INFO. message", string(StripColor([]byte("\x1b[92mINFO.\x1b[0m message"))))
+	assert.Equal(t, "(main.go:1)", string(StripColor([]byte("\x1b[38;2;18;52;86m(main.go:1)\x1b[0;22;0;0;0m"))))
+	assert.Equal(t, "(main.go:1)", string(StripColor([]byte("\x1b]8;;file:///main.go\x1b\\(main.go:1)\x1b]8;;\x1b\\"))))
+	assert.Equal(t, "(main.go:1)", string(StripColor([]byte("\x1b]8;;file:///main.go\x07(main.go:1)\x1b]8;;\x07"))))
+	assert.Equal(t, "message", string(StripColor([]byte("\x1bcmessage"))))
+	assert.Equal(t, "message\x1b", string(StripColor([]byte("message\x1b"))))
+}
+
+func TestStripColorWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(NewStripColorWriter(buf), &LogHandlerOptions{ColorMode: ColorModeAlways})
+	log.Warn("log message", "key1", "val1")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" WARN\\. log message key1=val1 \\(strip_color_writer_test\\.go:\\d+\\)\n$", buf.String())
+}
@@ -0,0 +1,98 @@
+package nslog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"golang.org/x/net/websocket"
+)
+
+// An option to customize [nslog.TailServer].
+type TailServerOptions struct {
+	Level slog.Leveler // Set minimum level of records sent to clients, which can be raised by "level" query such as "?level=warn". (default: nil, which sends all kept records)
+
+	// Set origins of web pages allowed to connect such as "https://dashboard.example.com", or "*" to allow any origin.
+	// Clients without Origin header such as command-line clients and pages of the same host are always allowed,
+	// so other web pages cannot read logs by cross-site WebSocket hijacking. (default: nil)
+	AllowedOrigins []string
+}
+
+// An HTTP handler to serve recent and live log lines of [nslog.RingHandler] over WebSocket,
+// so developers can tail a running service remotely by `wscat -c ws://host/debug/tail` or a browser.
+// Each line is sent as a text message without newline.
+type TailServer struct {
+	ring    *RingHandler
+	options TailServerOptions
+}
+
+// Create a new [nslog.TailServer] object, which sends records kept by the ring handler and then new records.
+func NewTailServer(ring *RingHandler, options *TailServerOptions) *TailServer {
+	// set default parameters
+	if options == nil {
+		options = &TailServerOptions{}
+	}
+	if options.Level == nil {
+		options.Level = slog.Level(math.MinInt)
+	}
+
+	return &TailServer{
+		ring:    ring,
+		options: *options,
+	}
+}
+
+func (server *TailServer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	level := server.options.Level.Level()
+	if query := request.URL.Query().Get("level"); query != "" {
+		queryLevel, err := ParseLevel(query)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		level = max(level, queryLevel)
+	}
+
+	websocketServer := websocket.Server{
+		Handshake: func(_ *websocket.Config, request *http.Request) error {
+			return server.checkOrigin(request)
+		},
+		Handler: func(conn *websocket.Conn) {
+			ctx, cancel := context.WithCancel(request.Context())
+			defer cancel()
+
+			// detect close of the client, which sends no message
+			go func() {
+				defer cancel()
+				var message string
+				for websocket.Message.Receive(conn, &message) == nil {
+				}
+			}()
+
+			_ = server.ring.Follow(ctx, func(record slog.Record, line []byte) error {
+				if record.Level < level {
+					return nil
+				}
+				return websocket.Message.Send(conn, string(bytes.TrimRight(line, "\r\n")))
+			})
+		},
+	}
+	websocketServer.ServeHTTP(writer, request)
+}
+
+// Check Origin header of the request, which is allowed if it is absent, of the same host, or in AllowedOrigins option.
+func (server *TailServer) checkOrigin(request *http.Request) error {
+	origin := request.Header.Get("Origin")
+	if origin == "" || slices.Contains(server.options.AllowedOrigins, "*") || slices.Contains(server.options.AllowedOrigins, origin) {
+		return nil
+	}
+	if originURL, err := url.Parse(origin); err == nil && originURL.Host == request.Host {
+		return nil
+	}
+	return fmt.Errorf("nslog: origin %q is not allowed", origin)
+}
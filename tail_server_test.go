@@ -0,0 +1,80 @@
+package nslog
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+func receiveTail(t *testing.T, conn *websocket.Conn) string {
+	var message string
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	assert.NoError(t, websocket.Message.Receive(conn, &message))
+	return message
+}
+
+func TestTailServer(t *testing.T) {
+	ring := NewRingHandler(nil, 10, &LogHandlerOptions{OmitTime: true})
+	log := slog.New(ring)
+	log.Debug("message 1")
+	log.Warn("message 2")
+
+	server := httptest.NewServer(NewTailServer(ring, nil))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	conn, err := websocket.Dial(url, "", server.URL)
+	assert.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, "DEBUG message 1", receiveTail(t, conn))
+	assert.Regexp(t, "^WARN\\. message 2 \\(tail_server_test\\.go:[0-9]+\\)$", receiveTail(t, conn))
+
+	filtered, err := websocket.Dial(url+"?level=info", "", server.URL)
+	assert.NoError(t, err)
+	defer filtered.Close()
+	assert.Regexp(t, "^WARN\\. message 2", receiveTail(t, filtered))
+
+	log.Debug("message 3")
+	log.Info("message 4")
+	assert.Equal(t, "DEBUG message 3", receiveTail(t, conn))
+	assert.Equal(t, "INFO. message 4", receiveTail(t, conn))
+	assert.Equal(t, "INFO. message 4", receiveTail(t, filtered))
+}
+
+func TestTailServerInvalidLevel(t *testing.T) {
+	server := NewTailServer(NewRingHandler(nil, 10, nil), &TailServerOptions{Level: slog.LevelInfo})
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/?level=unknown", nil))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestTailServerOrigin(t *testing.T) {
+	ring := NewRingHandler(nil, 10, &LogHandlerOptions{OmitTime: true})
+	slog.New(ring).Info("log message")
+
+	server := httptest.NewServer(NewTailServer(ring, &TailServerOptions{AllowedOrigins: []string{"https://dashboard.example.com"}}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	// foreign origin
+	_, err := websocket.Dial(url, "", "https://evil.example.com")
+	assert.Error(t, err)
+
+	// same origin
+	conn, err := websocket.Dial(url, "", server.URL)
+	assert.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, "INFO. log message", receiveTail(t, conn))
+
+	// allowed origin
+	allowed, err := websocket.Dial(url, "", "https://dashboard.example.com")
+	assert.NoError(t, err)
+	defer allowed.Close()
+	assert.Equal(t, "INFO. log message", receiveTail(t, allowed))
+}
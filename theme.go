@@ -0,0 +1,259 @@
+package nslog
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+// A mode to add color.
+type ColorMode int
+
+const (
+	ColorModeDefault ColorMode = iota // Add color if AddColor option is true.
+	ColorModeAuto                     // Add color only if the writer is a terminal. NO_COLOR and CLICOLOR_FORCE environment variables are respected.
+	ColorModeAlways                   // Always add color.
+	ColorModeNever                    // Never add color.
+)
+
+// Resolve whether color is added to the writer by the mode in the same way as [nslog.LogHandler],
+// such as for tools writing colored text without the handler. ColorModeDefault adds no color.
+func (mode ColorMode) Enabled(writer io.Writer) bool {
+	return mode.addColor(false, writer)
+}
+
+// Resolve whether color is added to the writer.
+func (mode ColorMode) addColor(addColor bool, writer io.Writer) bool {
+	switch mode {
+	case ColorModeAuto:
+		if os.Getenv("NO_COLOR") != "" {
+			return false
+		}
+		if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+			return true
+		}
+		return isTerminal(writer)
+	case ColorModeAlways:
+		return true
+	case ColorModeNever:
+		return false
+	default:
+		return addColor
+	}
+}
+
+// Check whether the writer is a terminal.
+func isTerminal(writer io.Writer) bool {
+	file, ok := writer.(interface{ Fd() uintptr })
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(file.Fd()) || isatty.IsCygwinTerminal(file.Fd())
+}
+
+// Colors of fields prepared on creation of handler.
+type fieldColors struct {
+	time    *color.Color
+	source  *color.Color
+	attrKey *color.Color
+}
+
+// A color theme used when AddColor option is true.
+// Colors of levels fall back to [nslog.DEFAULT_THEME] if they are nil.
+// Fields (time, source, and attribute keys) are colored if their colors are set, or if ColorTime, ColorSource, or ColorAttrKeys
+// option is true, in which case the default colors of fields are used for nil.
+type Theme struct {
+	Error   *color.Color // Color for Error level.
+	Warn    *color.Color // Color for Warn level.
+	Info    *color.Color // Color for Info level.
+	Debug   *color.Color // Color for Debug level.
+	Time    *color.Color // Color for time.
+	Source  *color.Color // Color for source.
+	AttrKey *color.Color // Color for keys of attributes.
+}
+
+var DEFAULT_THEME = Theme{
+	Error: color.New(color.FgHiRed),
+	Warn:  color.New(color.FgHiYellow),
+	Info:  color.New(color.FgHiGreen),
+	Debug: color.New(color.FgHiCyan),
+}
+
+// Themes by name for [nslog.ParseTheme] and GO_NSLOG_THEME environment variable.
+var THEMES = map[string]Theme{
+	"default": DEFAULT_THEME,
+	"solarized-dark": {
+		Error:   TrueColor(0xdc, 0x32, 0x2f),
+		Warn:    TrueColor(0xb5, 0x89, 0x00),
+		Info:    TrueColor(0x85, 0x99, 0x00),
+		Debug:   TrueColor(0x2a, 0xa1, 0x98),
+		Time:    TrueColor(0x58, 0x6e, 0x75),
+		Source:  TrueColor(0x6c, 0x71, 0xc4),
+		AttrKey: TrueColor(0x26, 0x8b, 0xd2),
+	},
+	"solarized-light": {
+		Error:   TrueColor(0xdc, 0x32, 0x2f),
+		Warn:    TrueColor(0xb5, 0x89, 0x00),
+		Info:    TrueColor(0x85, 0x99, 0x00),
+		Debug:   TrueColor(0x2a, 0xa1, 0x98),
+		Time:    TrueColor(0x93, 0xa1, 0xa1),
+		Source:  TrueColor(0x6c, 0x71, 0xc4),
+		AttrKey: TrueColor(0x26, 0x8b, 0xd2),
+	},
+	"monokai": {
+		Error:   TrueColor(0xf9, 0x26, 0x72),
+		Warn:    TrueColor(0xfd, 0x97, 0x1f),
+		Info:    TrueColor(0xa6, 0xe2, 0x2e),
+		Debug:   TrueColor(0x66, 0xd9, 0xef),
+		Time:    TrueColor(0x75, 0x71, 0x5e),
+		Source:  TrueColor(0xae, 0x81, 0xff),
+		AttrKey: TrueColor(0xe6, 0xdb, 0x74),
+	},
+	"mono": {
+		Error: color.New(color.Bold, color.ReverseVideo),
+		Warn:  color.New(color.Bold),
+		Info:  color.New(color.Reset),
+		Debug: color.New(color.Faint),
+	},
+}
+
+var themeColorNames = map[string]color.Attribute{
+	"black": color.FgBlack, "red": color.FgRed, "green": color.FgGreen, "yellow": color.FgYellow,
+	"blue": color.FgBlue, "magenta": color.FgMagenta, "cyan": color.FgCyan, "white": color.FgWhite,
+	"hi-black": color.FgHiBlack, "hi-red": color.FgHiRed, "hi-green": color.FgHiGreen, "hi-yellow": color.FgHiYellow,
+	"hi-blue": color.FgHiBlue, "hi-magenta": color.FgHiMagenta, "hi-cyan": color.FgHiCyan, "hi-white": color.FgHiWhite,
+	"bold": color.Bold, "faint": color.Faint, "dim": color.Faint, "italic": color.Italic, "underline": color.Underline, "reverse": color.ReverseVideo,
+}
+
+var DEFAULT_TIME_COLOR = color.New(color.Faint)
+var DEFAULT_SOURCE_COLOR = color.New(color.FgHiMagenta)
+var DEFAULT_ATTR_KEY_COLOR = color.New(color.FgCyan)
+
+// Create a color from the 256-color palette, which is supported by most terminals.
+func Color256(n uint8) *color.Color {
+	return color.New(38, 5, color.Attribute(n))
+}
+
+// Create a 24-bit true color, which is supported by modern terminals.
+func TrueColor(r, g, b uint8) *color.Color {
+	return color.RGB(int(r), int(g), int(b))
+}
+
+// Parse a theme such as "solarized-dark" (a name of [nslog.THEMES]), "error=red;warn=yellow", or "monokai;time=faint".
+// Keys are error, warn, info, debug, time, source, and attr, and colors are names such as "red" and "hi-red",
+// attributes such as "bold", numbers of the 256-color palette such as "33", or true colors such as "#ff8800", joined by "+".
+// The theme starts with the default theme unless the first item is a name.
+func ParseTheme(s string) (*Theme, error) {
+	theme := DEFAULT_THEME
+	for i, item := range strings.Split(s, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		if !ok {
+			named, found := THEMES[strings.ToLower(item)]
+			if !found || i > 0 {
+				return nil, fmt.Errorf("nslog: unknown theme %q", item)
+			}
+			theme = named
+			continue
+		}
+		c, err := parseThemeColor(value)
+		if err != nil {
+			return nil, err
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "error":
+			theme.Error = c
+		case "warn":
+			theme.Warn = c
+		case "info":
+			theme.Info = c
+		case "debug":
+			theme.Debug = c
+		case "time":
+			theme.Time = c
+		case "source":
+			theme.Source = c
+		case "attr":
+			theme.AttrKey = c
+		default:
+			return nil, fmt.Errorf("nslog: unknown theme key %q", key)
+		}
+	}
+	return &theme, nil
+}
+
+// Parse a color of the theme such as "red", "bold+hi-red", "33", and "#ff8800".
+func parseThemeColor(s string) (*color.Color, error) {
+	c := color.New()
+	for _, name := range strings.Split(strings.ToLower(strings.TrimSpace(s)), "+") {
+		if attribute, ok := themeColorNames[name]; ok {
+			c.Add(attribute)
+			continue
+		}
+		if n, err := strconv.ParseUint(name, 10, 8); err == nil {
+			c.Add(38, 5, color.Attribute(n))
+			continue
+		}
+		if rgb, err := strconv.ParseUint(strings.TrimPrefix(name, "#"), 16, 32); err == nil && len(name) == 7 && name[0] == '#' {
+			c.AddRGB(int(rgb>>16), int(rgb>>8&0xff), int(rgb&0xff))
+			continue
+		}
+		return nil, fmt.Errorf("nslog: unknown theme color %q", name)
+	}
+	return c, nil
+}
+
+// Get color for the level. It returns nil if the level is not one of Error, Warn, Info, and Debug.
+func (theme *Theme) level(level slog.Level) *color.Color {
+	var c, fallback *color.Color
+	switch level {
+	case LevelFatal, slog.LevelError:
+		c, fallback = theme.Error, DEFAULT_THEME.Error
+	case slog.LevelWarn:
+		c, fallback = theme.Warn, DEFAULT_THEME.Warn
+	case slog.LevelInfo:
+		c, fallback = theme.Info, DEFAULT_THEME.Info
+	case slog.LevelDebug:
+		c, fallback = theme.Debug, DEFAULT_THEME.Debug
+	}
+	if c == nil {
+		return fallback
+	}
+	return c
+}
+
+// Get color of the field from the theme, or the default color if the field is enabled by option.
+func fieldColor(c *color.Color, enabled bool, fallback *color.Color) *color.Color {
+	if c == nil && enabled {
+		return fallback
+	}
+	return c
+}
+
+// Copy the color to add color regardless of whether stdout is a terminal,
+// because the handler decides it for its own writer.
+func enableColor(c *color.Color) *color.Color {
+	if c == nil {
+		return nil
+	}
+	enabled := *c
+	enabled.EnableColor()
+	return &enabled
+}
+
+// Colorize the string if the color is not nil.
+func colorize(c *color.Color, s string) string {
+	if c == nil {
+		return s
+	}
+	return c.Sprint(s)
+}
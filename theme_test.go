@@ -0,0 +1,160 @@
+package nslog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func forceColor(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	t.Cleanup(func() { color.NoColor = noColor })
+}
+
+func TestDefaultTheme(t *testing.T) {
+	forceColor(t)
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{AddColor: true})
+	log.Info("log message", "key1", "val1")
+	log.Error("log message")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" \x1b\\[92mINFO\\.\x1b\\[0m log message key1=val1\n", buf.String())
+	assert.Contains(t, buf.String(), " \x1b[91mERROR\x1b[0m log message (")
+}
+
+func TestCustomTheme(t *testing.T) {
+	forceColor(t)
+	buf := new(bytes.Buffer)
+	theme := &Theme{
+		Info:    Color256(33),
+		Time:    color.New(color.Faint),
+		Source:  TrueColor(0x12, 0x34, 0x56),
+		AttrKey: color.New(color.FgBlue),
+	}
+	log := NewLogger(buf, &LogHandlerOptions{AddColor: true, Theme: theme}).With("id", 1)
+	log.Info("log message", "key1", "val1")
+	log.Warn("log message")
+	reset := "\x1b\\[[0-9;]*m"
+	assert.Regexp(t, "^\x1b\\[2m"+DEFAULT_TIME_REGEXP+reset+" \x1b\\[38;5;33mINFO\\."+reset+" \\[\x1b\\[34mid"+reset+"=1\\]: log message \x1b\\[34mkey1"+reset+"=val1\n", buf.String())
+	assert.Regexp(t, " \x1b\\[93mWARN\\."+reset+" \\[\x1b\\[34mid"+reset+"=1\\]: log message \x1b\\[38;2;18;52;86m\\(theme_test\\.go:\\d+\\)"+reset+"\n", buf.String())
+}
+
+func TestThemeWithoutColor(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{Theme: &Theme{Time: color.New(color.Faint)}})
+	log.Info("log message", "key1", "val1")
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" INFO\\. log message key1=val1\n$", buf.String())
+}
+
+func TestColorModeAlways(t *testing.T) {
+	// color is added even if stdout is not a terminal
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{ColorMode: ColorModeAlways})
+	log.Info("log message")
+	assert.Contains(t, buf.String(), "\x1b[92mINFO.\x1b[0m log message")
+}
+
+func TestColorModeNever(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{AddColor: true, ColorMode: ColorModeNever})
+	log.Info("log message")
+	assert.Contains(t, buf.String(), " INFO. log message")
+}
+
+func TestColorModeAuto(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{ColorMode: ColorModeAuto})
+	log.Info("log message")
+	assert.Contains(t, buf.String(), " INFO. log message")
+}
+
+func TestColorModeAutoForce(t *testing.T) {
+	t.Setenv("CLICOLOR_FORCE", "1")
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{ColorMode: ColorModeAuto})
+	log.Info("log message")
+	assert.Contains(t, buf.String(), "\x1b[92mINFO.\x1b[0m log message")
+}
+
+func TestColorModeAutoNoColor(t *testing.T) {
+	t.Setenv("CLICOLOR_FORCE", "1")
+	t.Setenv("NO_COLOR", "1")
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{ColorMode: ColorModeAuto})
+	log.Info("log message")
+	assert.Contains(t, buf.String(), " INFO. log message")
+}
+
+func TestColorModeEnabled(t *testing.T) {
+	buf := new(bytes.Buffer)
+	assert.True(t, ColorModeAlways.Enabled(buf))
+	assert.False(t, ColorModeNever.Enabled(buf))
+	assert.False(t, ColorModeDefault.Enabled(buf))
+	assert.False(t, ColorModeAuto.Enabled(buf))
+	t.Setenv("CLICOLOR_FORCE", "1")
+	assert.True(t, ColorModeAuto.Enabled(buf))
+}
+
+func TestColorModeEnv(t *testing.T) {
+	t.Setenv("GO_NSLOG_ADD_COLOR", "auto")
+	t.Setenv("CLICOLOR_FORCE", "1")
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, nil)
+	log.Info("log message")
+	assert.Contains(t, buf.String(), "\x1b[92mINFO.\x1b[0m log message")
+}
+
+func TestColorFields(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{ColorMode: ColorModeAlways, ColorTime: true, ColorAttrKeys: true, ColorSource: true})
+	log.Warn("log message", "key1", "val1")
+	reset := "\x1b\\[[0-9;]*m"
+	assert.Regexp(t, "^\x1b\\[2m"+DEFAULT_TIME_REGEXP+reset+" \x1b\\[93mWARN\\."+reset+" log message \x1b\\[36mkey1"+reset+"=val1 \x1b\\[95m\\(theme_test\\.go:\\d+\\)"+reset+"\n$", buf.String())
+}
+
+func TestColorFieldsIndividually(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{ColorMode: ColorModeAlways, ColorAttrKeys: true, Theme: &Theme{AttrKey: color.New(color.FgBlue)}})
+	log.Warn("log message", "key1", "val1")
+	reset := "\x1b\\[[0-9;]*m"
+	assert.Regexp(t, "^"+DEFAULT_TIME_REGEXP+" \x1b\\[93mWARN\\."+reset+" log message \x1b\\[34mkey1"+reset+"=val1 \\(theme_test\\.go:\\d+\\)\n$", buf.String())
+}
+
+func TestParseTheme(t *testing.T) {
+	forceColor(t)
+	theme, err := ParseTheme("error=bold+hi-red; warn=yellow;time=33;attr=#ff8800")
+	assert.NoError(t, err)
+	reset := "\x1b\\[[0-9;]*m"
+	assert.Regexp(t, "^\x1b\\[1;91mx"+reset+"$", theme.Error.Sprint("x"))
+	assert.Regexp(t, "^\x1b\\[33mx"+reset+"$", theme.Warn.Sprint("x"))
+	assert.Equal(t, DEFAULT_THEME.Info, theme.Info)
+	assert.Regexp(t, "^\x1b\\[38;5;33mx"+reset+"$", theme.Time.Sprint("x"))
+	assert.Regexp(t, "^\x1b\\[38;2;255;136;0mx"+reset+"$", theme.AttrKey.Sprint("x"))
+
+	theme, err = ParseTheme("Monokai;info=green")
+	assert.NoError(t, err)
+	assert.Equal(t, THEMES["monokai"].Error, theme.Error)
+	assert.Equal(t, "\x1b[32mx\x1b[0m", theme.Info.Sprint("x"))
+
+	for _, s := range []string{"unknown", "error=red;monokai", "level=red", "error=pink", "error=#12345", "error=256"} {
+		_, err = ParseTheme(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestThemeEnv(t *testing.T) {
+	forceColor(t)
+	t.Setenv("GO_NSLOG_THEME", "info=blue")
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{AddColor: true, OmitTime: true})
+	log.Info("log message")
+	assert.Equal(t, "\x1b[34mINFO.\x1b[0m log message\n", buf.String())
+
+	t.Setenv("GO_NSLOG_THEME", "invalid")
+	buf.Reset()
+	log = NewLogger(buf, &LogHandlerOptions{AddColor: true, OmitTime: true})
+	log.Info("log message")
+	assert.Equal(t, "\x1b[92mINFO.\x1b[0m log message\n", buf.String())
+}
@@ -0,0 +1,194 @@
+package nslog
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const DEFAULT_WRITE_TIMEOUT = 100 * time.Millisecond
+const DEFAULT_SPILL_BUFFER_SIZE = 8 << 20
+
+// An option to customize [nslog.TimeoutWriter].
+type TimeoutWriterOptions struct {
+	Timeout         time.Duration // Set time to wait for Write of the underlying writer before spilling. (default: 100 milliseconds)
+	SpillBufferSize int           // Set maximum bytes of data buffered in memory including data being written. Data exceeding it is dropped. (default: 8 MiB)
+}
+
+// Counters of [nslog.TimeoutWriter].
+type TimeoutWriterStats struct {
+	Timeouts uint64 `json:"timeouts"` // number of writes which did not complete in time
+	Spilled  uint64 `json:"spilled"`  // number of writes buffered in memory because the underlying writer was slow
+	Dropped  uint64 `json:"dropped"`  // number of writes dropped because the buffer was full
+	Errors   uint64 `json:"errors"`   // number of writes of the underlying writer failed after spilling
+	Buffered int    `json:"buffered"` // bytes buffered in memory currently
+}
+
+// A writer with deadline of write latency for slow sinks such as NFS and network, so logging never stalls request handling.
+// Data is written by a goroutine, and Write returns after the data is written or the timeout is over.
+// While the underlying writer is slow, data is spilled to an in-memory buffer and written later in order.
+type TimeoutWriter struct {
+	writer  io.Writer
+	options TimeoutWriterOptions
+	mutex   sync.Mutex
+	cond    *sync.Cond
+	pending []timeoutEntry
+	size    int  // bytes of pending data
+	writing bool // whether the goroutine is writing
+	closed  bool
+	stats   TimeoutWriterStats
+	done    chan struct{} // closed when the goroutine exits
+}
+
+type timeoutEntry struct {
+	data   []byte
+	result chan error // channel to receive result of the write, which is nil for spilled data
+}
+
+// Create a new [nslog.TimeoutWriter] object, which starts goroutine to write data.
+// Call [TimeoutWriter.Close] to write the spilled data and stop the goroutine.
+func NewTimeoutWriter(writer io.Writer, options *TimeoutWriterOptions) *TimeoutWriter {
+	// set default parameters
+	if options == nil {
+		options = &TimeoutWriterOptions{}
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = DEFAULT_WRITE_TIMEOUT
+	}
+	if options.SpillBufferSize <= 0 {
+		options.SpillBufferSize = DEFAULT_SPILL_BUFFER_SIZE
+	}
+
+	timeout := &TimeoutWriter{
+		writer:  writer,
+		options: *options,
+		done:    make(chan struct{}),
+	}
+	timeout.cond = sync.NewCond(&timeout.mutex)
+	go timeout.run()
+	return timeout
+}
+
+// Write the data, or spill it to the buffer if the underlying writer is slow.
+// The error of the underlying writer is returned only if the write completes in time without spilling.
+func (writer *TimeoutWriter) Write(p []byte) (int, error) {
+	data := make([]byte, len(p))
+	copy(data, p)
+
+	writer.mutex.Lock()
+	if writer.closed {
+		writer.mutex.Unlock()
+		return 0, os.ErrClosed
+	}
+	if writer.writing || len(writer.pending) > 0 {
+		// spill without waiting since the underlying writer is busy
+		if writer.size+len(data) > writer.options.SpillBufferSize {
+			writer.stats.Dropped++
+		} else {
+			writer.pending = append(writer.pending, timeoutEntry{data: data})
+			writer.size += len(data)
+			writer.stats.Spilled++
+		}
+		writer.mutex.Unlock()
+		return len(p), nil
+	}
+	result := make(chan error, 1)
+	writer.pending = append(writer.pending, timeoutEntry{data: data, result: result})
+	writer.size += len(data)
+	writer.cond.Broadcast()
+	writer.mutex.Unlock()
+
+	timer := time.NewTimer(writer.options.Timeout)
+	defer timer.Stop()
+	select {
+	case err := <-result:
+		if err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	case <-timer.C:
+		writer.mutex.Lock()
+		writer.stats.Timeouts++
+		writer.mutex.Unlock()
+		return len(p), nil
+	}
+}
+
+// Wait until the spilled data is written to the underlying writer.
+func (writer *TimeoutWriter) Flush() error {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	for writer.writing || len(writer.pending) > 0 {
+		writer.cond.Wait()
+	}
+	return nil
+}
+
+// Wait until the spilled data is written, and commit it to stable storage if the underlying writer has Sync method.
+func (writer *TimeoutWriter) Sync() error {
+	err := writer.Flush()
+	if err != nil {
+		return err
+	}
+	syncer, ok := writer.writer.(interface{ Sync() error })
+	if !ok {
+		return nil
+	}
+	return syncer.Sync()
+}
+
+// Get counters of the writer.
+func (writer *TimeoutWriter) Stats() TimeoutWriterStats {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	stats := writer.stats
+	stats.Buffered = writer.size
+	return stats
+}
+
+// Write the spilled data and stop goroutine. The underlying writer is not closed.
+func (writer *TimeoutWriter) Close() error {
+	writer.mutex.Lock()
+	writer.closed = true
+	writer.cond.Broadcast()
+	writer.mutex.Unlock()
+	<-writer.done
+	return nil
+}
+
+func (writer *TimeoutWriter) run() {
+	defer close(writer.done)
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	for {
+		for len(writer.pending) == 0 && !writer.closed {
+			writer.cond.Wait()
+		}
+		if len(writer.pending) == 0 {
+			return
+		}
+
+		// write all pending data at once
+		entries := writer.pending
+		writer.pending = nil
+		writer.writing = true
+		writer.mutex.Unlock()
+		var data []byte
+		for _, entry := range entries {
+			data = append(data, entry.data...)
+		}
+		_, err := writer.writer.Write(data)
+		writer.mutex.Lock()
+		writer.writing = false
+		writer.size -= len(data)
+		for _, entry := range entries {
+			if entry.result != nil {
+				entry.result <- err
+			} else if err != nil {
+				writer.stats.Errors++
+			}
+		}
+		writer.cond.Broadcast()
+	}
+}
@@ -0,0 +1,78 @@
+package nslog
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A writer blocked until release is closed.
+type blockedWriter struct {
+	mutex   sync.Mutex
+	buf     bytes.Buffer
+	release chan struct{}
+	err     error
+}
+
+func (writer *blockedWriter) Write(p []byte) (int, error) {
+	<-writer.release
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	if writer.err != nil {
+		return 0, writer.err
+	}
+	return writer.buf.Write(p)
+}
+
+func (writer *blockedWriter) String() string {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	return writer.buf.String()
+}
+
+func TestTimeoutWriter(t *testing.T) {
+	slow := &blockedWriter{release: make(chan struct{})}
+	close(slow.release)
+	writer := NewTimeoutWriter(slow, nil)
+	log := NewLogger(writer, &LogHandlerOptions{OmitTime: true})
+	log.Info("message1")
+	assert.Equal(t, "INFO. message1\n", slow.String())
+	assert.NoError(t, writer.Close())
+	assert.Equal(t, TimeoutWriterStats{}, writer.Stats())
+
+	_, err := writer.Write([]byte("message2\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestTimeoutWriterSpill(t *testing.T) {
+	slow := &blockedWriter{release: make(chan struct{})}
+	writer := NewTimeoutWriter(slow, &TimeoutWriterOptions{Timeout: 10 * time.Millisecond, SpillBufferSize: 30})
+	log := NewLogger(writer, &LogHandlerOptions{OmitTime: true})
+
+	start := time.Now()
+	log.Info("message1") // timeout
+	log.Info("message2") // spilled
+	log.Info("message3") // dropped since the buffer is full
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, TimeoutWriterStats{Timeouts: 1, Spilled: 1, Dropped: 1, Buffered: 30}, writer.Stats())
+
+	close(slow.release)
+	assert.NoError(t, writer.Flush())
+	assert.Equal(t, "INFO. message1\nINFO. message2\n", slow.String())
+	assert.Equal(t, 0, writer.Stats().Buffered)
+	assert.NoError(t, writer.Close())
+}
+
+func TestTimeoutWriterError(t *testing.T) {
+	slow := &blockedWriter{release: make(chan struct{}), err: errors.New("disk full")}
+	close(slow.release)
+	writer := NewTimeoutWriter(slow, nil)
+	defer writer.Close()
+	_, err := writer.Write([]byte("message\n"))
+	assert.EqualError(t, err, "disk full")
+}
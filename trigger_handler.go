@@ -0,0 +1,159 @@
+package nslog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+const DEFAULT_TRIGGER_LEVEL = slog.LevelError
+const DEFAULT_TRIGGER_BUFFER_SIZE = 100
+const DEFAULT_TRIGGER_MAX_SCOPES = 1000
+
+// An option to customize [nslog.TriggerHandler].
+type TriggerHandlerOptions struct {
+	TriggerLevel slog.Leveler // Set level to flush buffered records. (default: slog.LevelError)
+	BufferLevel  slog.Leveler // Set lowest level to buffer records which are not enabled by the next handler. (default: slog.LevelDebug)
+	BufferSize   int          // Set maximum number of buffered records per scope. The oldest record is discarded if exceeded. (default: 100)
+	MaxScopes    int          // Set maximum number of goroutine scopes. The oldest scope is discarded if exceeded. (default: 1000)
+}
+
+// A handler to buffer records which are not enabled by the next handler per scope, and flush them
+// to the next handler only when a record at or above the trigger level arrives in the same scope.
+//
+// The scope is a context created by [nslog.NewTriggerScope], such as a request.
+// If the context has no scope, the goroutine is used as the scope, which is costly to identify.
+type TriggerHandler struct {
+	next    slog.Handler
+	options TriggerHandlerOptions
+	scopes  *triggerScopes
+}
+
+type triggerEntry struct {
+	next   slog.Handler
+	record slog.Record
+}
+
+type triggerBuffer struct {
+	mutex   sync.Mutex
+	entries []triggerEntry
+}
+
+type triggerScopes struct {
+	mutex   sync.Mutex
+	buffers map[uint64]*triggerBuffer
+	order   []uint64
+}
+
+type triggerScopeKey struct{}
+
+// Create a new context with a new scope for [nslog.TriggerHandler].
+func NewTriggerScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, triggerScopeKey{}, &triggerBuffer{})
+}
+
+// Create a new [nslog.TriggerHandler] object.
+func NewTriggerHandler(next slog.Handler, options *TriggerHandlerOptions) *TriggerHandler {
+	// set default parameters
+	if options == nil {
+		options = &TriggerHandlerOptions{}
+	}
+	if options.TriggerLevel == nil {
+		options.TriggerLevel = DEFAULT_TRIGGER_LEVEL
+	}
+	if options.BufferLevel == nil {
+		options.BufferLevel = slog.LevelDebug
+	}
+	if options.BufferSize <= 0 {
+		options.BufferSize = DEFAULT_TRIGGER_BUFFER_SIZE
+	}
+	if options.MaxScopes <= 0 {
+		options.MaxScopes = DEFAULT_TRIGGER_MAX_SCOPES
+	}
+
+	return &TriggerHandler{
+		next:    next,
+		options: *options,
+		scopes:  &triggerScopes{buffers: map[uint64]*triggerBuffer{}},
+	}
+}
+
+func (handler *TriggerHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= handler.options.BufferLevel.Level() || handler.next.Enabled(ctx, level)
+}
+
+func (handler *TriggerHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &TriggerHandler{
+		next:    handler.next.WithAttrs(attrs),
+		options: handler.options,
+		scopes:  handler.scopes,
+	}
+}
+
+func (handler *TriggerHandler) WithGroup(name string) slog.Handler {
+	return &TriggerHandler{
+		next:    handler.next.WithGroup(name),
+		options: handler.options,
+		scopes:  handler.scopes,
+	}
+}
+
+func (handler *TriggerHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= handler.options.TriggerLevel.Level() {
+		// flush buffered records before the trigger record
+		for _, entry := range handler.buffer(ctx).flush() {
+			err := entry.next.Handle(ctx, entry.record)
+			if err != nil {
+				return err
+			}
+		}
+		return handler.next.Handle(ctx, record)
+	}
+	if handler.next.Enabled(ctx, record.Level) {
+		return handler.next.Handle(ctx, record)
+	}
+	if record.Level >= handler.options.BufferLevel.Level() {
+		handler.buffer(ctx).add(triggerEntry{next: handler.next, record: record.Clone()}, handler.options.BufferSize)
+	}
+	return nil
+}
+
+// Get buffer of the scope in the context, or of the current goroutine.
+func (handler *TriggerHandler) buffer(ctx context.Context) *triggerBuffer {
+	if buffer, ok := ctx.Value(triggerScopeKey{}).(*triggerBuffer); ok {
+		return buffer
+	}
+
+	id := goroutineID()
+	scopes := handler.scopes
+	scopes.mutex.Lock()
+	defer scopes.mutex.Unlock()
+	buffer, ok := scopes.buffers[id]
+	if !ok {
+		if len(scopes.order) >= handler.options.MaxScopes {
+			delete(scopes.buffers, scopes.order[0])
+			scopes.order = scopes.order[1:]
+		}
+		buffer = &triggerBuffer{}
+		scopes.buffers[id] = buffer
+		scopes.order = append(scopes.order, id)
+	}
+	return buffer
+}
+
+func (buffer *triggerBuffer) add(entry triggerEntry, size int) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	if len(buffer.entries) >= size {
+		buffer.entries = buffer.entries[1:]
+	}
+	buffer.entries = append(buffer.entries, entry)
+}
+
+func (buffer *triggerBuffer) flush() []triggerEntry {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	entries := buffer.entries
+	buffer.entries = nil
+	return entries
+}
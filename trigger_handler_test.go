@@ -0,0 +1,66 @@
+package nslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTriggerHandler(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := slog.New(NewTriggerHandler(NewLogHandler(buf, nil), nil))
+	log.Debug("message1")
+	log.Info("message2")
+	assert.NotContains(t, buf.String(), "message1")
+	assert.Contains(t, buf.String(), "INFO. message2")
+
+	log.WithGroup("Group1").Debug("message3")
+	log.Error("message4")
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 4)
+	assert.Contains(t, lines[1], "DEBUG message1")
+	assert.Contains(t, lines[2], "DEBUG Group1: message3")
+	assert.Contains(t, lines[3], "ERROR message4")
+
+	// buffer is flushed
+	log.Error("message5")
+	assert.Len(t, strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"), 5)
+}
+
+func TestTriggerHandlerScope(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := slog.New(NewTriggerHandler(NewLogHandler(buf, nil), &TriggerHandlerOptions{BufferSize: 2}))
+	ctx1 := NewTriggerScope(context.Background())
+	ctx2 := NewTriggerScope(context.Background())
+	log.DebugContext(ctx1, "message1")
+	log.DebugContext(ctx2, "message2")
+	log.DebugContext(ctx1, "message3")
+	log.DebugContext(ctx1, "message4")
+	log.ErrorContext(ctx1, "message5")
+	assert.NotContains(t, buf.String(), "message1")
+	assert.NotContains(t, buf.String(), "message2")
+	assert.Contains(t, buf.String(), "DEBUG message3")
+	assert.Contains(t, buf.String(), "DEBUG message4")
+	assert.Contains(t, buf.String(), "ERROR message5")
+}
+
+func TestTriggerHandlerGoroutine(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := slog.New(NewTriggerHandler(NewLogHandler(buf, nil), nil))
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		log.Debug("message1")
+	}()
+	wg.Wait()
+	log.Debug("message2")
+	log.Error("message3")
+	assert.NotContains(t, buf.String(), "message1")
+	assert.Contains(t, buf.String(), "DEBUG message2")
+}
@@ -0,0 +1,42 @@
+package nslog
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Get marker appended to truncated line.
+func truncatedMarker(n int) string {
+	return "…(truncated " + strconv.Itoa(n) + " bytes)"
+}
+
+// Truncate the line to the limit with a marker such as "…(truncated 12 bytes)".
+// The attributes in the line are cut preferentially to keep the message, and the whole line is cut if it is not enough.
+func truncateLine(line string, attributes string, limit int) string {
+	if len(line) <= limit {
+		return line
+	}
+	// upper bound of bytes to cut because the marker is not longer than the one with length of the line
+	cut := len(line) - limit + len(truncatedMarker(len(line)))
+
+	if attributes != "" {
+		end := strings.LastIndex(line, attributes) + len(attributes)
+		start := end - len(attributes)
+		if start >= 0 && cut <= len(attributes) {
+			keep := attributes[:runeBoundary(attributes, len(attributes)-cut)]
+			return line[:start] + keep + truncatedMarker(len(attributes)-len(keep)) + line[end:]
+		}
+	}
+
+	keep := line[:runeBoundary(line, max(len(line)-cut, 0))]
+	return keep + truncatedMarker(len(line)-len(keep))
+}
+
+// Get the largest index not greater than i, which is at the start of a rune.
+func runeBoundary(s string, i int) int {
+	for i > 0 && i < len(s) && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return i
+}
@@ -0,0 +1,44 @@
+package nslog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxLineLength(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true, MaxLineLength: 50})
+	log.Info("log message", "key1", strings.Repeat("a", 50))
+	assert.Equal(t, "INFO. log message key1=aaaa…(truncated 46 bytes)\n", buf.String())
+	assert.Len(t, strings.TrimSuffix(buf.String(), "\n"), 50)
+}
+
+func TestMaxLineLengthSource(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true, MaxLineLength: 80})
+	log.Warn("log message", "key1", strings.Repeat("a", 80))
+	assert.Regexp(t, "^WARN\\. log message key1=a+…\\(truncated \\d+ bytes\\) \\(truncate_test\\.go:\\d+\\)\n$", buf.String())
+	assert.LessOrEqual(t, len(strings.TrimSuffix(buf.String(), "\n")), 80)
+}
+
+func TestMaxLineLengthMessage(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true, MaxLineLength: 40})
+	log.Info(strings.Repeat("m", 50), "key1", "val1")
+	assert.Equal(t, "INFO. mmmmmmmmmmm…(truncated 49 bytes)\n", buf.String())
+}
+
+func TestMaxLineLengthNotExceeded(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true, MaxLineLength: 40})
+	log.Info("log message", "key1", "val1")
+	assert.Equal(t, "INFO. log message key1=val1\n", buf.String())
+}
+
+func TestTruncateLineRune(t *testing.T) {
+	line := truncateLine("msg k=ああああああああああああ", "k=ああああああああああああ", 32)
+	assert.Equal(t, "msg k=あ…(truncated 33 bytes)", line)
+}
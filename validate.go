@@ -0,0 +1,209 @@
+package nslog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validate the options, which reports values ignored or fallen back silently by [nslog.NewLogHandler] such as
+// a time layout without elements of time, values of enums out of range, bad glob patterns of keys, and conflicting options.
+// Environment variables are not checked. Errors of all invalid options are joined by [errors.Join].
+func (options *LogHandlerOptions) Validate() error {
+	var errs []error
+	if options.TimeLayout != "" {
+		errs = append(errs, validateTimeLayout(options.TimeLayout))
+	}
+	if options.ColorMode < 0 || int(options.ColorMode) >= len(colorModeNames) {
+		errs = append(errs, fmt.Errorf("nslog: invalid color mode %s", options.ColorMode))
+	}
+	if options.LevelStyle < 0 || int(options.LevelStyle) >= len(levelStyleNames) {
+		errs = append(errs, fmt.Errorf("nslog: invalid level style %s", options.LevelStyle))
+	}
+	if options.LevelSymbol < 0 || int(options.LevelSymbol) >= len(levelSymbolNames) {
+		errs = append(errs, fmt.Errorf("nslog: invalid level symbol %s", options.LevelSymbol))
+	}
+	if options.Format < 0 || int(options.Format) >= len(outputFormatNames) {
+		errs = append(errs, fmt.Errorf("nslog: invalid format %s", options.Format))
+	}
+	if options.MessageOverflow < 0 || int(options.MessageOverflow) >= len(overflowNames) {
+		errs = append(errs, fmt.Errorf("nslog: invalid message overflow %s", options.MessageOverflow))
+	}
+	if options.AttrsOverflow < 0 || int(options.AttrsOverflow) >= len(overflowNames) {
+		errs = append(errs, fmt.Errorf("nslog: invalid attributes overflow %s", options.AttrsOverflow))
+	}
+	if options.TraceFormat < TraceFormatAttrs || options.TraceFormat > TraceFormatNone {
+		errs = append(errs, fmt.Errorf("nslog: invalid trace format %d", int(options.TraceFormat)))
+	}
+	if options.MessageColumn < 0 {
+		errs = append(errs, fmt.Errorf("nslog: invalid message column %d", options.MessageColumn))
+	}
+	if options.TerminalWidth < 0 {
+		errs = append(errs, fmt.Errorf("nslog: invalid terminal width %d", options.TerminalWidth))
+	}
+	if options.MaxLineLength < 0 {
+		errs = append(errs, fmt.Errorf("nslog: invalid max line length %d", options.MaxLineLength))
+	}
+	errs = append(errs, validateKeyPatterns(options.DropKeys), validateKeyPatterns(options.KeepOnlyKeys))
+	if options.BatchWrite && options.LiveProgress {
+		errs = append(errs, errors.New("nslog: BatchWrite and LiveProgress cannot be used together, since lines are not coalesced for live progress"))
+	}
+	return errors.Join(errs...)
+}
+
+// Check that environment variables overriding the options have valid values, which are ignored by [nslog.NewLogHandler] otherwise.
+// The output given by GO_NSLOG_OUTPUT is parsed but not opened, see [nslog.NewDefaultLoggerE].
+func (options *LogHandlerOptions) validateEnv() error {
+	prefix := options.EnvPrefix
+	if prefix == "" {
+		prefix = DEFAULT_ENV_PREFIX
+	}
+	var errs []error
+	for _, env := range envVars {
+		value := options.getenv(env.name)
+		if value == "" || env.validate == nil {
+			continue
+		}
+		if err := env.validate(value); err != nil {
+			errs = append(errs, fmt.Errorf("nslog: invalid environment variable %s%s=%q: %w", prefix, env.name, value, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Check that the value is a flag of environment variables such as "TRUE", "1", "FALSE", or "0".
+func validateEnvBool(value string) error {
+	if strings.EqualFold(value, "true") || value == "1" || strings.EqualFold(value, "false") || value == "0" {
+		return nil
+	}
+	return errors.New("not TRUE, 1, FALSE, or 0")
+}
+
+// Check that the value is "AUTO" or a flag for GO_NSLOG_ADD_COLOR.
+func validateEnvAddColor(value string) error {
+	if strings.EqualFold(value, "auto") {
+		return nil
+	}
+	return validateEnvBool(value)
+}
+
+func validateEnvLevel(value string) error {
+	_, err := ParseLevel(value)
+	return err
+}
+
+func validateEnvInt(value string) error {
+	_, err := strconv.Atoi(value)
+	return err
+}
+
+// Check that the value is a comma separated list of group and level such as "db=DEBUG,http=ERROR".
+func validateEnvGroupLevels(value string) error {
+	for _, groupLevel := range strings.Split(value, ",") {
+		_, level, ok := strings.Cut(groupLevel, "=")
+		if !ok {
+			return fmt.Errorf("no level of group %q", groupLevel)
+		}
+		if _, err := ParseLevel(level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateEnvTheme(value string) error {
+	_, err := ParseTheme(value)
+	return err
+}
+
+func validateEnvTimeLayout(value string) error {
+	switch value {
+	case "MILLIS", "MICROS", "RFC3339", "RFC3339NANO":
+		return nil
+	}
+	return validateTimeLayout(value)
+}
+
+func validateEnvTraceFormat(value string) error {
+	switch value {
+	case "ATTRS", "SUFFIX", "NONE":
+		return nil
+	}
+	return errors.New("not ATTRS, SUFFIX, or NONE")
+}
+
+// Check that the value is "NONE", "FILE", "VSCODE", or a template of URL including "{path}".
+func validateEnvSourceLink(value string) error {
+	switch value {
+	case "NONE", "FILE", "VSCODE":
+		return nil
+	}
+	if !strings.Contains(value, "{path}") {
+		return errors.New("not NONE, FILE, VSCODE, or a template including {path}")
+	}
+	return nil
+}
+
+// Check that the separator does not break a line into lines, which cannot be parsed by line-based tools.
+func validateEnvSeparator(value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return errors.New("separator including a line break")
+	}
+	return nil
+}
+
+func validateEnvKeyPatterns(value string) error {
+	return validateKeyPatterns(strings.Split(value, ","))
+}
+
+// Check that the output is "stderr", "stdout", a path of file, or a URL of a known scheme such as "syslog://".
+func validateEnvOutput(value string) error {
+	_, _, err := parseOutput(value)
+	return err
+}
+
+// Check that the layout has elements of time such as "2006" and "15:04", unlike "yyyy-MM-dd" of other languages.
+func validateTimeLayout(layout string) error {
+	if time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC).Format(layout) == layout {
+		return fmt.Errorf("nslog: invalid time layout %q, which has no elements of time such as \"2006\" and \"15:04\"", layout)
+	}
+	return nil
+}
+
+// Check that the glob patterns of keys are valid for [path.Match].
+func validateKeyPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("nslog: invalid pattern of keys %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Create a new [slog.Logger] object that implements [nslog.LogHandler], or return an error for invalid options.
+// See [nslog.NewLogHandlerE].
+func NewLoggerE(writer io.Writer, options *LogHandlerOptions) (*slog.Logger, error) {
+	handler, err := NewLogHandlerE(writer, options)
+	if err != nil {
+		return nil, err
+	}
+	return slog.New(handler), nil
+}
+
+// Create a new [nslog.LogHandler] object, or return an error if the options or environment variables overriding them are invalid
+// instead of ignoring invalid values silently like [nslog.NewLogHandler]. See [LogHandlerOptions.Validate] for the options.
+func NewLogHandlerE(writer io.Writer, options *LogHandlerOptions) (*LogHandler, error) {
+	if options == nil {
+		options = &LogHandlerOptions{}
+	}
+	err := errors.Join(options.Validate(), options.validateEnv())
+	if err != nil {
+		return nil, err
+	}
+	return NewLogHandler(writer, options), nil
+}
@@ -0,0 +1,76 @@
+package nslog
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, (&LogHandlerOptions{}).Validate())
+	assert.NoError(t, (&LogHandlerOptions{TimeLayout: TIME_LAYOUT_MILLIS, Format: FormatCompact, DropKeys: []string{"*password"}}).Validate())
+
+	err := (&LogHandlerOptions{TimeLayout: "yyyy-MM-dd"}).Validate()
+	assert.ErrorContains(t, err, `nslog: invalid time layout "yyyy-MM-dd"`)
+
+	err = (&LogHandlerOptions{Format: OutputFormat(9), MessageColumn: -1, KeepOnlyKeys: []string{"["}, BatchWrite: true, LiveProgress: true}).Validate()
+	assert.ErrorContains(t, err, "nslog: invalid format OutputFormat(9)")
+	assert.ErrorContains(t, err, "nslog: invalid message column -1")
+	assert.ErrorContains(t, err, `nslog: invalid pattern of keys "["`)
+	assert.ErrorContains(t, err, "nslog: BatchWrite and LiveProgress cannot be used together")
+}
+
+func TestNewLogHandlerE(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log, err := NewLoggerE(buf, &LogHandlerOptions{OmitTime: true})
+	assert.NoError(t, err)
+	log.Info("log message")
+	assert.Equal(t, "INFO. log message\n", buf.String())
+
+	handler, err := NewLogHandlerE(buf, &LogHandlerOptions{TimeLayout: "%Y-%m-%d"})
+	assert.Nil(t, handler)
+	assert.ErrorContains(t, err, "nslog: invalid time layout")
+}
+
+func TestEnvVars(t *testing.T) {
+	// all environment variables read by getenv are validated
+	names := map[string]bool{}
+	for _, env := range envVars {
+		names[env.name] = true
+	}
+	for _, file := range []string{"log_handler.go", "output.go"} {
+		data, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		for _, match := range regexp.MustCompile(`getenv\("(\w+)"\)`).FindAllStringSubmatch(string(data), -1) {
+			assert.True(t, names[match[1]], match[1])
+		}
+	}
+}
+
+func TestNewLogHandlerEEnv(t *testing.T) {
+	t.Setenv("GO_NSLOG_THEME", "error=pink")
+	t.Setenv("GO_NSLOG_LEVEL", "VERBOSE")
+	t.Setenv("GO_NSLOG_ADD_PID", "yes")
+	t.Setenv("GO_NSLOG_FORMAT", "LTSV")
+	_, err := NewLogHandlerE(new(bytes.Buffer), nil)
+	assert.ErrorContains(t, err, `nslog: invalid environment variable GO_NSLOG_THEME="error=pink": nslog: unknown theme color "pink"`)
+	assert.ErrorContains(t, err, `GO_NSLOG_LEVEL="VERBOSE"`)
+	assert.ErrorContains(t, err, `GO_NSLOG_ADD_PID="yes"`)
+	assert.NotContains(t, err.Error(), "GO_NSLOG_FORMAT")
+
+	t.Setenv("GO_NSLOG_OUTPUT", "http://localhost:8080")
+	t.Setenv("GO_NSLOG_SOURCE_LINK", "vscode://file")
+	t.Setenv("GO_NSLOG_FIELD_SEPARATOR", "\n")
+	_, err = NewLogHandlerE(new(bytes.Buffer), nil)
+	assert.ErrorContains(t, err, `GO_NSLOG_OUTPUT="http://localhost:8080": nslog: invalid output "http://localhost:8080", which has unknown scheme "http"`)
+	assert.ErrorContains(t, err, `GO_NSLOG_SOURCE_LINK="vscode://file"`)
+	assert.ErrorContains(t, err, `GO_NSLOG_FIELD_SEPARATOR="\n"`)
+
+	_, err = NewLogHandlerE(new(bytes.Buffer), &LogHandlerOptions{EnvPrefix: "MYLIB_LOG_"})
+	assert.NoError(t, err)
+	_, err = NewLogHandlerE(new(bytes.Buffer), &LogHandlerOptions{DisableEnv: true})
+	assert.NoError(t, err)
+}
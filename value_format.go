@@ -0,0 +1,94 @@
+package nslog
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// A format of byte slices in attribute values.
+type BytesFormat int
+
+const (
+	BytesFormatDefault BytesFormat = iota // Format by [slog.Value.String].
+	BytesFormatHex                        // Format as hex string such as "0a1b".
+	BytesFormatBase64                     // Format as standard base64 string such as "Chs=".
+)
+
+// A format of attribute values per kind. Values are formatted by [slog.Value.String] if it is nil or its fields are zero.
+type ValueFormat struct {
+	DurationRound time.Duration // Round durations to a multiple of this such as time.Millisecond for "1.235s".
+	TimeLayout    string        // Set layout of times in attributes for [Time.Format].
+	FloatFormat   string        // Set format of floats for [fmt.Sprintf] such as "%.2f".
+	Bytes         BytesFormat   // Set format of byte slices.
+	IntSeparator  string        // Set separator of thousands for integers such as "," for "1,234,567".
+
+	// Set functions to format values of the kinds, which take precedence over the other fields.
+	Formatters map[slog.Kind]func(value slog.Value) string
+}
+
+// Format the value according to the format.
+func (format *ValueFormat) format(value slog.Value) string {
+	if format == nil {
+		return value.String()
+	}
+	if formatter, ok := format.Formatters[value.Kind()]; ok {
+		return formatter(value)
+	}
+
+	switch value.Kind() {
+	case slog.KindDuration:
+		if format.DurationRound > 0 {
+			return value.Duration().Round(format.DurationRound).String()
+		}
+	case slog.KindTime:
+		if format.TimeLayout != "" {
+			return value.Time().Format(format.TimeLayout)
+		}
+	case slog.KindFloat64:
+		if format.FloatFormat != "" {
+			return fmt.Sprintf(format.FloatFormat, value.Float64())
+		}
+	case slog.KindInt64:
+		if format.IntSeparator != "" {
+			number := value.Int64()
+			if number < 0 {
+				return "-" + separateThousands(strconv.FormatUint(uint64(-number), 10), format.IntSeparator)
+			}
+			return separateThousands(strconv.FormatInt(number, 10), format.IntSeparator)
+		}
+	case slog.KindUint64:
+		if format.IntSeparator != "" {
+			return separateThousands(strconv.FormatUint(value.Uint64(), 10), format.IntSeparator)
+		}
+	case slog.KindAny:
+		if bytes, ok := value.Any().([]byte); ok {
+			switch format.Bytes {
+			case BytesFormatHex:
+				return hex.EncodeToString(bytes)
+			case BytesFormatBase64:
+				return base64.StdEncoding.EncodeToString(bytes)
+			}
+		}
+	}
+	return value.String()
+}
+
+// Insert separator every three digits from the right of the digits.
+func separateThousands(digits string, separator string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+	head := len(digits) % 3
+	if head == 0 {
+		head = 3
+	}
+	separated := digits[:head]
+	for i := head; i < len(digits); i += 3 {
+		separated += separator + digits[i:i+3]
+	}
+	return separated
+}
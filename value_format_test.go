@@ -0,0 +1,59 @@
+package nslog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValueFormat(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{ValueFormat: &ValueFormat{
+		DurationRound: time.Millisecond,
+		TimeLayout:    time.RFC3339,
+		FloatFormat:   "%.2f",
+		Bytes:         BytesFormatHex,
+		IntSeparator:  ",",
+	}})
+	log.Info("message",
+		"duration", 1234567*time.Microsecond,
+		"time", time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		"float", 3.14159,
+		"bytes", []byte{0x0a, 0x1b},
+		"int", -1234567,
+		"uint", uint64(123456),
+		"small", 123,
+	)
+	assert.Contains(t, buf.String(), "INFO. message duration=1.235s time=2024-01-02T03:04:05Z float=3.14 bytes=0a1b int=-1,234,567 uint=123,456 small=123\n")
+}
+
+func TestValueFormatFormatters(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{ValueFormat: &ValueFormat{
+		Bytes: BytesFormatBase64,
+		Formatters: map[slog.Kind]func(value slog.Value) string{
+			slog.KindString: func(value slog.Value) string { return strings.ToUpper(value.String()) },
+		},
+	}})
+	log.With("key1", "val1").Info("message", "key2", "val2", "bytes", []byte{0x0a, 0x1b})
+	assert.Contains(t, buf.String(), "INFO. [key1=VAL1]: message key2=VAL2 bytes=Chs=\n")
+}
+
+func TestValueFormatDefault(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, nil)
+	log.Info("message", "duration", 1234567*time.Microsecond, "float", 3.5, "int", 1234567)
+	assert.Contains(t, buf.String(), "INFO. message duration=1.234567s float=3.5 int=1234567\n")
+}
+
+func TestSeparateThousands(t *testing.T) {
+	assert.Equal(t, "1", separateThousands("1", ","))
+	assert.Equal(t, "123", separateThousands("123", ","))
+	assert.Equal(t, "1_234", separateThousands("1234", "_"))
+	assert.Equal(t, "123,456", separateThousands("123456", ","))
+	assert.Equal(t, "1,234,567", separateThousands("1234567", ","))
+}
@@ -0,0 +1,133 @@
+package nslog
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Width of tab of continuation lines on the terminal.
+const tabWidth = 8
+
+// Fit message and attributes in the width of the terminal by MessageOverflow and AttrsOverflow options.
+// The prefix and the suffix are widths of fields before message and after attributes including separators.
+// It returns message and attributes of the line, and continuation lines wrapped from them.
+func (handler *LogHandler) fitWidth(prefix int, message string, attributes []string, suffix int) (string, []string, []string) {
+	var continuations []string
+	rest := max(handler.width-tabWidth, 1)
+	separator := handler.options.FieldSeparator
+
+	// message
+	available := handler.width - prefix - suffix
+	if available > 0 && visibleWidth(message) > available {
+		switch handler.options.MessageOverflow {
+		case OverflowTruncate:
+			message = truncateWidth(message, available)
+		case OverflowWrap:
+			lines := wrapWidth(message, available, rest)
+			message = lines[0]
+			for _, line := range lines[1:] {
+				continuations = append(continuations, "\t"+line)
+			}
+		}
+	}
+
+	// attributes, which are wrapped after message if message is wrapped
+	if len(attributes) == 0 {
+		return message, attributes, continuations
+	}
+	available = handler.width - prefix - visibleWidth(message) - suffix - visibleWidth(separator)
+	switch handler.options.AttrsOverflow {
+	case OverflowTruncate:
+		if text := strings.Join(attributes, separator); visibleWidth(text) > available {
+			attributes = []string{truncateWidth(text, max(available, 1))}
+		}
+	case OverflowWrap:
+		used := -visibleWidth(separator)
+		for i, attribute := range attributes {
+			used += visibleWidth(separator + attribute)
+			if used > available || len(continuations) > 0 {
+				for _, line := range packWidth(attributes[i:], rest, separator) {
+					continuations = append(continuations, "\t"+line)
+				}
+				attributes = attributes[:i]
+				break
+			}
+		}
+	}
+	return message, attributes, continuations
+}
+
+// Truncate the string to the width on the terminal with an ellipsis "…", which keeps escape sequences.
+func truncateWidth(s string, width int) string {
+	if visibleWidth(s) <= width {
+		return s
+	}
+	var builder strings.Builder
+	escaped := false
+	for i, visible := 0, 0; i < len(s); {
+		if n := escapeLength(s[i:]); n > 0 {
+			builder.WriteString(s[i : i+n])
+			escaped = true
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if visible+runeWidth(r) > width-1 {
+			break
+		}
+		builder.WriteString(s[i : i+size])
+		i += size
+		visible += runeWidth(r)
+	}
+	builder.WriteString("…")
+	if escaped {
+		// reset color and hyperlink cut in the middle
+		builder.WriteString("\x1b[0m")
+	}
+	return builder.String()
+}
+
+// Wrap the text without escape sequences into lines of the widths for the first line and the rest, which are broken at spaces if possible.
+func wrapWidth(s string, first int, rest int) []string {
+	var lines []string
+	for width := first; visibleWidth(s) > width; width = rest {
+		cut := 0
+		for used := 0; cut < len(s); {
+			r, size := utf8.DecodeRuneInString(s[cut:])
+			if used+runeWidth(r) > width {
+				break
+			}
+			used += runeWidth(r)
+			cut += size
+		}
+		if cut == 0 {
+			// keep a wide character wider than the width on its own line
+			_, cut = utf8.DecodeRuneInString(s)
+		}
+		if space := strings.LastIndexByte(s[:min(cut+1, len(s))], ' '); space > 0 {
+			lines = append(lines, s[:space])
+			s = s[space+1:]
+		} else {
+			lines = append(lines, s[:cut])
+			s = s[cut:]
+		}
+	}
+	return append(lines, s)
+}
+
+// Pack the items joined by the separator into lines of the width, where an item wider than the width is on its own line.
+func packWidth(items []string, width int, separator string) []string {
+	var lines []string
+	var line string
+	for _, item := range items {
+		if line != "" && visibleWidth(line+separator+item) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += separator
+		}
+		line += item
+	}
+	return append(lines, line)
+}
@@ -0,0 +1,59 @@
+package nslog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttrsOverflowWrap(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true, TerminalWidth: 30, AttrsOverflow: OverflowWrap})
+	log.Info("log message", "key1", "val1", "key2", "val2", "key3", "value3", "key4", "val4")
+	log.Info("short", "key", "val")
+	assert.Equal(t, "INFO. log message key1=val1\n\tkey2=val2 key3=value3\n\tkey4=val4\nINFO. short key=val\n", buf.String())
+}
+
+func TestAttrsOverflowTruncate(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true, TerminalWidth: 30, AttrsOverflow: OverflowTruncate})
+	log.Info("log message", "key1", "val1", "key2", "val2")
+	assert.Equal(t, "INFO. log message key1=val1 k…\n", buf.String())
+}
+
+func TestMessageOverflowWrap(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true, TerminalWidth: 20, MessageOverflow: OverflowWrap, AttrsOverflow: OverflowWrap})
+	log.Info("the quick brown fox jumps over the lazy dog", "key", "val")
+	assert.Equal(t, "INFO. the quick\n\tbrown fox\n\tjumps over\n\tthe lazy dog\n\tkey=val\n", buf.String())
+}
+
+func TestMessageOverflowTruncate(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true, TerminalWidth: 20, MessageOverflow: OverflowTruncate})
+	log.Info("the quick brown fox jumps over the lazy dog", "key", "val")
+	assert.Equal(t, "INFO. the quick bro… key=val\n", buf.String())
+}
+
+func TestOverflowEnv(t *testing.T) {
+	t.Setenv("GO_NSLOG_TERMINAL_WIDTH", "20")
+	t.Setenv("GO_NSLOG_ATTRS_OVERFLOW", "TRUNCATE")
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true})
+	log.Info("message", "key", "value")
+	assert.Equal(t, "INFO. message key=v…\n", buf.String())
+}
+
+func TestOverflowNotTerminal(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log := NewLogger(buf, &LogHandlerOptions{OmitTime: true, AttrsOverflow: OverflowTruncate})
+	log.Info("log message", "key", "val")
+	assert.Equal(t, "INFO. log message key=val\n", buf.String())
+}
+
+func TestTruncateWidth(t *testing.T) {
+	assert.Equal(t, "hello", truncateWidth("hello", 5))
+	assert.Equal(t, "hel…", truncateWidth("hello", 4))
+	assert.Equal(t, "\x1b[36mhel…\x1b[0m", truncateWidth("\x1b[36mhello\x1b[0m", 4))
+}